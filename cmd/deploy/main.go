@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
 
 	"github.com/nais/deploy/pkg/deployclient"
 	"github.com/nais/deploy/pkg/pb"
@@ -43,9 +44,28 @@ func run() error {
 	// Welcome
 	log.Infof("NAIS deploy %s", version.Version())
 
+	switch flag.Arg(0) {
+	case "history":
+		return lookup(ctx, cfg, func(d *deployclient.Deployer) error {
+			return d.History(ctx, cfg, flag.Arg(1))
+		})
+	case "diff":
+		return lookup(ctx, cfg, func(d *deployclient.Deployer) error {
+			return d.Diff(ctx, cfg, flag.Arg(1), flag.Arg(2))
+		})
+	case "redeploy":
+		return follow(ctx, cfg, "NAIS redeploy", func(ctx context.Context, d *deployclient.Deployer) error {
+			return d.Redeploy(ctx, cfg, flag.Arg(1))
+		})
+	case "teardown-preview":
+		return follow(ctx, cfg, "NAIS preview teardown", func(ctx context.Context, d *deployclient.Deployer) error {
+			return d.TeardownPreview(ctx, cfg)
+		})
+	}
+
 	err := cfg.Validate()
 	if err != nil {
-		if !cfg.DryRun {
+		if !cfg.DryRun && !cfg.PlanLocal {
 			return deployclient.ErrorWrap(deployclient.ExitInvocationFailure, err)
 		}
 		log.Warnf("Configuration did not pass validation: %s", err)
@@ -98,6 +118,17 @@ func run() error {
 		return err
 	}
 
+	if cfg.PlanLocal {
+		if cfg.PrintPayload {
+			fmt.Println(protojson.Format(request))
+		}
+		get, err := deployclient.KubernetesGetter()
+		if err != nil {
+			return deployclient.ErrorWrap(deployclient.ExitInvocationFailure, err)
+		}
+		return deployclient.PlanLocal(ctx, cfg, request, get)
+	}
+
 	// Set up asynchronous gRPC connection
 	grpcConnection, err := deployclient.NewGrpcConnection(*cfg)
 	if err != nil {
@@ -110,8 +141,15 @@ func run() error {
 		}
 	}()
 
+	// Cancel the deployment on Ctrl-C while waiting for it to complete.
+	interrupt := make(chan os.Signal, 1)
+	if cfg.Wait {
+		signal.Notify(interrupt, os.Interrupt)
+	}
+
 	d := deployclient.Deployer{
-		Client: pb.NewDeployClient(grpcConnection),
+		Client:    pb.NewDeployClient(grpcConnection),
+		Interrupt: interrupt,
 	}
 
 	if cfg.PrintPayload {
@@ -122,5 +160,87 @@ func run() error {
 		return nil
 	}
 
+	if cfg.Plan {
+		return d.Plan(ctx, cfg, request)
+	}
+
+	if cfg.Delete {
+		return d.Undeploy(ctx, cfg, request)
+	}
+
 	return d.Deploy(ctx, cfg, request)
 }
+
+// Look up past deployments of the team.
+func lookup(ctx context.Context, cfg *deployclient.Config, fn func(d *deployclient.Deployer) error) error {
+	err := cfg.ValidateHistory()
+	if err != nil {
+		return deployclient.ErrorWrap(deployclient.ExitInvocationFailure, err)
+	}
+
+	grpcConnection, err := deployclient.NewGrpcConnection(*cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := grpcConnection.Close()
+		if err != nil {
+			log.Error(err)
+		}
+	}()
+
+	d := deployclient.Deployer{
+		Client: pb.NewDeployClient(grpcConnection),
+	}
+
+	return fn(&d)
+}
+
+// Start a deployment that is made from something other than local resources, such as a previous deployment,
+// and follow it like any other deployment.
+func follow(ctx context.Context, cfg *deployclient.Config, spanName string, fn func(ctx context.Context, d *deployclient.Deployer) error) error {
+	err := cfg.ValidateHistory()
+	if err != nil {
+		return deployclient.ErrorWrap(deployclient.ExitInvocationFailure, err)
+	}
+
+	tracerProvider, err := telemetry.New(ctx, "deploy", cfg.OpenTelemetryCollectorURL)
+	if err != nil {
+		return fmt.Errorf("Setup OpenTelemetry: %w", err)
+	}
+	defer func() {
+		err := tracerProvider.Shutdown(ctx)
+		if err != nil {
+			log.Errorf("Shutdown OpenTelemetry: %s", err)
+		}
+	}()
+
+	if len(cfg.Traceparent) > 0 {
+		ctx = telemetry.WithTraceParent(ctx, cfg.Traceparent)
+	}
+	ctx, span := telemetry.Tracer().Start(ctx, spanName, otrace.WithSpanKind(otrace.SpanKindClient))
+	defer span.End()
+
+	grpcConnection, err := deployclient.NewGrpcConnection(*cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := grpcConnection.Close()
+		if err != nil {
+			log.Error(err)
+		}
+	}()
+
+	interrupt := make(chan os.Signal, 1)
+	if cfg.Wait {
+		signal.Notify(interrupt, os.Interrupt)
+	}
+
+	d := deployclient.Deployer{
+		Client:    pb.NewDeployClient(grpcConnection),
+		Interrupt: interrupt,
+	}
+
+	return fn(ctx, &d)
+}
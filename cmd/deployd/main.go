@@ -25,6 +25,7 @@ import (
 	"github.com/nais/deploy/pkg/deployd/kubeclient"
 	"github.com/nais/deploy/pkg/deployd/metrics"
 	"github.com/nais/deploy/pkg/deployd/operation"
+	"github.com/nais/deploy/pkg/deployd/strategy"
 	presharedkey_interceptor "github.com/nais/deploy/pkg/grpc/interceptor/presharedkey"
 	"github.com/nais/deploy/pkg/logging"
 	"github.com/nais/deploy/pkg/pb"
@@ -85,6 +86,23 @@ func run() error {
 		return fmt.Errorf("authenticated gRPC calls enabled, but --hookd-key is not specified")
 	}
 
+	_, err = strategy.NewDeployStrategy(cfg.DeployStrategy, nil, nil)
+	if err != nil {
+		return fmt.Errorf("invalid --%s: %w", config.DeployStrategy, err)
+	}
+
+	conditionWatches, err := strategy.NewConditionWatches(cfg.ConditionWatch.Resources, cfg.ConditionWatch.SuccessCondition, cfg.ConditionWatch.FailureCondition)
+	if err != nil {
+		return fmt.Errorf("invalid condition watch configuration: %w", err)
+	}
+
+	if len(cfg.InstanceID) == 0 {
+		cfg.InstanceID, err = os.Hostname()
+		if err != nil {
+			return fmt.Errorf("determine instance ID: %w", err)
+		}
+	}
+
 	kube, err := kubeclient.DefaultClient()
 	if err != nil {
 		return fmt.Errorf("cannot configure Kubernetes client: %s", err)
@@ -143,6 +161,7 @@ func run() error {
 			deploymentStream, err := grpcClient.Deployments(programContext, &pb.GetDeploymentOpts{
 				Cluster:     cfg.Cluster,
 				StartupTime: pb.TimeAsTimestamp(startupTime),
+				InstanceID:  cfg.InstanceID,
 			})
 			if err != nil {
 				log.Errorf("Open hookd deployment stream: %s", err)
@@ -164,6 +183,9 @@ func run() error {
 		}
 	}()
 
+	// Deployments touching the same resources are run one at a time.
+	var scheduler *deployd.Scheduler
+
 	deploy := func(req *pb.DeploymentRequest) {
 		ctx, cancel := req.Context()
 		ctx = telemetry.WithTraceParent(ctx, req.TraceParent)
@@ -189,7 +211,44 @@ func run() error {
 			StatusChan: statusChan,
 		}
 
-		deployd.Run(op, client)
+		scheduler.Started(op)
+		if req.GetDelete() {
+			deployd.Delete(op, client)
+			return
+		}
+		deployd.Run(op, client, cfg, conditionWatches)
+	}
+
+	scheduler = deployd.NewScheduler(deploy)
+
+	// Plans don't change anything, so they are not scheduled with deployments.
+	plan := func(req *pb.DeploymentRequest) {
+		ctx, cancel := req.Context()
+		defer cancel()
+		ctx = telemetry.WithTraceParent(ctx, req.TraceParent)
+		ctx, span := telemetry.Tracer().Start(ctx, "Plan Kubernetes changes", otrace.WithSpanKind(otrace.SpanKindServer))
+		defer span.End()
+
+		logger := log.WithFields(req.LogFields())
+
+		var result *pb.DeploymentPlan
+		client, err := kube.Impersonate(req.GetTeam())
+		if err != nil {
+			result = &pb.DeploymentPlan{
+				ID:      req.GetID(),
+				Cluster: cfg.Cluster,
+				Errors:  []*pb.ResourceError{{Message: err.Error()}},
+			}
+		} else {
+			result = deployd.Plan(ctx, client, cfg, req)
+		}
+
+		logger.Infof("Planned %d resources; %d would be rejected", len(result.GetResources()), len(result.GetErrors()))
+
+		_, err = grpcClient.ReportPlan(programContext, result)
+		if err != nil {
+			logger.Errorf("Report plan to hookd: %s", err)
+		}
 	}
 
 	statusQueue := make([]*pb.DeploymentStatus, 0, 128)
@@ -237,10 +296,22 @@ func run() error {
 	for {
 		select {
 		case req := <-requestChan:
-			go deploy(req)
+			switch {
+			case req.GetPlan():
+				go plan(req)
+			case req.GetCancel():
+				statusQueue = append(statusQueue, scheduler.Cancel(req)...)
+				reportAllInQueue()
+			default:
+				statusQueue = append(statusQueue, scheduler.Submit(req)...)
+				reportAllInQueue()
+			}
 
 		case st := <-statusChan:
 			statusQueue = append(statusQueue, st)
+			if st.GetState().Finished() {
+				statusQueue = append(statusQueue, scheduler.Finished(st)...)
+			}
 			reportAllInQueue()
 
 		case <-time.NewTimer(statusQueueReportInterval).C:
@@ -21,10 +21,12 @@ import (
 	switch_interceptor "github.com/nais/deploy/pkg/grpc/interceptor/switch"
 	unauthenticated_interceptor "github.com/nais/deploy/pkg/grpc/interceptor/unauthenticated"
 	"github.com/nais/deploy/pkg/hookd/api"
+	"github.com/nais/deploy/pkg/hookd/approval"
 	"github.com/nais/deploy/pkg/hookd/config"
 	"github.com/nais/deploy/pkg/hookd/database"
 	"github.com/nais/deploy/pkg/hookd/logproxy"
 	"github.com/nais/deploy/pkg/hookd/middleware"
+	"github.com/nais/deploy/pkg/hookd/policy"
 	"github.com/nais/deploy/pkg/logging"
 	"github.com/nais/deploy/pkg/pb"
 	"github.com/nais/deploy/pkg/telemetry"
@@ -47,6 +49,10 @@ var maskedConfig = []string{
 
 const (
 	databaseConnectBackoffInterval = 3 * time.Second
+	manifestExpiryInterval         = time.Hour
+	previewExpiryInterval          = time.Minute
+	approvalExpiryInterval         = time.Minute
+	queueExpiryInterval            = time.Minute
 )
 
 func run() error {
@@ -127,8 +133,12 @@ func run() error {
 		return fmt.Errorf("migrating database: %s", err)
 	}
 
+	if cfg.ManifestRetention > 0 {
+		go expireManifests(programContext, db, cfg.ManifestRetention)
+	}
+
 	// Set up gRPC server
-	grpcServer, dispatchServer, err := startGrpcServer(*cfg, db, db)
+	grpcServer, dispatchServer, approvals, err := startGrpcServer(programContext, *cfg, db, db, db, db, db)
 	if err != nil {
 		return err
 	}
@@ -142,8 +152,11 @@ func run() error {
 	}
 	router := api.New(api.Config{
 		ApiKeyStore:           db,
+		ApprovalStore:         db,
+		Approvals:             approvals,
 		BaseURL:               cfg.BaseURL,
 		DispatchServer:        dispatchServer,
+		FreezeStore:           db,
 		MetricsPath:           cfg.MetricsPath,
 		PSKValidator:          middleware.PskValidatorMiddleware(cfg.FrontendKeys),
 		ProvisionKey:          provisionKey,
@@ -170,6 +183,27 @@ func run() error {
 	return nil
 }
 
+// Periodically remove deployment manifests that are older than the retention period.
+func expireManifests(ctx context.Context, db database.DeploymentStore, retention time.Duration) {
+	ticker := time.NewTicker(manifestExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := db.DeleteDeploymentManifests(ctx, time.Now().Add(-retention))
+		if err != nil {
+			log.Errorf("Remove expired deployment manifests: %s", err)
+		} else if deleted > 0 {
+			log.Infof("Removed %d deployment manifests older than %s", deleted, retention)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 func newApiClient(target string, insecureConnection bool) (*apiclient.APIClient, error) {
 	opts := []grpc.DialOption{}
 	if insecureConnection {
@@ -179,19 +213,53 @@ func newApiClient(target string, insecureConnection bool) (*apiclient.APIClient,
 	return apiclient.New(target, opts...)
 }
 
-func startGrpcServer(cfg config.Config, db database.DeploymentStore, apikeys database.ApiKeyStore) (*grpc.Server, dispatchserver.DispatchServer, error) {
+func startGrpcServer(ctx context.Context, cfg config.Config, db database.DeploymentStore, notifier database.Notifier, apikeys database.ApiKeyStore, freezes database.FreezeStore, approvalStore database.ApprovalStore) (*grpc.Server, dispatchserver.DispatchServer, *approval.Gate, error) {
 	clusterRedirects, err := parseKeyVal(cfg.ClusterMigrationRedirect)
 	if err != nil {
-		return nil, nil, fmt.Errorf("unable to parse cluster migration redirects: %v", err)
+		return nil, nil, nil, fmt.Errorf("unable to parse cluster migration redirects: %v", err)
+	}
+
+	policies, err := loadPolicies(cfg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	approvers, err := parseApprovers(cfg.ApprovalClusters)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("unable to parse approval clusters: %v", err)
 	}
 
 	apiClient, err := newApiClient(cfg.NaisAPIAddress, cfg.NaisAPIInsecureConnection)
 	if err != nil {
-		return nil, nil, fmt.Errorf("unable to set up nais-api client: %w", err)
+		return nil, nil, nil, fmt.Errorf("unable to set up nais-api client: %w", err)
+	}
+
+	dispatchMode, err := dispatchserver.ParseDispatchMode(cfg.GRPC.DeploydDispatchMode)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid --%s: %w", config.GrpcDeploydDispatchMode, err)
+	}
+
+	dispatchServer, err := dispatchserver.New(ctx, db, notifier, apiClient.Deployments(), dispatchMode)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	go dispatchServer.ExpireQueue(ctx, queueExpiryInterval)
+
+	var approvals *approval.Gate
+	if len(approvers) > 0 {
+		approvals = approval.NewGate(approvalStore, dispatchServer, approvers).WithFreezes(freezes)
+		go approvals.ExpireApprovals(ctx, approvalExpiryInterval)
 	}
 
-	dispatchServer := dispatchserver.New(db, apiClient.Deployments())
-	deployServer := deployserver.New(dispatchServer, db, clusterRedirects, apiClient.Deployments())
+	deployServer := deployserver.NewWithOptions(dispatchServer, db, clusterRedirects, apiClient.Deployments(), deployserver.Options{
+		FreezeStore:     freezes,
+		Policies:        policies,
+		Approvals:       approvals,
+		ManifestMaxSize: cfg.ManifestMaxSize,
+		BaseURL:         cfg.BaseURL,
+	})
+	go deployServer.ExpirePreviews(ctx, previewExpiryInterval)
+
 	unaryInterceptors := make([]grpc.UnaryServerInterceptor, 0)
 	streamInterceptors := make([]grpc.StreamServerInterceptor, 0)
 
@@ -215,7 +283,7 @@ func startGrpcServer(cfg config.Config, db database.DeploymentStore, apikeys dat
 		if cfg.GRPC.CliAuthentication {
 			ghValidator, err := auth_interceptor.NewGithubValidator()
 			if err != nil {
-				return nil, nil, fmt.Errorf("unable to set up github validator: %w", err)
+				return nil, nil, nil, fmt.Errorf("unable to set up github validator: %w", err)
 			}
 
 			authInterceptor := auth_interceptor.NewServerInterceptor(apikeys, ghValidator, apiClient.Teams())
@@ -264,7 +332,7 @@ func startGrpcServer(cfg config.Config, db database.DeploymentStore, apikeys dat
 
 	grpcListener, err := net.Listen("tcp", cfg.GRPC.Address)
 	if err != nil {
-		return nil, nil, fmt.Errorf("unable to set up gRPC server: %w", err)
+		return nil, nil, nil, fmt.Errorf("unable to set up gRPC server: %w", err)
 	}
 	go func() {
 		err := grpcServer.Serve(grpcListener)
@@ -274,7 +342,45 @@ func startGrpcServer(cfg config.Config, db database.DeploymentStore, apikeys dat
 		}
 	}()
 
-	return grpcServer, dispatchServer, nil
+	return grpcServer, dispatchServer, approvals, nil
+}
+
+// Admission policies are only checked if a policy directory is configured.
+func loadPolicies(cfg config.Config) (*policy.Engine, error) {
+	if len(cfg.PolicyDirectory) == 0 {
+		return nil, nil
+	}
+
+	policies, err := policy.Load(cfg.PolicyDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load admission policies: %w", err)
+	}
+
+	if cfg.PolicyWarnOnly {
+		log.Infof("Loaded %d admission policies from %s; violations are reported as warnings only", len(policies), cfg.PolicyDirectory)
+	} else {
+		log.Infof("Loaded %d admission policies from %s", len(policies), cfg.PolicyDirectory)
+	}
+
+	return policy.NewEngine(policies, cfg.PolicyWarnOnly), nil
+}
+
+// Parse clusters where deployments must be approved, given as cluster=team or just cluster.
+func parseApprovers(clusters []string) (map[string]string, error) {
+	approvers := make(map[string]string, len(clusters))
+	for _, entry := range clusters {
+		cluster, team, _ := strings.Cut(entry, "=")
+		if len(cluster) == 0 {
+			return nil, fmt.Errorf("missing cluster in '%s'", entry)
+		}
+		approvers[cluster] = team
+		if len(team) == 0 {
+			log.Infof("Deployments to %s must be approved by another member of the deploying team", cluster)
+		} else {
+			log.Infof("Deployments to %s must be approved by team %s", cluster, team)
+		}
+	}
+	return approvers, nil
 }
 
 func parseKeyVal(projects []string) (map[string]string, error) {
@@ -2,34 +2,52 @@ package deployclient
 
 import (
 	"encoding/hex"
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	flag "github.com/spf13/pflag"
+
+	"github.com/nais/deploy/pkg/pb"
 )
 
 type Config struct {
 	APIKey                    string
 	Actions                   bool
 	Cluster                   string
+	Concurrency               string
+	Cursor                    string
+	Delete                    bool
 	DeployServerURL           string
+	DeploySet                 string
 	DryRun                    bool
 	Environment               string
+	FreezeOverride            string
 	GitHubTokenURL            string
 	GitHubBearerToken         string
 	GrpcAuthentication        bool
 	GrpcUseTLS                bool
+	Limit                     int
 	OpenTelemetryCollectorURL string
+	Output                    string
 	Owner                     string
+	Plan                      bool
+	PlanLocal                 bool
 	PollInterval              time.Duration
+	Preview                   int
+	PreviewTTL                time.Duration
 	PrintPayload              bool
+	Prune                     bool
 	Quiet                     bool
 	Repository                string
 	Resource                  []string
 	Retry                     bool
 	RetryInterval             time.Duration
+	Rollback                  bool
+	Since                     string
+	State                     []string
 	Team                      string
 	Traceparent               string
 	Timeout                   time.Duration
@@ -45,20 +63,35 @@ func InitConfig(cfg *Config) {
 	flag.StringVar(&cfg.APIKey, "apikey", os.Getenv("APIKEY"), "NAIS Deploy API key. (env APIKEY)")
 	flag.BoolVar(&cfg.Actions, "actions", getEnvBool("ACTIONS", false), "Use GitHub Actions compatible error and warning messages. (env ACTIONS)")
 	flag.StringVar(&cfg.Cluster, "cluster", os.Getenv("CLUSTER"), "NAIS cluster to deploy into. (env CLUSTER)")
+	flag.StringVar(&cfg.Concurrency, "concurrency", getEnv("CONCURRENCY", pb.ConcurrencyPolicy_queue.String()), "What to do if the same resources are already being deployed: queue, supersede or reject. (env CONCURRENCY)")
+	flag.StringVar(&cfg.Cursor, "cursor", os.Getenv("CURSOR"), "History: continue listing where a previous page ended. (env CURSOR)")
+	flag.BoolVar(&cfg.Delete, "delete", getEnvBool("DELETE", false), "Delete the resources from the cluster instead of deploying them, and wait until they are gone. (env DELETE)")
 	flag.StringVar(&cfg.DeployServerURL, "deploy-server", getEnv("DEPLOY_SERVER", DefaultDeployServer), "URL to API server. (env DEPLOY_SERVER)")
+	flag.StringVar(&cfg.DeploySet, "deploy-set", os.Getenv("DEPLOY_SET"), "Label every resource as part of this deploy set, so that resources removed from it can be pruned. (env DEPLOY_SET)")
 	flag.BoolVar(&cfg.DryRun, "dry-run", getEnvBool("DRY_RUN", false), "Run templating, but don't actually make any requests. (env DRY_RUN)")
 	flag.StringVar(&cfg.Environment, "environment", os.Getenv("ENVIRONMENT"), "Environment for GitHub deployment. Autodetected from nais.yaml if not specified. (env ENVIRONMENT)")
+	flag.StringVar(&cfg.FreezeOverride, "freeze-override", os.Getenv("FREEZE_OVERRIDE"), "Deploy even if a deployment freeze applies, giving this reason. The reason is logged. (env FREEZE_OVERRIDE)")
 	flag.StringVar(&cfg.GitHubTokenURL, "github-token-url", os.Getenv("GITHUB_TOKEN_URL"), "URL for requesting GitHub id_token. (env GITHUB_TOKEN_URL)")
 	flag.StringVar(&cfg.GitHubBearerToken, "github-bearer-token", os.Getenv("GITHUB_BEARER_TOKEN"), "Bearer token for use when requesting GitHub id_token. (env GITHUB_BEARER_TOKEN)")
 	flag.BoolVar(&cfg.GrpcAuthentication, "grpc-authentication", getEnvBool("GRPC_AUTHENTICATION", true), "Use team API key to authenticate requests. (env GRPC_AUTHENTICATION)")
 	flag.BoolVar(&cfg.GrpcUseTLS, "grpc-use-tls", getEnvBool("GRPC_USE_TLS", true), "Use encrypted connection for gRPC calls. (env GRPC_USE_TLS)")
+	flag.IntVar(&cfg.Limit, "limit", getEnvInt("LIMIT", 0), "History: maximum number of deployments to list. (env LIMIT)")
 	flag.StringVar(&cfg.OpenTelemetryCollectorURL, "otel-collector-endpoint", getEnv("OTEL_COLLECTOR_ENDPOINT", DefaultOtelCollectorEndpoint), "OpenTelemetry collector endpoint. (env OTEL_COLLECTOR_ENDPOINT)")
+	flag.StringVar(&cfg.Output, "output", getEnv("OUTPUT", OutputTable), "History: output format, table or json. (env OUTPUT)")
 	flag.StringVar(&cfg.Owner, "owner", getEnv("OWNER", DefaultOwner), "Owner of GitHub repository. (env OWNER)")
+	flag.BoolVar(&cfg.Plan, "plan", getEnvBool("PLAN", false), "Show what the deployment would change in the cluster, without deploying. (env PLAN)")
+	flag.BoolVar(&cfg.PlanLocal, "plan-local", getEnvBool("PLAN_LOCAL", false), "Like --plan, but compare with the cluster given by $KUBECONFIG instead of asking NAIS deploy. (env PLAN_LOCAL)")
+	flag.IntVar(&cfg.Preview, "preview", getEnvInt("PREVIEW", 0), "Deploy a preview environment for this pull request number, which is torn down when it expires. (env PREVIEW)")
+	flag.DurationVar(&cfg.PreviewTTL, "preview-ttl", getEnvDuration("PREVIEW_TTL", DefaultPreviewTTL), "How long a preview environment lives after its latest deployment. (env PREVIEW_TTL)")
 	flag.BoolVar(&cfg.PrintPayload, "print-payload", getEnvBool("PRINT_PAYLOAD", false), "Print templated resources to standard output. (env PRINT_PAYLOAD)")
+	flag.BoolVar(&cfg.Prune, "prune", getEnvBool("PRUNE", false), "Delete resources in the deploy set that are no longer part of the deployment, once it has succeeded. (env PRUNE)")
 	flag.BoolVar(&cfg.Quiet, "quiet", getEnvBool("QUIET", false), "Suppress printing of informational messages except errors. (env QUIET)")
 	flag.StringVar(&cfg.Repository, "repository", os.Getenv("REPOSITORY"), "Name of GitHub repository. (env REPOSITORY)")
 	flag.StringSliceVar(&cfg.Resource, "resource", getEnvStringSlice("RESOURCE"), "File with Kubernetes resource. Can be specified multiple times. (env RESOURCE)")
 	flag.BoolVar(&cfg.Retry, "retry", getEnvBool("RETRY", true), "Retry deploy when encountering transient errors. (env RETRY)")
+	flag.BoolVar(&cfg.Rollback, "rollback", getEnvBool("ROLLBACK", false), "Roll back all resources to their previous version if the deployment fails. (env ROLLBACK)")
+	flag.StringVar(&cfg.Since, "since", os.Getenv("SINCE"), "History: only list deployments newer than this duration or RFC 3339 timestamp. (env SINCE)")
+	flag.StringSliceVar(&cfg.State, "state", getEnvStringSlice("STATE"), "History: only list deployments in this state. Can be specified multiple times. (env STATE)")
 	flag.StringVar(&cfg.Team, "team", os.Getenv("TEAM"), "Team making the deployment. Auto-detected from nais.yaml if possible. (env TEAM)")
 	flag.StringVar(&cfg.Traceparent, "traceparent", os.Getenv("TRACEPARENT"), "The W3C Trace Context traceparent value for the workflow run. (env TRACEPARENT)")
 	flag.DurationVar(&cfg.Timeout, "timeout", getEnvDuration("TIMEOUT", DefaultDeployTimeout), "Time to wait for successful deployment. (env TIMEOUT)")
@@ -104,6 +137,16 @@ func getEnvDuration(key string, fallback time.Duration) time.Duration {
 	return fallback
 }
 
+func getEnvInt(key string, fallback int) int {
+	if value, ok := os.LookupEnv(key); ok {
+		i, err := strconv.Atoi(value)
+		if err == nil {
+			return i
+		}
+	}
+	return fallback
+}
+
 func getEnvStringSlice(key string) []string {
 	if value, ok := os.LookupEnv(key); ok {
 		parts := strings.Split(value, ",")
@@ -147,5 +190,56 @@ func (cfg *Config) Validate() error {
 		return ErrMalformedAPIKey
 	}
 
+	if _, ok := pb.ConcurrencyPolicy_value[cfg.Concurrency]; len(cfg.Concurrency) > 0 && !ok {
+		return ErrInvalidConcurrency
+	}
+
+	if cfg.Prune && len(cfg.DeploySet) == 0 {
+		return ErrDeploySetRequired
+	}
+
+	if cfg.Preview < 0 {
+		return ErrInvalidPreview
+	}
+
+	if cfg.Delete && (cfg.Plan || cfg.PlanLocal || cfg.Prune || cfg.Preview > 0) {
+		return ErrInvalidDelete
+	}
+
+	if cfg.Preview > 0 && cfg.PreviewTTL <= 0 {
+		return ErrInvalidPreviewTTL
+	}
+
+	return nil
+}
+
+// ValidateHistory checks the configuration needed to look up or redeploy past deployments.
+func (cfg *Config) ValidateHistory() error {
+	if len(cfg.Team) == 0 {
+		return ErrTeamRequired
+	}
+
+	githubAuth := len(cfg.GitHubTokenURL) > 0 && len(cfg.GitHubBearerToken) > 0
+	if len(cfg.APIKey) == 0 && !githubAuth {
+		return ErrAuthRequired
+	}
+
+	_, err := hex.DecodeString(cfg.APIKey)
+	if err != nil {
+		return ErrMalformedAPIKey
+	}
+
+	switch cfg.Output {
+	case "", OutputTable, OutputJSON:
+	default:
+		return ErrInvalidOutput
+	}
+
+	for _, state := range cfg.State {
+		if _, ok := pb.DeploymentState_value[state]; !ok {
+			return fmt.Errorf("invalid deployment state %q", state)
+		}
+	}
+
 	return nil
 }
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
 	"time"
 
@@ -25,18 +26,31 @@ const (
 	DefaultOtelCollectorEndpoint = "https://collector-internet.external.prod-gcp.nav.cloud.nais.io"
 	DefaultTracingDashboardURL   = "https://grafana.nav.cloud.nais.io/d/cdxgyzr3rikn4a/deploy-tracing-drilldown?var-trace_id="
 	DefaultDeployTimeout         = time.Minute * 10
+	DefaultPreviewTTL            = time.Hour * 72
 )
 
+// How long to wait for NAIS deploy to accept a cancel request.
+const cancelTimeout = time.Second * 10
+
 var (
 	ErrResourceRequired       = errors.New("at least one Kubernetes resource is required to make sense of the deployment")
 	ErrImageRequired          = errors.New("workload-image is required when using workload-name")
 	ErrAuthRequired           = errors.New("Github token or API key required")
 	ErrClusterRequired        = errors.New("cluster required; see reference section in the documentation for available environments")
 	ErrMalformedAPIKey        = errors.New("API key must be a hex encoded string")
+	ErrInvalidConcurrency     = errors.New("concurrency must be one of queue, supersede or reject")
+	ErrInvalidPreview         = errors.New("preview must be a pull request number")
+	ErrDeploySetRequired      = errors.New("deploy-set is required to prune resources")
+	ErrInvalidPreviewTTL      = errors.New("preview-ttl must be positive")
+	ErrInvalidDelete          = errors.New("delete can't be combined with plan, prune or preview; use teardown-preview to remove preview environments")
+	ErrTeamRequired           = errors.New("team required")
+	ErrInvalidOutput          = errors.New("output must be one of table or json")
 )
 
 type Deployer struct {
 	Client pb.DeployClient
+	// When waiting for completion, the deployment is cancelled the first time a signal is received on this channel.
+	Interrupt <-chan os.Signal
 }
 
 func Prepare(ctx context.Context, cfg *Config) (*pb.DeploymentRequest, error) {
@@ -61,6 +75,12 @@ func Prepare(ctx context.Context, cfg *Config) (*pb.DeploymentRequest, error) {
 		}
 	}
 
+	if cfg.Preview > 0 {
+		if _, ok := templateVariables["preview"]; !ok {
+			templateVariables["preview"] = PreviewName(cfg.Preview)
+		}
+	}
+
 	resources := make([]json.RawMessage, 0)
 
 	for i, path := range cfg.Resource {
@@ -165,6 +185,18 @@ func Prepare(ctx context.Context, cfg *Config) (*pb.DeploymentRequest, error) {
 		}
 	}
 
+	var previewExpires time.Time
+	if cfg.Preview > 0 {
+		previewExpires = time.Now().Add(cfg.PreviewTTL)
+		log.Infof("Deploying preview environment %q, which expires at %s", PreviewName(cfg.Preview), previewExpires.Local())
+		for i := range resources {
+			resources[i], err = MakePreview(resources[i], PreviewName(cfg.Preview), previewExpires)
+			if err != nil {
+				return nil, ErrorWrap(ExitInternalError, fmt.Errorf("make resource %d part of preview environment: %w", i, err))
+			}
+		}
+	}
+
 	allResources, err := wrapResources(resources)
 	if err != nil {
 		return nil, ErrorWrap(ExitInvocationFailure, err)
@@ -177,10 +209,68 @@ func Prepare(ctx context.Context, cfg *Config) (*pb.DeploymentRequest, error) {
 
 	deadline, _ := ctx.Deadline()
 
-	return MakeDeploymentRequest(*cfg, deadline, kube), nil
+	request := MakeDeploymentRequest(*cfg, deadline, kube)
+	if cfg.Preview > 0 {
+		request.Preview = PreviewName(cfg.Preview)
+		request.PreviewExpires = pb.TimeAsTimestamp(previewExpires)
+		if len(request.DeploySet) > 0 {
+			// Keep the deploy set of the preview apart, so that pruning it never touches the main deployment.
+			request.DeploySet += "-" + request.Preview
+		}
+	}
+
+	return request, nil
 }
 
+// Sends a deployment request to NAIS deploy, and returns its initial status.
+type sendFunc func(ctx context.Context, deployRequest *pb.DeploymentRequest) (*pb.DeploymentStatus, error)
+
 func (d *Deployer) Deploy(ctx context.Context, cfg *Config, deployRequest *pb.DeploymentRequest) error {
+	return d.deploy(ctx, cfg, deployRequest, func(ctx context.Context, deployRequest *pb.DeploymentRequest) (*pb.DeploymentStatus, error) {
+		return d.Client.Deploy(ctx, deployRequest)
+	})
+}
+
+// Undeploy asks NAIS deploy to delete the resources in a deployment request, and waits for the deletion like any other deployment.
+// Only the kind, name and namespace of each resource is used.
+func (d *Deployer) Undeploy(ctx context.Context, cfg *Config, deployRequest *pb.DeploymentRequest) error {
+	log.Infof("Deleting %d resources from cluster '%s'", len(deployRequest.GetKubernetes().GetResources()), deployRequest.GetCluster())
+
+	return d.deploy(ctx, cfg, deployRequest, func(ctx context.Context, deployRequest *pb.DeploymentRequest) (*pb.DeploymentStatus, error) {
+		return d.Client.Undeploy(ctx, deployRequest)
+	})
+}
+
+// Redeploy makes a previous deployment again, and waits for it like any other deployment.
+func (d *Deployer) Redeploy(ctx context.Context, cfg *Config, deploymentID string) error {
+	if len(deploymentID) == 0 {
+		return Errorf(ExitInvocationFailure, "usage: deploy redeploy DEPLOYMENT_ID")
+	}
+
+	deadline, _ := ctx.Deadline()
+	deployRequest := &pb.DeploymentRequest{
+		Team:     cfg.Team,
+		Deadline: pb.TimeAsTimestamp(deadline),
+	}
+
+	log.Infof("Redeploying deployment %s", deploymentID)
+
+	return d.deploy(ctx, cfg, deployRequest, func(ctx context.Context, deployRequest *pb.DeploymentRequest) (*pb.DeploymentStatus, error) {
+		deployStatus, err := d.Client.Redeploy(ctx, &pb.RedeployRequest{
+			ID:             deploymentID,
+			Team:           deployRequest.GetTeam(),
+			Deadline:       deployRequest.GetDeadline(),
+			TraceParent:    deployRequest.GetTraceParent(),
+			FreezeOverride: cfg.FreezeOverride,
+		})
+		if err == nil {
+			deployRequest.Cluster = deployStatus.GetRequest().GetCluster()
+		}
+		return deployStatus, err
+	})
+}
+
+func (d *Deployer) deploy(ctx context.Context, cfg *Config, deployRequest *pb.DeploymentRequest, send sendFunc) error {
 	var deployStatus *pb.DeploymentStatus
 	var err error
 
@@ -197,7 +287,7 @@ func (d *Deployer) Deploy(ctx context.Context, cfg *Config, deployRequest *pb.De
 		defer requestSpan.End()
 
 		err = retryUnavailable(cfg.RetryInterval, cfg.Retry, func() error {
-			deployStatus, err = d.Client.Deploy(requestContext, deployRequest)
+			deployStatus, err = send(requestContext, deployRequest)
 			return err
 		})
 
@@ -217,7 +307,14 @@ func (d *Deployer) Deploy(ctx context.Context, cfg *Config, deployRequest *pb.De
 			return ErrorWrap(ExitNoDeployment, err)
 		}
 
-		log.Infof("Deployment request accepted by NAIS deploy and dispatched to cluster '%s'.", deployStatus.GetRequest().GetCluster())
+		if deployStatus.GetState() == pb.DeploymentState_pending {
+			log.Infof("Deployment request accepted by NAIS deploy, but must be approved before it is dispatched to cluster '%s'.", deployStatus.GetRequest().GetCluster())
+		} else {
+			log.Infof("Deployment request accepted by NAIS deploy and dispatched to cluster '%s'.", deployStatus.GetRequest().GetCluster())
+		}
+		for _, warning := range deployStatus.GetWarnings() {
+			log.Warnf("Policy violation: %s", warning)
+		}
 
 		deployRequest.ID = deployStatus.GetRequest().GetID()
 		telemetry.AddDeploymentRequestSpanAttributes(span, deployStatus.GetRequest())
@@ -288,6 +385,18 @@ func (d *Deployer) Deploy(ctx context.Context, cfg *Config, deployRequest *pb.De
 
 	log.Infof("Waiting for deployment to complete...")
 
+	if d.Interrupt != nil {
+		go func() {
+			select {
+			case <-d.Interrupt:
+				// A second interrupt terminates the program immediately.
+				signal.Reset(os.Interrupt)
+				d.cancel(deployRequest)
+			case <-ctx.Done():
+			}
+		}()
+	}
+
 	for ctx.Err() == nil {
 		err = retryUnavailable(cfg.RetryInterval, cfg.Retry, func() error {
 			stream, err = d.Client.Status(ctx, deployRequest)
@@ -316,7 +425,11 @@ func (d *Deployer) Deploy(ctx context.Context, cfg *Config, deployRequest *pb.De
 				}
 			}
 			logDeployStatus(deployStatus)
-			if deployStatus.GetState() == pb.DeploymentState_inactive {
+			if deployStatus.GetCancelled() {
+				summary("❌ cancelled")
+				finalStatus(deployStatus)
+				return ErrorStatus(deployStatus)
+			} else if deployStatus.GetState() == pb.DeploymentState_inactive {
 				log.Warn("NAIS deploy has been restarted. Re-sending deployment request...")
 				err = sendDeploymentRequest()
 				if err != nil {
@@ -334,6 +447,26 @@ func (d *Deployer) Deploy(ctx context.Context, cfg *Config, deployRequest *pb.De
 	return Errorf(ExitTimeout, "deployment timed out: %w", ctx.Err())
 }
 
+// Ask NAIS deploy to stop the deployment. The final status is reported on the status stream.
+func (d *Deployer) cancel(deployRequest *pb.DeploymentRequest) {
+	log.Warnf("Interrupted; cancelling deployment...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cancelTimeout)
+	defer cancel()
+
+	_, err := d.Client.Cancel(ctx, &pb.DeploymentRequest{
+		ID:      deployRequest.GetID(),
+		Team:    deployRequest.GetTeam(),
+		Cluster: deployRequest.GetCluster(),
+	})
+	if err != nil {
+		log.Errorf("Cancel deployment: %s", formatGrpcError(err))
+		return
+	}
+
+	log.Infof("Cancellation requested; waiting for NAIS deploy to stop the deployment...")
+}
+
 func grpcErrorRetriable(err error) bool {
 	switch grpcErrorCode(err) {
 	case codes.Unavailable, codes.Internal:
@@ -3,6 +3,7 @@ package deployclient_test
 import (
 	"context"
 	"encoding/json"
+	"os"
 	"testing"
 	"time"
 
@@ -76,6 +77,75 @@ func TestSuccessfulDeploy(t *testing.T) {
 	assert.Equal(t, deployclient.ExitSuccess, deployclient.ErrorExitCode(err))
 }
 
+func TestRedeploy(t *testing.T) {
+	cfg := validConfig()
+	cfg.Team = "aura"
+	cfg.Wait = true
+	cfg.FreezeOverride = "rollback of broken release"
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	_, _ = telemetry.New(ctx, "test", "")
+
+	redeployed := &pb.DeploymentRequest{ID: "2", Team: "aura", Cluster: "dev-fss"}
+
+	client := &pb.MockDeployClient{}
+	client.On("Redeploy", mock.Anything, mock.MatchedBy(func(req *pb.RedeployRequest) bool {
+		return req.GetID() == "1" && req.GetTeam() == "aura" && req.GetDeadline() != nil && req.GetFreezeOverride() == "rollback of broken release"
+	})).Return(&pb.DeploymentStatus{
+		Request: redeployed,
+		Time:    pb.TimeAsTimestamp(time.Now()),
+		State:   pb.DeploymentState_queued,
+	}, nil).Once()
+
+	statusClient := &pb.MockDeploy_StatusClient{}
+	statusClient.On("Recv").Return(&pb.DeploymentStatus{
+		Request: redeployed,
+		Time:    pb.TimeAsTimestamp(time.Now()),
+		State:   pb.DeploymentState_success,
+	}, nil).Once()
+	client.On("Status", mock.Anything, mock.MatchedBy(func(req *pb.DeploymentRequest) bool {
+		return req.GetID() == "2" && req.GetCluster() == "dev-fss"
+	})).Return(statusClient, nil).Once()
+
+	d := deployclient.Deployer{Client: client}
+	err := d.Redeploy(ctx, cfg, "1")
+
+	assert.NoError(t, err)
+	client.AssertExpectations(t)
+}
+
+func TestUndeploy(t *testing.T) {
+	cfg := validConfig()
+	cfg.Wait = true
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	_, _ = telemetry.New(ctx, "test", "")
+
+	request := &pb.DeploymentRequest{Team: "aura", Cluster: "dev-fss", Kubernetes: &pb.Kubernetes{}}
+	deleting := &pb.DeploymentRequest{ID: "1", Team: "aura", Cluster: "dev-fss", Delete: true}
+
+	client := &pb.MockDeployClient{}
+	client.On("Undeploy", mock.Anything, request).Return(&pb.DeploymentStatus{
+		Request: deleting,
+		Time:    pb.TimeAsTimestamp(time.Now()),
+		State:   pb.DeploymentState_queued,
+	}, nil).Once()
+
+	statusClient := &pb.MockDeploy_StatusClient{}
+	statusClient.On("Recv").Return(&pb.DeploymentStatus{
+		Request: deleting,
+		Time:    pb.TimeAsTimestamp(time.Now()),
+		State:   pb.DeploymentState_success,
+	}, nil).Once()
+	client.On("Status", mock.Anything, mock.Anything).Return(statusClient, nil).Once()
+
+	d := deployclient.Deployer{Client: client}
+	err := d.Undeploy(ctx, cfg, request)
+
+	assert.NoError(t, err)
+	client.AssertExpectations(t)
+}
+
 func TestDeployError(t *testing.T) {
 	cfg := validConfig()
 	cfg.Wait = true
@@ -148,6 +218,88 @@ func TestDeployPolling(t *testing.T) {
 	assert.Equal(t, deployclient.ExitSuccess, deployclient.ErrorExitCode(err))
 }
 
+func TestDeployRejectedByApprover(t *testing.T) {
+	cfg := validConfig()
+	cfg.Wait = true
+	request := makeMockDeployRequest(*cfg)
+	request.ID = "1"
+	ctx := context.Background()
+	_, _ = telemetry.New(ctx, "test", "")
+
+	pending := &pb.DeploymentStatus{
+		Request: request,
+		Time:    pb.TimeAsTimestamp(time.Now()),
+		State:   pb.DeploymentState_pending,
+		Message: "Deployment is waiting for approval by a member of team 'aura'.",
+	}
+
+	client := &pb.MockDeployClient{}
+	client.On("Deploy", mock.Anything, request).Return(pending, nil).Once()
+
+	// Pending deployments are waited for like any other unfinished deployment.
+	statusClient := &pb.MockDeploy_StatusClient{}
+	statusClient.On("Recv").Return(pending, nil).Once()
+	statusClient.On("Recv").Return(&pb.DeploymentStatus{
+		Request: request,
+		Time:    pb.TimeAsTimestamp(time.Now()),
+		State:   pb.DeploymentState_failure,
+		Message: "deployment was rejected by bob",
+	}, nil).Once()
+
+	client.On("Status", mock.Anything, request).Return(statusClient, nil).Once()
+
+	d := deployclient.Deployer{Client: client}
+	err := d.Deploy(ctx, cfg, request)
+
+	assert.Error(t, err)
+	assert.Equal(t, deployclient.ExitDeploymentFailure, deployclient.ErrorExitCode(err))
+	client.AssertExpectations(t)
+}
+
+func TestDeployCancelledOnInterrupt(t *testing.T) {
+	cfg := validConfig()
+	cfg.Wait = true
+	request := makeMockDeployRequest(*cfg)
+	request.ID = "1"
+	ctx := context.Background()
+	_, _ = telemetry.New(ctx, "test", "")
+
+	cancelRequest := &pb.DeploymentRequest{
+		ID:      request.GetID(),
+		Team:    request.GetTeam(),
+		Cluster: request.GetCluster(),
+	}
+	cancelled := make(chan struct{})
+
+	client := &pb.MockDeployClient{}
+	client.On("Deploy", mock.Anything, request).Return(&pb.DeploymentStatus{
+		Request: request,
+		Time:    pb.TimeAsTimestamp(time.Now()),
+		State:   pb.DeploymentState_queued,
+		Message: "queued",
+	}, nil).Once()
+	client.On("Cancel", mock.Anything, cancelRequest).Return(pb.NewInProgressStatus(cancelRequest, "Cancellation requested"), nil).Run(func(args mock.Arguments) {
+		close(cancelled)
+	}).Once()
+
+	statusClient := &pb.MockDeploy_StatusClient{}
+	statusClient.On("Recv").Return(pb.NewCancelledStatus(request), nil).Run(func(args mock.Arguments) {
+		<-cancelled
+	}).Once()
+
+	client.On("Status", mock.Anything, request).Return(statusClient, nil).Once()
+
+	interrupt := make(chan os.Signal, 1)
+	interrupt <- os.Interrupt
+
+	d := deployclient.Deployer{Client: client, Interrupt: interrupt}
+	err := d.Deploy(ctx, cfg, request)
+
+	assert.Error(t, err)
+	assert.Equal(t, deployclient.ExitDeploymentInactive, deployclient.ErrorExitCode(err))
+	client.AssertExpectations(t)
+}
+
 func TestDeployWithStatusRetry(t *testing.T) {
 	cfg := validConfig()
 	cfg.Retry = true
@@ -299,6 +451,12 @@ func TestValidationFailures(t *testing.T) {
 		{deployclient.ErrAuthRequired.Error(), func(cfg deployclient.Config) deployclient.Config { cfg.APIKey = ""; return cfg }},
 		{deployclient.ErrResourceRequired.Error(), func(cfg deployclient.Config) deployclient.Config { cfg.Resource = nil; return cfg }},
 		{deployclient.ErrMalformedAPIKey.Error(), func(cfg deployclient.Config) deployclient.Config { cfg.APIKey = "malformed"; return cfg }},
+		{deployclient.ErrInvalidConcurrency.Error(), func(cfg deployclient.Config) deployclient.Config { cfg.Concurrency = "wait"; return cfg }},
+		{deployclient.ErrDeploySetRequired.Error(), func(cfg deployclient.Config) deployclient.Config { cfg.Prune = true; return cfg }},
+		{deployclient.ErrInvalidPreview.Error(), func(cfg deployclient.Config) deployclient.Config { cfg.Preview = -1; return cfg }},
+		{deployclient.ErrInvalidPreviewTTL.Error(), func(cfg deployclient.Config) deployclient.Config { cfg.Preview = 12; cfg.PreviewTTL = 0; return cfg }},
+		{deployclient.ErrInvalidDelete.Error(), func(cfg deployclient.Config) deployclient.Config { cfg.Delete = true; cfg.Plan = true; return cfg }},
+		{deployclient.ErrInvalidDelete.Error(), func(cfg deployclient.Config) deployclient.Config { cfg.Delete = true; cfg.Preview = 12; return cfg }},
 	} {
 		cfg := testCase.transform(*valid)
 		err := cfg.Validate()
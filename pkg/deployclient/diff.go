@@ -0,0 +1,158 @@
+package deployclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nais/deploy/pkg/pb"
+)
+
+// Diff prints the changes made to Kubernetes resources by a deployment.
+// If no deployment to compare with is given, the previous deployment of the same resources is used.
+func (d *Deployer) Diff(ctx context.Context, cfg *Config, deploymentID, fromDeploymentID string) error {
+	if len(deploymentID) == 0 {
+		return Errorf(ExitInvocationFailure, "usage: deploy diff DEPLOYMENT_ID [COMPARE_WITH_DEPLOYMENT_ID]")
+	}
+
+	diff, err := d.Client.DiffDeployments(ctx, &pb.DiffDeploymentsRequest{
+		Team:   cfg.Team,
+		ToID:   deploymentID,
+		FromID: fromDeploymentID,
+	})
+	if err != nil {
+		return Errorf(ExitUnavailable, "diff deployments: %s", formatGrpcError(err))
+	}
+
+	if cfg.Output == OutputJSON {
+		return printJSON(os.Stdout, diff)
+	}
+
+	_, err = fmt.Fprintf(os.Stdout, "Changes from deployment %s to %s:\n\n", diff.GetFromID(), diff.GetToID())
+	if err != nil {
+		return err
+	}
+	return PrintDiff(os.Stdout, diff.GetResources())
+}
+
+func resourceName(resource *pb.KubernetesResource) string {
+	apiVersion := resource.GetVersion()
+	if len(resource.GetGroup()) > 0 {
+		apiVersion = resource.GetGroup() + "/" + apiVersion
+	}
+	if len(resource.GetNamespace()) > 0 {
+		return fmt.Sprintf("%s %s %s/%s", apiVersion, resource.GetKind(), resource.GetNamespace(), resource.GetName())
+	}
+	return fmt.Sprintf("%s %s %s", apiVersion, resource.GetKind(), resource.GetName())
+}
+
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+)
+
+// Writes resource changes line by line, optionally in color.
+// After the first write error, nothing more is written, and the error is kept in err.
+type diffPrinter struct {
+	w     io.Writer
+	color bool
+	err   error
+}
+
+func (p *diffPrinter) printf(color, format string, args ...any) {
+	if p.err != nil {
+		return
+	}
+	line := fmt.Sprintf(format, args...)
+	if p.color && len(color) > 0 {
+		line = color + line + colorReset
+	}
+	_, p.err = fmt.Fprintln(p.w, line)
+}
+
+func (p *diffPrinter) resource(diff *pb.ResourceDiff) {
+	switch diff.GetChange() {
+	case pb.ResourceChange_added:
+		p.added(diff.GetResource())
+	case pb.ResourceChange_removed:
+		p.removed(diff.GetResource())
+	case pb.ResourceChange_unchanged:
+		p.unchanged(diff.GetResource())
+	default:
+		p.changed(diff.GetResource(), diff.GetFields())
+	}
+}
+
+func (p *diffPrinter) added(resource *pb.KubernetesResource) {
+	p.printf(colorGreen, "+ %s (added)", resourceName(resource))
+}
+
+func (p *diffPrinter) removed(resource *pb.KubernetesResource) {
+	p.printf(colorRed, "- %s (removed)", resourceName(resource))
+}
+
+func (p *diffPrinter) unchanged(resource *pb.KubernetesResource) {
+	p.printf("", "  %s (unchanged)", resourceName(resource))
+}
+
+func (p *diffPrinter) changed(resource *pb.KubernetesResource, fields []*pb.FieldChange) {
+	p.printf(colorYellow, "~ %s", resourceName(resource))
+	for _, field := range fields {
+		p.field(field)
+	}
+}
+
+func (p *diffPrinter) rejected(resourceError *pb.ResourceError) {
+	if resourceError.GetResource() == nil {
+		p.printf(colorRed, "! %s", resourceError.GetMessage())
+		return
+	}
+	p.printf(colorRed, "! %s (rejected): %s", resourceName(resourceError.GetResource()), resourceError.GetMessage())
+}
+
+func (p *diffPrinter) field(field *pb.FieldChange) {
+	// Scalar values fit on a single line.
+	if !strings.Contains(field.GetOld(), "\n") && !strings.Contains(field.GetNew(), "\n") {
+		switch {
+		case field.Old == nil:
+			p.printf(colorGreen, "    + %s: %s", field.GetPath(), field.GetNew())
+		case field.New == nil:
+			p.printf(colorRed, "    - %s: %s", field.GetPath(), field.GetOld())
+		default:
+			p.printf(colorYellow, "    ~ %s: %s -> %s", field.GetPath(), field.GetOld(), field.GetNew())
+		}
+		return
+	}
+
+	p.printf(colorYellow, "    ~ %s:", field.GetPath())
+	if field.Old != nil {
+		for _, line := range strings.Split(field.GetOld(), "\n") {
+			p.printf(colorRed, "      - %s", line)
+		}
+	}
+	if field.New != nil {
+		for _, line := range strings.Split(field.GetNew(), "\n") {
+			p.printf(colorGreen, "      + %s", line)
+		}
+	}
+}
+
+// PrintDiff writes resource changes in a YAML-like format, where added lines are prefixed with + and removed lines with -.
+func PrintDiff(w io.Writer, diffs []*pb.ResourceDiff) error {
+	p := &diffPrinter{w: w}
+
+	if len(diffs) == 0 {
+		p.printf("", "No changes.")
+		return p.err
+	}
+
+	for _, diff := range diffs {
+		p.resource(diff)
+	}
+
+	return p.err
+}
@@ -22,6 +22,7 @@ const (
 	ExitInternalError
 	ExitTemplateError
 	ExitTimeout
+	ExitPlanChanges
 )
 
 type Error struct {
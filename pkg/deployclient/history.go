@@ -0,0 +1,165 @@
+package deployclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nais/deploy/pkg/pb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+// History prints past deployments of the team to standard output.
+// If a deployment ID is given, only that deployment is printed, along with its statuses and resources.
+func (d *Deployer) History(ctx context.Context, cfg *Config, deploymentID string) error {
+	return d.history(ctx, os.Stdout, cfg, deploymentID)
+}
+
+func (d *Deployer) history(ctx context.Context, w io.Writer, cfg *Config, deploymentID string) error {
+	if len(deploymentID) > 0 {
+		deployment, err := d.Client.GetDeployment(ctx, &pb.GetDeploymentRequest{
+			ID:   deploymentID,
+			Team: cfg.Team,
+		})
+		if err != nil {
+			return Errorf(ExitUnavailable, "get deployment: %s", formatGrpcError(err))
+		}
+		if cfg.Output == OutputJSON {
+			return printJSON(w, deployment)
+		}
+		return printDeployment(w, deployment)
+	}
+
+	request, err := MakeListDeploymentsRequest(*cfg, time.Now())
+	if err != nil {
+		return ErrorWrap(ExitInvocationFailure, err)
+	}
+
+	response, err := d.Client.ListDeployments(ctx, request)
+	if err != nil {
+		return Errorf(ExitUnavailable, "list deployments: %s", formatGrpcError(err))
+	}
+	if cfg.Output == OutputJSON {
+		return printJSON(w, response)
+	}
+	return printDeployments(w, response)
+}
+
+// MakeListDeploymentsRequest builds a deployment listing request from the history flags.
+// Relative values of --since are counted back from now.
+func MakeListDeploymentsRequest(cfg Config, now time.Time) (*pb.ListDeploymentsRequest, error) {
+	request := &pb.ListDeploymentsRequest{
+		Team:   cfg.Team,
+		Limit:  int32(cfg.Limit),
+		Cursor: cfg.Cursor,
+	}
+
+	if len(cfg.Cluster) > 0 {
+		request.Clusters = []string{cfg.Cluster}
+	}
+
+	if len(cfg.Owner) > 0 && len(cfg.Repository) > 0 {
+		request.Repository = cfg.Owner + "/" + cfg.Repository
+	}
+
+	for _, state := range cfg.State {
+		request.States = append(request.States, pb.DeploymentState(pb.DeploymentState_value[state]))
+	}
+
+	if len(cfg.Since) > 0 {
+		since, err := parseSince(cfg.Since, now)
+		if err != nil {
+			return nil, err
+		}
+		request.Since = pb.TimeAsTimestamp(since)
+	}
+
+	return request, nil
+}
+
+func parseSince(since string, now time.Time) (time.Time, error) {
+	duration, err := time.ParseDuration(since)
+	if err == nil {
+		return now.Add(-duration), nil
+	}
+	timestamp, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("since must be a duration such as 24h, or an RFC 3339 timestamp")
+	}
+	return timestamp, nil
+}
+
+func printJSON(w io.Writer, message proto.Message) error {
+	_, err := fmt.Fprintln(w, protojson.Format(message))
+	return err
+}
+
+func deploymentState(deployment *pb.Deployment) string {
+	if deployment.State == nil {
+		return "-"
+	}
+	return deployment.GetState().String()
+}
+
+func printDeployments(w io.Writer, response *pb.ListDeploymentsResponse) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tCREATED\tCLUSTER\tOPERATION\tSTATE\tREPOSITORY")
+	for _, deployment := range response.GetDeployments() {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			deployment.GetID(),
+			deployment.GetCreated().AsTime().Local().Format(time.RFC3339),
+			deployment.GetCluster(),
+			deployment.GetOperation(),
+			deploymentState(deployment),
+			deployment.GetRepository(),
+		)
+	}
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+	if len(response.GetNextCursor()) > 0 {
+		_, err = fmt.Fprintf(w, "\nMore deployments available; continue with --cursor=%s\n", response.GetNextCursor())
+	}
+	return err
+}
+
+func printDeployment(w io.Writer, deployment *pb.Deployment) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "ID:\t%s\n", deployment.GetID())
+	_, _ = fmt.Fprintf(tw, "Team:\t%s\n", deployment.GetTeam())
+	_, _ = fmt.Fprintf(tw, "Cluster:\t%s\n", deployment.GetCluster())
+	_, _ = fmt.Fprintf(tw, "Repository:\t%s\n", deployment.GetRepository())
+	_, _ = fmt.Fprintf(tw, "Created:\t%s\n", deployment.GetCreated().AsTime().Local().Format(time.RFC3339))
+	_, _ = fmt.Fprintf(tw, "Operation:\t%s\n", deployment.GetOperation())
+	_, _ = fmt.Fprintf(tw, "State:\t%s\n", deploymentState(deployment))
+
+	_, _ = fmt.Fprintln(tw, "\nAPIVERSION\tKIND\tNAMESPACE\tNAME")
+	for _, resource := range deployment.GetResources() {
+		apiVersion := resource.GetVersion()
+		if len(resource.GetGroup()) > 0 {
+			apiVersion = resource.GetGroup() + "/" + apiVersion
+		}
+		name := resource.GetName()
+		if resource.GetDeleted() {
+			name += " (deleted)"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", apiVersion, resource.GetKind(), resource.GetNamespace(), name)
+	}
+
+	_, _ = fmt.Fprintln(tw, "\nTIME\tSTATE\tMESSAGE")
+	for _, st := range deployment.GetStatuses() {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", st.Timestamp().Local().Format(time.RFC3339), st.GetState(), st.GetMessage())
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,153 @@
+package deployclient_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nais/deploy/pkg/deployclient"
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func validHistoryConfig() *deployclient.Config {
+	cfg := deployclient.NewConfig()
+	cfg.Team = "aura"
+	cfg.APIKey = "1234567812345678"
+	return cfg
+}
+
+func TestHistoryValidationFailures(t *testing.T) {
+	valid := validHistoryConfig()
+	assert.NoError(t, valid.ValidateHistory())
+
+	for _, testCase := range []struct {
+		errorMsg  string
+		transform func(cfg deployclient.Config) deployclient.Config
+	}{
+		{deployclient.ErrTeamRequired.Error(), func(cfg deployclient.Config) deployclient.Config { cfg.Team = ""; return cfg }},
+		{deployclient.ErrAuthRequired.Error(), func(cfg deployclient.Config) deployclient.Config { cfg.APIKey = ""; return cfg }},
+		{deployclient.ErrInvalidOutput.Error(), func(cfg deployclient.Config) deployclient.Config { cfg.Output = "yaml"; return cfg }},
+		{`invalid deployment state "done"`, func(cfg deployclient.Config) deployclient.Config { cfg.State = []string{"done"}; return cfg }},
+	} {
+		cfg := testCase.transform(*valid)
+		err := cfg.ValidateHistory()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), testCase.errorMsg)
+	}
+}
+
+func TestMakeListDeploymentsRequest(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	cfg := validHistoryConfig()
+	cfg.Cluster = "dev-fss"
+	cfg.Owner = "navikt"
+	cfg.Repository = "myrepo"
+	cfg.State = []string{"failure", "error"}
+	cfg.Since = "24h"
+	cfg.Limit = 10
+	cfg.Cursor = "abc"
+
+	request, err := deployclient.MakeListDeploymentsRequest(*cfg, now)
+	assert.NoError(t, err)
+	assert.Equal(t, "aura", request.GetTeam())
+	assert.Equal(t, []string{"dev-fss"}, request.GetClusters())
+	assert.Equal(t, "navikt/myrepo", request.GetRepository())
+	assert.Equal(t, []pb.DeploymentState{pb.DeploymentState_failure, pb.DeploymentState_error}, request.GetStates())
+	assert.Equal(t, now.Add(-24*time.Hour), request.GetSince().AsTime())
+	assert.Equal(t, int32(10), request.GetLimit())
+	assert.Equal(t, "abc", request.GetCursor())
+
+	cfg.Since = "2024-05-01T00:00:00Z"
+	request, err = deployclient.MakeListDeploymentsRequest(*cfg, now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), request.GetSince().AsTime())
+
+	cfg.Since = "yesterday"
+	_, err = deployclient.MakeListDeploymentsRequest(*cfg, now)
+	assert.Error(t, err)
+}
+
+func TestHistory(t *testing.T) {
+	ctx := context.Background()
+	cfg := validHistoryConfig()
+
+	t.Run("list deployments", func(t *testing.T) {
+		client := &pb.MockDeployClient{}
+		client.On("ListDeployments", mock.Anything, &pb.ListDeploymentsRequest{Team: "aura"}).Return(&pb.ListDeploymentsResponse{
+			Deployments: []*pb.Deployment{
+				{ID: "2", Team: "aura", Cluster: "dev-fss", State: pb.DeploymentState_success.Enum()},
+				{ID: "1", Team: "aura", Cluster: "dev-fss"},
+			},
+			NextCursor: "next",
+		}, nil).Once()
+
+		d := deployclient.Deployer{Client: client}
+		err := d.History(ctx, cfg, "")
+		assert.NoError(t, err)
+		client.AssertExpectations(t)
+	})
+
+	t.Run("get single deployment", func(t *testing.T) {
+		client := &pb.MockDeployClient{}
+		client.On("GetDeployment", mock.Anything, &pb.GetDeploymentRequest{ID: "1", Team: "aura"}).Return(&pb.Deployment{
+			ID:   "1",
+			Team: "aura",
+			Statuses: []*pb.DeploymentStatus{
+				{State: pb.DeploymentState_success, Message: "done", Time: pb.TimeAsTimestamp(time.Now())},
+			},
+			Resources: []*pb.KubernetesResource{
+				{Group: "nais.io", Version: "v1alpha1", Kind: "Application", Name: "myapp", Namespace: "aura"},
+			},
+		}, nil).Once()
+
+		d := deployclient.Deployer{Client: client}
+		err := d.History(ctx, cfg, "1")
+		assert.NoError(t, err)
+		client.AssertExpectations(t)
+	})
+
+	t.Run("deployment not found", func(t *testing.T) {
+		client := &pb.MockDeployClient{}
+		client.On("GetDeployment", mock.Anything, mock.Anything).Return(nil, status.Errorf(codes.NotFound, "deployment 1 not found")).Once()
+
+		d := deployclient.Deployer{Client: client}
+		err := d.History(ctx, cfg, "1")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "deployment 1 not found")
+	})
+}
+
+func TestPrintDiff(t *testing.T) {
+	oldValue, newValue := "2", "3"
+	block := "- name: foo\n  image: bar"
+	buf := &strings.Builder{}
+
+	err := deployclient.PrintDiff(buf, []*pb.ResourceDiff{
+		{
+			Resource: &pb.KubernetesResource{Group: "apps", Version: "v1", Kind: "Deployment", Namespace: "aura", Name: "foo"},
+			Change:   pb.ResourceChange_changed,
+			Fields: []*pb.FieldChange{
+				{Path: "spec.replicas", Old: &oldValue, New: &newValue},
+				{Path: "spec.template.spec.containers", New: &block},
+			},
+		},
+		{
+			Resource: &pb.KubernetesResource{Version: "v1", Kind: "ConfigMap", Namespace: "aura", Name: "foo"},
+			Change:   pb.ResourceChange_removed,
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `~ apps/v1 Deployment aura/foo
+    ~ spec.replicas: 2 -> 3
+    ~ spec.template.spec.containers:
+      + - name: foo
+      +   image: bar
+- v1 ConfigMap aura/foo (removed)
+`, buf.String())
+}
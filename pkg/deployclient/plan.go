@@ -0,0 +1,264 @@
+package deployclient
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nais/deploy/pkg/deployd/kubeclient"
+	"github.com/nais/deploy/pkg/k8sutils"
+	"github.com/nais/deploy/pkg/pb"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// LiveResourceGetter returns the object currently in the cluster, or nil if it does not exist.
+type LiveResourceGetter func(ctx context.Context, resource unstructured.Unstructured) (*unstructured.Unstructured, error)
+
+// KubernetesGetter fetches live objects using the system Kubernetes configuration, either in-cluster or from $KUBECONFIG.
+func KubernetesGetter() (LiveResourceGetter, error) {
+	client, err := kubeclient.DefaultClient()
+	if err != nil {
+		return nil, fmt.Errorf("configure Kubernetes client: %w", err)
+	}
+
+	return func(ctx context.Context, resource unstructured.Unstructured) (*unstructured.Unstructured, error) {
+		resourceInterface, err := client.ResourceInterface(&resource)
+		if err != nil {
+			return nil, err
+		}
+		live, err := resourceInterface.Get(ctx, resource.GetName(), metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return live, err
+	}, nil
+}
+
+// Plan asks NAIS deploy what a deployment request would change in the cluster, and prints the answer.
+// The answer is also written to the GitHub Actions step summary, if there is one.
+// If any resource would be created or changed, an error with the exit code ExitPlanChanges is returned.
+func (d *Deployer) Plan(ctx context.Context, cfg *Config, deployRequest *pb.DeploymentRequest) error {
+	log.Infof("Asking NAIS deploy at %s what the deployment would change...", cfg.DeployServerURL)
+
+	var plan *pb.DeploymentPlan
+	err := retryUnavailable(cfg.RetryInterval, cfg.Retry, func() error {
+		var err error
+		plan, err = d.Client.Plan(ctx, deployRequest)
+		return err
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return Errorf(ExitTimeout, "plan timed out: %s", ctx.Err())
+		}
+		return Errorf(ExitNoDeployment, "plan deployment: %s", formatGrpcError(err))
+	}
+
+	writeStepSummary(func(w io.Writer) error {
+		return PlanSummary(w, plan)
+	})
+
+	return reportPlan(os.Stdout, plan, useColor(cfg))
+}
+
+// PlanLocal prints how the resources in a deployment request differ from the objects currently in the cluster,
+// as seen by the given getter. Fields that are set in the cluster, but not in the request, are not compared.
+// If any resource would be created or changed, an error with the exit code ExitPlanChanges is returned.
+func PlanLocal(ctx context.Context, cfg *Config, deployRequest *pb.DeploymentRequest, get LiveResourceGetter) error {
+	plan, err := localPlan(ctx, deployRequest, get)
+	if err != nil {
+		return err
+	}
+	return reportPlan(os.Stdout, plan, useColor(cfg))
+}
+
+func localPlan(ctx context.Context, deployRequest *pb.DeploymentRequest, get LiveResourceGetter) (*pb.DeploymentPlan, error) {
+	resources, err := k8sutils.ResourcesFromDeploymentRequest(deployRequest)
+	if err != nil {
+		return nil, ErrorWrap(ExitInternalError, err)
+	}
+
+	plan := &pb.DeploymentPlan{
+		Cluster: deployRequest.GetCluster(),
+	}
+
+	for _, desired := range resources {
+		if len(desired.GetNamespace()) == 0 {
+			desired.SetNamespace(deployRequest.GetTeam())
+		}
+
+		live, err := get(ctx, desired)
+		if err != nil {
+			return nil, Errorf(ExitUnavailable, "get %s from cluster: %s", resourceName(k8sutils.ResourceIdentifier(desired).KubernetesResource()), err)
+		}
+
+		desired = secretStringData(desired)
+		if live != nil {
+			live.Object = pruneTo(live.Object, desired.Object).(map[string]any)
+		}
+
+		plan.Resources = append(plan.Resources, k8sutils.DiffLive(live, desired))
+	}
+
+	return plan, nil
+}
+
+// Print a plan, and return an error with an exit code telling whether the deployment would change anything.
+func reportPlan(w io.Writer, plan *pb.DeploymentPlan, color bool) error {
+	err := PrintPlan(w, plan, color)
+	if err != nil {
+		return err
+	}
+
+	if len(plan.GetErrors()) > 0 {
+		return Errorf(ExitDeploymentFailure, "deployment would be rejected by the cluster (%d errors)", len(plan.GetErrors()))
+	}
+
+	changes := planChanges(plan)
+	if changes > 0 {
+		return Errorf(ExitPlanChanges, "deployment would change %d resource(s)", changes)
+	}
+
+	return nil
+}
+
+// PrintPlan writes every resource in a plan along with what would happen to it, followed by the resources the cluster would reject.
+func PrintPlan(w io.Writer, plan *pb.DeploymentPlan, color bool) error {
+	p := &diffPrinter{w: w, color: color}
+
+	for _, diff := range plan.GetResources() {
+		p.resource(diff)
+	}
+	for _, resourceError := range plan.GetErrors() {
+		p.rejected(resourceError)
+	}
+
+	return p.err
+}
+
+// PlanSummary writes a plan in GitHub flavored markdown.
+func PlanSummary(w io.Writer, plan *pb.DeploymentPlan) error {
+	counts := make(map[pb.ResourceChange]int)
+	for _, diff := range plan.GetResources() {
+		counts[diff.GetChange()]++
+	}
+
+	text := &strings.Builder{}
+	fmt.Fprintf(text, "## 📋 NAIS deploy plan\n\n")
+	fmt.Fprintf(text, "Cluster `%s`: %d to add, %d to change, %d unchanged", plan.GetCluster(), counts[pb.ResourceChange_added], counts[pb.ResourceChange_changed], counts[pb.ResourceChange_unchanged])
+	if len(plan.GetErrors()) > 0 {
+		fmt.Fprintf(text, ", %d rejected", len(plan.GetErrors()))
+	}
+	fmt.Fprintf(text, ".\n\n```diff\n")
+
+	err := PrintPlan(text, plan, false)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(text, "```\n")
+
+	_, err = io.WriteString(w, text.String())
+	return err
+}
+
+func planChanges(plan *pb.DeploymentPlan) int {
+	changes := 0
+	for _, diff := range plan.GetResources() {
+		if diff.GetChange() != pb.ResourceChange_unchanged {
+			changes++
+		}
+	}
+	return changes
+}
+
+// Append to the GitHub Actions step summary, unless there is none, or it has been disabled with NAIS_DEPLOY_SUMMARY=false.
+func writeStepSummary(write func(w io.Writer) error) {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if len(path) == 0 || strings.ToLower(os.Getenv("NAIS_DEPLOY_SUMMARY")) == "false" {
+		return
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err == nil {
+		err = write(file)
+		_ = file.Close()
+	}
+	if err != nil {
+		log.Warnf("Write step summary: %s", err)
+	}
+}
+
+// Remove fields from a live object that are not present in the desired object, such as defaults filled in by the cluster.
+// Lists of different lengths are kept as is, as their elements can not be matched up.
+func pruneTo(live, desired any) any {
+	switch desired := desired.(type) {
+	case map[string]any:
+		liveMap, ok := live.(map[string]any)
+		if !ok {
+			return live
+		}
+		pruned := make(map[string]any, len(desired))
+		for key, value := range desired {
+			if liveValue, ok := liveMap[key]; ok {
+				pruned[key] = pruneTo(liveValue, value)
+			}
+		}
+		return pruned
+	case []any:
+		liveList, ok := live.([]any)
+		if !ok || len(liveList) != len(desired) {
+			return live
+		}
+		pruned := make([]any, len(liveList))
+		for i := range liveList {
+			pruned[i] = pruneTo(liveList[i], desired[i])
+		}
+		return pruned
+	default:
+		return live
+	}
+}
+
+// Move the stringData of a Secret into its data, as the cluster does when the Secret is written.
+// The cluster never returns stringData, so comparing it with the live object would always show a change.
+func secretStringData(resource unstructured.Unstructured) unstructured.Unstructured {
+	gvk := resource.GroupVersionKind()
+	if gvk.Group != "" || gvk.Kind != "Secret" {
+		return resource
+	}
+	stringData, found, err := unstructured.NestedStringMap(resource.Object, "stringData")
+	if !found || err != nil {
+		return resource
+	}
+
+	converted := resource.DeepCopy()
+	data, _, _ := unstructured.NestedMap(converted.Object, "data")
+	if data == nil {
+		data = make(map[string]any, len(stringData))
+	}
+	for key, value := range stringData {
+		data[key] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	unstructured.RemoveNestedField(converted.Object, "stringData")
+	_ = unstructured.SetNestedMap(converted.Object, data, "data")
+
+	return *converted
+}
+
+// Use colors when writing to a terminal or a GitHub Actions log, unless disabled with $NO_COLOR.
+func useColor(cfg *Config) bool {
+	if _, found := os.LookupEnv("NO_COLOR"); found {
+		return false
+	}
+	if cfg.Actions {
+		return true
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
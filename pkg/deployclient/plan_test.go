@@ -0,0 +1,121 @@
+package deployclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const planResources = `[
+	{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "settings", "namespace": "aura"}, "data": {"level": "debug"}},
+	{"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "credentials", "namespace": "aura"}, "stringData": {"password": "hunter3"}},
+	{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "app"}, "spec": {"replicas": 2, "template": {"spec": {"containers": [{"name": "app", "image": "app:2"}]}}}},
+	{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "app", "namespace": "aura"}}
+]`
+
+// Objects as returned by the Kubernetes API server, with server-managed fields and defaults filled in.
+var liveObjects = map[string]string{
+	"settings":    `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "settings", "namespace": "aura", "uid": "1", "resourceVersion": "42", "managedFields": [{"manager": "deployd"}], "annotations": {"deploy.nais.io/github-sha": "abc"}}, "data": {"level": "debug"}}`,
+	"credentials": `{"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "credentials", "namespace": "aura"}, "type": "Opaque", "data": {"password": "aHVudGVyMg=="}}`,
+	"app":         `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "app", "namespace": "aura", "generation": 3}, "spec": {"replicas": 1, "strategy": {"type": "RollingUpdate"}, "template": {"spec": {"containers": [{"name": "app", "image": "app:1", "imagePullPolicy": "Always"}]}}}, "status": {"replicas": 1}}`,
+}
+
+func fakeGetter(ctx context.Context, resource unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if resource.GetNamespace() != "aura" {
+		return nil, fmt.Errorf("unexpected namespace %q", resource.GetNamespace())
+	}
+	data, ok := liveObjects[resource.GetName()]
+	if !ok || resource.GetKind() == "Service" {
+		return nil, nil
+	}
+	live := &unstructured.Unstructured{}
+	err := live.UnmarshalJSON([]byte(data))
+	return live, err
+}
+
+func TestLocalPlan(t *testing.T) {
+	kube, err := pb.KubernetesFromJSONResources(json.RawMessage(planResources))
+	assert.NoError(t, err)
+
+	plan, err := localPlan(context.Background(), &pb.DeploymentRequest{Team: "aura", Kubernetes: kube}, fakeGetter)
+	assert.NoError(t, err)
+
+	buf := &strings.Builder{}
+	err = reportPlan(buf, plan, false)
+	assert.Equal(t, ExitPlanChanges, ErrorExitCode(err))
+	assert.Equal(t, `  v1 ConfigMap aura/settings (unchanged)
+~ v1 Secret aura/credentials
+    ~ data.password: <redacted> -> <redacted>
+~ apps/v1 Deployment aura/app
+    ~ spec.replicas: 1 -> 2
+    ~ spec.template.spec.containers[0].image: app:1 -> app:2
++ v1 Service aura/app (added)
+`, buf.String())
+}
+
+func TestLocalPlanWithoutChanges(t *testing.T) {
+	secret := `{"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "credentials", "namespace": "aura"}, "stringData": {"password": "hunter2"}}`
+	kube, err := pb.KubernetesFromJSONResources(json.RawMessage(`[` + liveObjects["settings"] + `,` + secret + `]`))
+	assert.NoError(t, err)
+
+	plan, err := localPlan(context.Background(), &pb.DeploymentRequest{Team: "aura", Kubernetes: kube}, fakeGetter)
+	assert.NoError(t, err)
+
+	buf := &strings.Builder{}
+	err = reportPlan(buf, plan, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "  v1 ConfigMap aura/settings (unchanged)\n  v1 Secret aura/credentials (unchanged)\n", buf.String())
+}
+
+func TestPlan(t *testing.T) {
+	summary := filepath.Join(t.TempDir(), "summary.md")
+	assert.NoError(t, os.WriteFile(summary, nil, 0o644))
+	t.Setenv("GITHUB_STEP_SUMMARY", summary)
+
+	cfg := NewConfig()
+	request := &pb.DeploymentRequest{Team: "aura", Cluster: "dev-gcp"}
+	replicas, newReplicas := "1", "2"
+
+	client := &pb.MockDeployClient{}
+	client.On("Plan", mock.Anything, request).Return(&pb.DeploymentPlan{
+		ID:      "1",
+		Cluster: "dev-gcp",
+		Resources: []*pb.ResourceDiff{
+			{
+				Resource: &pb.KubernetesResource{Group: "apps", Version: "v1", Kind: "Deployment", Namespace: "aura", Name: "app"},
+				Change:   pb.ResourceChange_changed,
+				Fields:   []*pb.FieldChange{{Path: "spec.replicas", Old: &replicas, New: &newReplicas}},
+			},
+		},
+		Errors: []*pb.ResourceError{
+			{
+				Resource: &pb.KubernetesResource{Version: "v1", Kind: "ConfigMap", Namespace: "aura", Name: "app"},
+				Message:  "admission webhook denied the request",
+			},
+		},
+	}, nil).Once()
+
+	d := Deployer{Client: client}
+	err := d.Plan(context.Background(), cfg, request)
+	assert.Equal(t, ExitDeploymentFailure, ErrorExitCode(err))
+	client.AssertExpectations(t)
+
+	written, err := os.ReadFile(summary)
+	assert.NoError(t, err)
+	assert.Equal(t, "## 📋 NAIS deploy plan\n\n"+
+		"Cluster `dev-gcp`: 0 to add, 1 to change, 0 unchanged, 1 rejected.\n\n"+
+		"```diff\n"+
+		"~ apps/v1 Deployment aura/app\n"+
+		"    ~ spec.replicas: 1 -> 2\n"+
+		"! v1 ConfigMap aura/app (rejected): admission webhook denied the request\n"+
+		"```\n", string(written))
+}
@@ -0,0 +1,97 @@
+package deployclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nais/deploy/pkg/k8sutils"
+	"github.com/nais/deploy/pkg/pb"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PreviewName returns the name of the preview environment of a pull request.
+func PreviewName(pullRequest int) string {
+	return fmt.Sprintf("pr-%d", pullRequest)
+}
+
+// MakePreview makes a resource part of a preview environment. The name of the environment is appended to the name
+// of the resource, so that it does not replace the resource deployed from the main branch. The resource is labelled
+// with the name of the environment, and annotated with the time the environment expires.
+func MakePreview(resource json.RawMessage, preview string, expires time.Time) (json.RawMessage, error) {
+	decoded := make(map[string]json.RawMessage)
+	err := json.Unmarshal(resource, &decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &v1.ObjectMeta{}
+	err = json.Unmarshal(decoded["metadata"], meta)
+	if err != nil {
+		return nil, fmt.Errorf("error in metadata field: %w", err)
+	}
+
+	suffix := "-" + preview
+	if !strings.HasSuffix(meta.Name, suffix) {
+		meta.Name += suffix
+	}
+	if meta.Labels == nil {
+		meta.Labels = make(map[string]string)
+	}
+	meta.Labels[k8sutils.PreviewLabel] = preview
+
+	encoded, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	decoded["metadata"] = encoded
+
+	resource, err = json.Marshal(decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	return InjectAnnotations(resource, map[string]string{
+		k8sutils.PreviewExpiresAnnotation: expires.UTC().Format(time.RFC3339),
+	})
+}
+
+// TeardownPreview asks NAIS deploy to delete all resources of the preview environment of a pull request,
+// and waits for the deletion like any other deployment.
+func (d *Deployer) TeardownPreview(ctx context.Context, cfg *Config) error {
+	if cfg.Preview <= 0 || len(cfg.Cluster) == 0 {
+		return Errorf(ExitInvocationFailure, "usage: deploy teardown-preview --preview PULL_REQUEST --cluster CLUSTER")
+	}
+
+	deadline, _ := ctx.Deadline()
+	deployRequest := &pb.DeploymentRequest{
+		Team:     cfg.Team,
+		Cluster:  cfg.Cluster,
+		Deadline: pb.TimeAsTimestamp(deadline),
+		Preview:  PreviewName(cfg.Preview),
+	}
+	var repository string
+	if len(cfg.Owner) > 0 && len(cfg.Repository) > 0 {
+		repository = cfg.Owner + "/" + cfg.Repository
+	}
+
+	log.Infof("Tearing down preview environment %q in cluster %q", deployRequest.GetPreview(), deployRequest.GetCluster())
+
+	return d.deploy(ctx, cfg, deployRequest, func(ctx context.Context, deployRequest *pb.DeploymentRequest) (*pb.DeploymentStatus, error) {
+		deployStatus, err := d.Client.TeardownPreview(ctx, &pb.TeardownPreviewRequest{
+			Team:        deployRequest.GetTeam(),
+			Cluster:     deployRequest.GetCluster(),
+			Repository:  repository,
+			Preview:     deployRequest.GetPreview(),
+			Deadline:    deployRequest.GetDeadline(),
+			TraceParent: deployRequest.GetTraceParent(),
+		})
+		if err == nil {
+			deployRequest.Cluster = deployStatus.GetRequest().GetCluster()
+		}
+		return deployStatus, err
+	})
+}
@@ -0,0 +1,88 @@
+package deployclient_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nais/deploy/pkg/deployclient"
+	"github.com/nais/deploy/pkg/k8sutils"
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/nais/deploy/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPreparePreview(t *testing.T) {
+	cfg := validConfig()
+	cfg.Preview = 12
+	cfg.PreviewTTL = time.Hour
+	cfg.WorkloadImage = "ghcr.io/nais/testapp:pr-12"
+	cfg.DeploySet = "testapp"
+
+	request, err := deployclient.Prepare(context.Background(), cfg)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "pr-12", request.GetPreview())
+	assert.Equal(t, "testapp-pr-12", request.GetDeploySet(), "preview has a deploy set of its own")
+	assert.WithinDuration(t, time.Now().Add(time.Hour), request.GetPreviewExpires().AsTime(), time.Minute)
+
+	resources, err := k8sutils.ResourcesFromDeploymentRequest(request)
+	assert.NoError(t, err)
+	assert.Len(t, resources, 2)
+
+	for _, resource := range resources {
+		assert.Equal(t, "testapp-pr-12", resource.GetName(), "%s is renamed", resource.GetKind())
+		assert.Equal(t, "pr-12", resource.GetLabels()[k8sutils.PreviewLabel])
+		assert.Equal(t, request.GetPreviewExpires().AsTime().UTC().Format(time.RFC3339), resource.GetAnnotations()[k8sutils.PreviewExpiresAnnotation])
+	}
+	assert.Equal(t, "Image", resources[0].GetKind())
+	assert.Equal(t, "aura", resources[1].GetLabels()["team"], "existing labels are kept")
+}
+
+func TestMakePreview(t *testing.T) {
+	expires := time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC)
+	resource := []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo-pr-12"}}`)
+
+	preview, err := deployclient.MakePreview(resource, "pr-12", expires)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"apiVersion": "v1",
+		"kind": "ConfigMap",
+		"metadata": {
+			"name": "foo-pr-12",
+			"labels": {"deploy.nais.io/preview": "pr-12"},
+			"annotations": {"deploy.nais.io/preview-expires": "2026-10-20T12:00:00Z"},
+			"creationTimestamp": null
+		}
+	}`, string(preview), "names that already end with the preview name are kept")
+}
+
+func TestTeardownPreview(t *testing.T) {
+	cfg := validConfig()
+	cfg.Team = "aura"
+	cfg.Owner = "navikt"
+	cfg.Preview = 12
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	_, _ = telemetry.New(ctx, "test", "")
+
+	client := &pb.MockDeployClient{}
+	client.On("TeardownPreview", mock.Anything, mock.MatchedBy(func(req *pb.TeardownPreviewRequest) bool {
+		return req.GetTeam() == "aura" &&
+			req.GetCluster() == "dev-fss" &&
+			req.GetRepository() == "navikt/myrepo" &&
+			req.GetPreview() == "pr-12" &&
+			req.GetDeadline() != nil
+	})).Return(&pb.DeploymentStatus{
+		Request: &pb.DeploymentRequest{ID: "2", Team: "aura", Cluster: "dev-fss", Preview: "pr-12", Delete: true},
+		Time:    pb.TimeAsTimestamp(time.Now()),
+		State:   pb.DeploymentState_queued,
+	}, nil).Once()
+
+	d := deployclient.Deployer{Client: client}
+	err := d.TeardownPreview(ctx, cfg)
+
+	assert.NoError(t, err)
+	client.AssertExpectations(t)
+}
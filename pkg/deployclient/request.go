@@ -11,7 +11,10 @@ func MakeDeploymentRequest(cfg Config, deadline time.Time, kubernetes *pb.Kubern
 	annotations := BuildEnvironmentAnnotations()
 	return &pb.DeploymentRequest{
 		Cluster:           cfg.Cluster,
+		Concurrency:       pb.ConcurrencyPolicy(pb.ConcurrencyPolicy_value[cfg.Concurrency]),
 		Deadline:          pb.TimeAsTimestamp(deadline),
+		DeploySet:         cfg.DeploySet,
+		FreezeOverride:    cfg.FreezeOverride,
 		GitRefSha:         annotations[CommitRef],
 		GithubEnvironment: cfg.Environment,
 		Kubernetes:        kubernetes,
@@ -19,6 +22,8 @@ func MakeDeploymentRequest(cfg Config, deadline time.Time, kubernetes *pb.Kubern
 			Owner: cfg.Owner,
 			Name:  cfg.Repository,
 		},
+		Prune:            cfg.Prune,
+		Rollback:         cfg.Rollback,
 		Team:             cfg.Team,
 		Time:             pb.TimeAsTimestamp(time.Now()),
 		TriggerUrl:       annotations[GithubWorkflowRunURL],
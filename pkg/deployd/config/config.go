@@ -7,16 +7,25 @@ import (
 )
 
 type Config struct {
-	AutoCreateServiceAccount  bool   `json:"auto-create-service-account"`
-	Cluster                   string `json:"cluster"`
-	GRPC                      GRPC   `json:"grpc"`
-	HookdKey                  string `json:"hookd-key"`
-	LogFormat                 string `json:"log-format"`
-	LogLevel                  string `json:"log-level"`
-	MetricsListenAddr         string `json:"metrics-listen-address"`
-	MetricsPath               string `json:"metrics-path"`
-	OpenTelemetryCollectorURL string `json:"otel-exporter-otlp-endpoint"`
-	TeamNamespaces            bool   `json:"team-namespaces"`
+	AutoCreateServiceAccount  bool           `json:"auto-create-service-account"`
+	Cluster                   string         `json:"cluster"`
+	ConditionWatch            ConditionWatch `json:"condition-watch"`
+	DeployStrategy            string         `json:"deploy-strategy"`
+	GRPC                      GRPC           `json:"grpc"`
+	HookdKey                  string         `json:"hookd-key"`
+	InstanceID                string         `json:"instance-id"`
+	LogFormat                 string         `json:"log-format"`
+	LogLevel                  string         `json:"log-level"`
+	MetricsListenAddr         string         `json:"metrics-listen-address"`
+	MetricsPath               string         `json:"metrics-path"`
+	OpenTelemetryCollectorURL string         `json:"otel-exporter-otlp-endpoint"`
+	TeamNamespaces            bool           `json:"team-namespaces"`
+}
+
+type ConditionWatch struct {
+	Resources        []string `json:"resources"`
+	SuccessCondition string   `json:"success-condition"`
+	FailureCondition string   `json:"failure-condition"`
 }
 
 type GRPC struct {
@@ -27,10 +36,15 @@ type GRPC struct {
 
 const (
 	Cluster                  = "cluster"
+	ConditionWatchResources  = "condition-watch.resources"
+	ConditionWatchSuccess    = "condition-watch.success-condition"
+	ConditionWatchFailure    = "condition-watch.failure-condition"
+	DeployStrategy           = "deploy-strategy"
 	GrpcAuthentication       = "grpc.authentication"
 	GrpcServer               = "grpc.server"
 	GrpcUseTLS               = "grpc.use-tls"
 	HookdKey                 = "hookd-key"
+	InstanceID               = "instance-id"
 	LogFormat                = "log-format"
 	LogLevel                 = "log-level"
 	MetricsListenAddr        = "metrics-listen-address"
@@ -50,8 +64,13 @@ func Initialize() *Config {
 	flag.Bool(GrpcAuthentication, false, "Use authentication on gRPC connection.")
 	flag.Bool(GrpcUseTLS, false, "Use TLS when connecting to gRPC server.")
 	flag.String(Cluster, "local", "Apply changes only within this cluster.")
+	flag.StringSlice(ConditionWatchResources, []string{}, "Monitor rollout of these resource kinds using their status conditions, in the form Kind.version.group, e.g. Topic.v1.kafka.nais.io.")
+	flag.String(ConditionWatchSuccess, "Ready=True", "Status condition that signals a successful rollout of resources monitored by condition, in the form Type=Status.")
+	flag.String(ConditionWatchFailure, "", "Status condition that signals a failed rollout of resources monitored by condition, in the form Type=Status.")
+	flag.String(DeployStrategy, "create-or-update", "Default strategy for saving resources, either 'create-or-update' or 'server-side-apply'. Can be overridden per resource with the deploy.nais.io/deploy-strategy annotation.")
 	flag.String(GrpcServer, "127.0.0.1:9090", "gRPC server endpoint on hookd.")
 	flag.String(HookdKey, "", "Pre-shared key used for hookd authentication.")
+	flag.String(InstanceID, "", "Identifies this instance to hookd when several instances serve the same cluster. Defaults to the host name.")
 	flag.String(LogFormat, "text", "Log format, either 'json' or 'text'.")
 	flag.String(LogLevel, "debug", "Logging verbosity level.")
 	flag.String(MetricsListenAddr, "127.0.0.1:8081", "Serve metrics on this address.")
@@ -0,0 +1,168 @@
+package deployd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nais/deploy/pkg/deployd/kubeclient"
+	"github.com/nais/deploy/pkg/deployd/operation"
+	"github.com/nais/deploy/pkg/k8sutils"
+	"github.com/nais/deploy/pkg/pb"
+	"go.opentelemetry.io/otel/codes"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// How often to check whether a deleted resource is gone, while waiting for its finalizers.
+var deletionPollInterval = time.Second * 5
+
+// A resource that deployd has asked the cluster to delete.
+type deletedResource struct {
+	resource unstructured.Unstructured
+	uid      types.UID
+}
+
+// Delete removes every resource in a delete request from the cluster, and reports the outcome on the status channel.
+// Deletion is not complete until every deleted resource is gone, which can take a while for resources with finalizers.
+// Resources that are already gone are skipped. If the request belongs to a preview environment,
+// resources that are not labelled as part of that environment are left alone.
+func Delete(op *operation.Operation, client kubeclient.Interface) {
+	op.Logger.Infof("Starting deletion")
+	defer op.Trace.End()
+	defer op.Cancel()
+
+	resources, err := op.ExtractResources()
+	if err != nil {
+		op.StatusChan <- pb.NewFailureStatus(op.Request, err)
+		op.Trace.SetStatus(codes.Error, err.Error())
+		return
+	}
+
+	op.StatusChan <- pb.NewInProgressStatus(op.Request, "Deleting %d resources", len(resources))
+
+	errs := 0
+	deleted := make([]deletedResource, 0, len(resources))
+	for _, resource := range resources {
+		if op.Context.Err() != nil {
+			break
+		}
+
+		identifier := k8sutils.ResourceIdentifier(resource).String()
+		message, uid, err := deleteResource(op.Context, client, resource, op.Request.GetPreview())
+		if err != nil {
+			errs++
+			op.Logger.Errorf("Delete %s: %s", identifier, err)
+			op.StatusChan <- pb.NewInProgressStatus(op.Request, "Failed to delete %s: %s", identifier, err)
+			continue
+		}
+		if len(uid) > 0 {
+			deleted = append(deleted, deletedResource{resource: resource, uid: uid})
+		}
+		op.Logger.Infof("%s: %s", identifier, message)
+		op.StatusChan <- pb.NewInProgressStatus(op.Request, "%s: %s", identifier, message)
+	}
+
+	for _, d := range deleted {
+		if op.Context.Err() != nil {
+			break
+		}
+
+		identifier := k8sutils.ResourceIdentifier(d.resource).String()
+		err = waitForDeletion(op.Context, client, d.resource, d.uid, func(message string) {
+			op.Logger.Infof("%s: %s", identifier, message)
+			op.StatusChan <- pb.NewInProgressStatus(op.Request, "%s: %s", identifier, message)
+		})
+		if err != nil {
+			errs++
+			op.Logger.Errorf("Wait for deletion of %s: %s", identifier, err)
+			op.StatusChan <- pb.NewInProgressStatus(op.Request, "Failed to delete %s: %s", identifier, err)
+		}
+	}
+
+	switch {
+	case op.Aborted():
+		op.StatusChan <- pb.NewCancelledStatus(op.Request)
+		op.Trace.SetStatus(codes.Error, "Deletion cancelled")
+	case op.Context.Err() != nil:
+		op.StatusChan <- pb.NewFailureStatus(op.Request, op.Context.Err())
+		op.Trace.SetStatus(codes.Error, op.Context.Err().Error())
+	case errs > 0:
+		err = fmt.Errorf("failed to delete %d of %d resources", errs, len(resources))
+		op.StatusChan <- pb.NewFailureStatus(op.Request, err)
+		op.Trace.SetStatus(codes.Error, err.Error())
+	default:
+		op.StatusChan <- pb.NewSuccessStatus(op.Request)
+		op.Trace.SetStatus(codes.Ok, "All resources deleted")
+	}
+}
+
+// Delete a single resource, and return a message describing what happened to it.
+// If the resource was deleted, its UID is returned as well.
+func deleteResource(ctx context.Context, client kubeclient.Interface, resource unstructured.Unstructured, preview string) (string, types.UID, error) {
+	resourceInterface, err := client.ResourceInterface(&resource)
+	if err != nil {
+		return "", "", err
+	}
+
+	existing, err := resourceInterface.Get(ctx, resource.GetName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return "already deleted", "", nil
+	} else if err != nil {
+		return "", "", fmt.Errorf("get existing resource: %w", err)
+	}
+
+	if len(preview) > 0 && existing.GetLabels()[k8sutils.PreviewLabel] != preview {
+		return fmt.Sprintf("not part of preview environment %q; leaving it alone", preview), "", nil
+	}
+
+	// Make sure the resource that was checked is the one being deleted.
+	uid := existing.GetUID()
+	propagation := metav1.DeletePropagationBackground
+	err = resourceInterface.Delete(ctx, resource.GetName(), metav1.DeleteOptions{
+		Preconditions:     &metav1.Preconditions{UID: &uid},
+		PropagationPolicy: &propagation,
+	})
+	if errors.IsNotFound(err) {
+		return "already deleted", "", nil
+	} else if err != nil {
+		return "", "", err
+	}
+
+	return "deleted", uid, nil
+}
+
+// Wait until a deleted resource is gone from the cluster, or has been replaced by a new object with the same name.
+// While the resource is kept around by finalizers, progress is reported whenever the remaining finalizers change.
+func waitForDeletion(ctx context.Context, client kubeclient.Interface, resource unstructured.Unstructured, uid types.UID, report func(message string)) error {
+	resourceInterface, err := client.ResourceInterface(&resource)
+	if err != nil {
+		return err
+	}
+
+	last := ""
+	for {
+		existing, err := resourceInterface.Get(ctx, resource.GetName(), metav1.GetOptions{})
+		if errors.IsNotFound(err) || (err == nil && existing.GetUID() != uid) {
+			return nil
+		}
+
+		message := "waiting for deletion to complete"
+		if err == nil && len(existing.GetFinalizers()) > 0 {
+			message = fmt.Sprintf("waiting for finalizers: %s", strings.Join(existing.GetFinalizers(), ", "))
+		}
+		if message != last {
+			report(message)
+			last = message
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("gave up %s: %w", message, ctx.Err())
+		case <-time.After(deletionPollInterval):
+		}
+	}
+}
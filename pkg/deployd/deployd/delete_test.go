@@ -0,0 +1,140 @@
+package deployd
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nais/deploy/pkg/deployd/operation"
+	"github.com/nais/deploy/pkg/k8sutils"
+	"github.com/nais/deploy/pkg/pb"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	otrace "go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestDeletePreview(t *testing.T) {
+	labelled := configMap("labelled", "info")
+	labelled.SetLabels(map[string]string{k8sutils.PreviewLabel: "pr-1"})
+	otherPreview := configMap("other-preview", "info")
+	otherPreview.SetLabels(map[string]string{k8sutils.PreviewLabel: "pr-2"})
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		configMaps: "ConfigMapList",
+	}, labelled, otherPreview, configMap("unlabelled", "info"))
+
+	resources := make([]json.RawMessage, 0)
+	for _, name := range []string{"labelled", "other-preview", "unlabelled", "missing"} {
+		data, err := configMap(name, "").MarshalJSON()
+		assert.NoError(t, err)
+		resources = append(resources, data)
+	}
+	data, err := json.Marshal(resources)
+	assert.NoError(t, err)
+	kube, err := pb.KubernetesFromJSONResources(data)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	statusChan := make(chan *pb.DeploymentStatus, 16)
+	op := &operation.Operation{
+		Context: ctx,
+		Cancel:  cancel,
+		Logger:  log.WithField("test", t.Name()),
+		Request: &pb.DeploymentRequest{
+			ID:         "123",
+			Kubernetes: kube,
+			Preview:    "pr-1",
+			Delete:     true,
+		},
+		Trace:      otrace.SpanFromContext(ctx),
+		StatusChan: statusChan,
+	}
+
+	Delete(op, &configMapClient{dynamic: client})
+	close(statusChan)
+
+	var last *pb.DeploymentStatus
+	for last = range statusChan {
+	}
+	assert.Equal(t, pb.DeploymentState_success, last.GetState())
+
+	resourceInterface := client.Resource(configMaps).Namespace("aura")
+	_, err = resourceInterface.Get(context.Background(), "labelled", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err), "labelled resource should be deleted")
+
+	for _, name := range []string{"other-preview", "unlabelled"} {
+		_, err = resourceInterface.Get(context.Background(), name, metav1.GetOptions{})
+		assert.NoError(t, err, "%s should not be deleted", name)
+	}
+}
+
+func TestDeleteResource(t *testing.T) {
+	existing := configMap("existing", "info")
+	existing.SetUID("existing-uid")
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		configMaps: "ConfigMapList",
+	}, existing)
+
+	message, uid, err := deleteResource(context.Background(), &configMapClient{dynamic: client}, *configMap("existing", ""), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "deleted", message)
+	assert.Equal(t, types.UID("existing-uid"), uid)
+
+	message, uid, err = deleteResource(context.Background(), &configMapClient{dynamic: client}, *configMap("existing", ""), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "already deleted", message)
+	assert.Empty(t, uid)
+}
+
+func TestWaitForDeletion(t *testing.T) {
+	defer func(interval time.Duration) { deletionPollInterval = interval }(deletionPollInterval)
+	deletionPollInterval = time.Millisecond
+
+	finalized := configMap("finalized", "info")
+	finalized.SetUID("finalized-uid")
+	finalized.SetFinalizers([]string{"example.com/cleanup"})
+
+	t.Run("waits until finalizers are done", func(t *testing.T) {
+		client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			configMaps: "ConfigMapList",
+		}, finalized.DeepCopy())
+
+		messages := make([]string, 0)
+		err := waitForDeletion(context.Background(), &configMapClient{dynamic: client}, *configMap("finalized", ""), "finalized-uid", func(message string) {
+			messages = append(messages, message)
+			// Deleting the object in the fake client stands in for the finalizer completing.
+			_ = client.Resource(configMaps).Namespace("aura").Delete(context.Background(), "finalized", metav1.DeleteOptions{})
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"waiting for finalizers: example.com/cleanup"}, messages)
+	})
+
+	t.Run("gives up at the deadline", func(t *testing.T) {
+		client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			configMaps: "ConfigMapList",
+		}, finalized.DeepCopy())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		err := waitForDeletion(ctx, &configMapClient{dynamic: client}, *configMap("finalized", ""), "finalized-uid", func(string) {})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "example.com/cleanup")
+	})
+
+	t.Run("replaced resource counts as deleted", func(t *testing.T) {
+		client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			configMaps: "ConfigMapList",
+		}, finalized.DeepCopy())
+
+		err := waitForDeletion(context.Background(), &configMapClient{dynamic: client}, *configMap("finalized", ""), "old-uid", func(string) {})
+		assert.NoError(t, err)
+	})
+}
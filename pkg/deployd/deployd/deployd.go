@@ -1,9 +1,13 @@
 package deployd
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/nais/deploy/pkg/deployd/config"
 	"github.com/nais/deploy/pkg/deployd/kubeclient"
 	"github.com/nais/deploy/pkg/deployd/metrics"
 	"github.com/nais/deploy/pkg/deployd/operation"
@@ -16,9 +20,20 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	otrace "go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
 )
 
+// How long to wait for all resources to be restored when rolling back a failed deployment.
+// The deployment context has already expired at this point, so rollback gets its own deadline.
+const rollbackTimeout = 2 * time.Minute
+
+type rollbackTarget struct {
+	identifier k8sutils.Identifier
+	snapshot   *strategy.Snapshot
+}
+
 // Annotate a resource with the deployment correlation ID.
 func addCorrelationID(resource *unstructured.Unstructured, correlationID string) {
 	anno := resource.GetAnnotations()
@@ -29,11 +44,106 @@ func addCorrelationID(resource *unstructured.Unstructured, correlationID string)
 	resource.SetAnnotations(anno)
 }
 
-func Run(op *operation.Operation, client kubeclient.Interface) {
+// Whether a resource in the cluster was saved by this deployment, i.e. it is annotated with the deployment's correlation ID.
+func alreadyApplied(op *operation.Operation, resourceInterface dynamic.ResourceInterface, resource unstructured.Unstructured) bool {
+	existing, err := resourceInterface.Get(op.Context, resource.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return false
+	}
+	return existing.GetAnnotations()[nais_io_v1.DeploymentCorrelationIDAnnotation] == op.Request.GetID()
+}
+
+// Run every resource through a server-side dry run, so that a deployment is either
+// applied in its entirety or not at all. All validation errors are collected into a single error.
+func dryRun(op *operation.Operation, client kubeclient.Interface, cfg *config.Config, resources []unstructured.Unstructured) error {
+	_, span := telemetry.Tracer().Start(op.Context, "Server-side dry run", otrace.WithSpanKind(otrace.SpanKindClient))
+	defer span.End()
+
+	errs := make([]string, 0)
+
+	for _, resource := range resources {
+		identifier := k8sutils.ResourceIdentifier(resource)
+		strategyName := strategy.DeployStrategyName(resource, cfg.DeployStrategy)
+
+		var deployStrategy strategy.DeployStrategy
+		resourceInterface, err := client.ResourceInterface(&resource)
+		if err == nil {
+			deployStrategy, err = strategy.NewDryRunDeployStrategy(strategyName, resourceInterface)
+		}
+		if err == nil {
+			_, err = deployStrategy.Deploy(op.Context, resource, span)
+		}
+
+		if err != nil {
+			op.Logger.WithFields(log.Fields{
+				"name":      identifier.Name,
+				"namespace": identifier.Namespace,
+				"gvk":       identifier.GroupVersionKind,
+			}).Errorf("Dry run: %s", err)
+			errs = append(errs, fmt.Sprintf("%s: %s", identifier.String(), err))
+		}
+	}
+
+	if len(errs) > 0 {
+		err := fmt.Errorf("%s (total of %d errors)", strings.Join(errs, "\n"), len(errs))
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	span.SetStatus(codes.Ok, "All resources passed validation")
+
+	return nil
+}
+
+// Restore every resource touched by the deployment to its previous version, in reverse order of deployment.
+// Returns the number of resources that could not be restored.
+func rollback(op *operation.Operation, targets []rollbackTarget) int {
+	ctx, cancel := context.WithTimeout(context.Background(), rollbackTimeout)
+	defer cancel()
+
+	ctx, span := telemetry.Tracer().Start(otrace.ContextWithSpan(ctx, op.Trace), "Rollback", otrace.WithSpanKind(otrace.SpanKindClient))
+	defer span.End()
+
+	reason := "Deployment failed"
+	if op.Aborted() {
+		reason = "Deployment cancelled"
+	}
+	op.StatusChan <- pb.NewInProgressStatus(op.Request, "%s; rolling back %d resources to their previous version", reason, len(targets))
+
+	failed := 0
+	for i := len(targets) - 1; i >= 0; i-- {
+		target := targets[i]
+		err := target.snapshot.Restore(ctx)
+		switch {
+		case err != nil:
+			failed++
+			op.Logger.Errorf("Rollback of %s: %s", target.identifier.String(), err)
+			op.StatusChan <- pb.NewInProgressStatus(op.Request, "Failed to roll back %s: %s", target.identifier.String(), err)
+		case target.snapshot.Created():
+			op.StatusChan <- pb.NewInProgressStatus(op.Request, "Rolled back %s by deleting it", target.identifier.String())
+		default:
+			op.StatusChan <- pb.NewInProgressStatus(op.Request, "Rolled back %s to its previous version", target.identifier.String())
+		}
+	}
+
+	if failed > 0 {
+		span.SetStatus(codes.Error, fmt.Sprintf("%d of %d resources could not be rolled back", failed, len(targets)))
+	} else {
+		span.SetStatus(codes.Ok, "All resources rolled back")
+	}
+
+	return failed
+}
+
+func Run(op *operation.Operation, client kubeclient.Interface, cfg *config.Config, conditionWatches *strategy.ConditionWatches) {
 	op.Logger.Infof("Starting deployment")
 
 	failure := func(err error) {
 		op.Cancel()
+		if op.Aborted() {
+			op.StatusChan <- pb.NewCancelledStatus(op.Request)
+			return
+		}
 		op.StatusChan <- pb.NewFailureStatus(op.Request, err)
 	}
 
@@ -53,11 +163,120 @@ func Run(op *operation.Operation, client kubeclient.Interface) {
 		return
 	}
 
-	wait := sync.WaitGroup{}
+	for i := range resources {
+		addCorrelationID(&resources[i], op.Request.GetID())
+		if len(op.Request.GetDeploySet()) > 0 {
+			addDeploySetLabel(&resources[i], op.Request.GetDeploySet())
+		}
+	}
+
+	waves, err := groupWaves(resources)
+	if err != nil {
+		failure(err)
+		op.Trace.SetStatus(codes.Error, err.Error())
+		op.Trace.End()
+		return
+	}
+
+	// Resources in later waves may depend on resources created by earlier waves, such as a namespace or a CRD,
+	// so each wave is validated just before it is deployed. Nothing has been changed if the first wave fails.
+	err = dryRun(op, client, cfg, waves[0].resources)
+	if err != nil {
+		failure(err)
+		op.Trace.SetStatus(codes.Error, err.Error())
+		op.Trace.End()
+		return
+	}
+
+	if len(waves) == 1 {
+		op.StatusChan <- pb.NewInProgressStatus(op.Request, "All resources passed server-side validation")
+	}
+
 	errors := make(chan error, len(resources))
+	rollbackTargets := make([]rollbackTarget, 0, len(resources))
 
-	for _, resource := range resources {
-		addCorrelationID(&resource, op.Request.GetID())
+	go func() {
+		for i, wave := range waves {
+			if len(waves) > 1 {
+				if i > 0 {
+					err := dryRun(op, client, cfg, wave.resources)
+					if err != nil {
+						errors <- err
+						op.StatusChan <- pb.NewInProgressStatus(op.Request, "Wave %d failed server-side validation; skipping %d remaining waves", wave.number, len(waves)-i-1)
+						break
+					}
+				}
+				op.StatusChan <- pb.NewInProgressStatus(op.Request, "Wave %d passed server-side validation", wave.number)
+				op.StatusChan <- pb.NewInProgressStatus(op.Request, "Deploying wave %d (%d of %d) with %d resources", wave.number, i+1, len(waves), len(wave.resources))
+			}
+
+			targets := deployWave(op, client, cfg, conditionWatches, wave, errors)
+			rollbackTargets = append(rollbackTargets, targets...)
+
+			if op.Aborted() {
+				break
+			}
+
+			if len(errors) > 0 {
+				if remaining := len(waves) - i - 1; remaining > 0 {
+					op.StatusChan <- pb.NewInProgressStatus(op.Request, "Wave %d failed; skipping %d remaining waves", wave.number, remaining)
+				}
+				break
+			}
+		}
+
+		var pruneErr error
+		if len(errors) == 0 && !op.Aborted() && op.Request.GetPrune() && len(op.Request.GetDeploySet()) > 0 {
+			pruneErr = pruneDeploySet(op, client, resources)
+		}
+
+		op.Logger.Debugf("Finished monitoring all resources")
+		op.Cancel()
+
+		errCount := len(errors)
+		if op.Aborted() {
+			op.Logger.Infof("Deployment cancelled")
+			if len(rollbackTargets) > 0 {
+				failed := rollback(op, rollbackTargets)
+				if failed > 0 {
+					op.StatusChan <- pb.NewInProgressStatus(op.Request, "Rollback failed for %d of %d resources", failed, len(rollbackTargets))
+				}
+			}
+			op.StatusChan <- pb.NewCancelledStatus(op.Request)
+			op.Trace.SetStatus(codes.Error, "Deployment cancelled")
+		} else if errCount > 0 {
+			err := <-errors
+			close(errors)
+			aggregateError := fmt.Errorf("%s (total of %d errors)", err, errCount)
+			if len(rollbackTargets) > 0 {
+				failed := rollback(op, rollbackTargets)
+				if failed > 0 {
+					aggregateError = fmt.Errorf("%w; rollback failed for %d of %d resources", aggregateError, failed, len(rollbackTargets))
+				} else {
+					aggregateError = fmt.Errorf("%w; all changes have been rolled back", aggregateError)
+				}
+			}
+			op.StatusChan <- pb.NewFailureStatus(op.Request, aggregateError)
+			op.Trace.SetStatus(codes.Error, aggregateError.Error())
+		} else if pruneErr != nil {
+			op.StatusChan <- pb.NewFailureStatus(op.Request, pruneErr)
+			op.Trace.SetStatus(codes.Error, pruneErr.Error())
+		} else {
+			op.StatusChan <- pb.NewSuccessStatus(op.Request)
+			op.Trace.SetStatus(codes.Ok, "All resources rolled out successfully")
+		}
+
+		op.Trace.End()
+	}()
+}
+
+// Deploy all resources in a single wave, and block until every one of them has finished rolling out.
+// Errors are sent on the errors channel. Returns the resources that can be rolled back.
+func deployWave(op *operation.Operation, client kubeclient.Interface, cfg *config.Config, conditionWatches *strategy.ConditionWatches, wave wave, errors chan<- error) []rollbackTarget {
+	wait := sync.WaitGroup{}
+	targets := make([]rollbackTarget, 0, len(wave.resources))
+
+	for _, resource := range wave.resources {
 		identifier := k8sutils.ResourceIdentifier(resource)
 
 		logger := op.Logger.WithFields(log.Fields{
@@ -88,12 +307,38 @@ func Run(op *operation.Operation, client kubeclient.Interface) {
 			},
 		)
 
+		strategyName := strategy.DeployStrategyName(resource, cfg.DeployStrategy)
+		span.SetAttributes(attribute.KeyValue{
+			Key:   "deploy.strategy",
+			Value: attribute.StringValue(strategyName),
+		})
+
+		var snapshot *strategy.Snapshot
+		if op.Request.GetRollback() {
+			snapshot = &strategy.Snapshot{}
+		}
+
+		// When resuming a deployment, resources that were saved before deployd restarted are only watched.
+		resumed := false
+
+		var deployStrategy strategy.DeployStrategy
 		resourceInterface, err := client.ResourceInterface(&resource)
-		if err == nil {
-			_, err = strategy.NewDeployStrategy(resourceInterface).Deploy(op.Context, resource, span)
+		if err == nil && op.Request.GetResume() {
+			resumed = alreadyApplied(op, resourceInterface, resource)
+		}
+		if err == nil && !resumed {
+			deployStrategy, err = strategy.NewDeployStrategy(strategyName, resourceInterface, snapshot)
+		}
+		if err == nil && !resumed {
+			_, err = deployStrategy.Deploy(op.Context, resource, span)
 		}
 
 		if err != nil {
+			if op.Aborted() {
+				span.SetStatus(codes.Error, "Deployment cancelled")
+				span.End()
+				break
+			}
 			err = fmt.Errorf("%s: %s", identifier.String(), err)
 			span.SetStatus(codes.Error, err.Error())
 			span.End()
@@ -102,19 +347,40 @@ func Run(op *operation.Operation, client kubeclient.Interface) {
 			break
 		}
 
-		span.AddEvent("Resource saved to Kubernetes")
+		if resumed {
+			span.AddEvent("Resource already saved to Kubernetes by this deployment")
+		} else {
+			span.AddEvent("Resource saved to Kubernetes")
+		}
+
+		// The previous version of a resumed resource is unknown, so it cannot be rolled back.
+		if snapshot != nil && !resumed {
+			targets = append(targets, rollbackTarget{identifier: identifier, snapshot: snapshot})
+		}
+
+		if op.Aborted() {
+			span.SetStatus(codes.Error, "Deployment cancelled")
+			span.End()
+			break
+		}
 
 		metrics.KubernetesResources(op.Request.GetTeam(), identifier.Kind, identifier.Name).Inc()
 
-		op.StatusChan <- pb.NewInProgressStatus(op.Request, "Successfully applied %s", identifier.String())
+		if resumed {
+			op.StatusChan <- pb.NewInProgressStatus(op.Request, "Resuming rollout of %s, which was already applied", identifier.String())
+		} else {
+			op.StatusChan <- pb.NewInProgressStatus(op.Request, "Successfully applied %s", identifier.String())
+		}
 		wait.Add(1)
 
 		go func(logger *log.Entry, resource unstructured.Unstructured) {
 			deadline, _ := op.Context.Deadline()
 			op.Logger.Debugf("Monitoring rollout status of '%s/%s' in namespace '%s', deadline %s", identifier.GroupVersionKind, identifier.Name, identifier.Namespace, deadline)
-			strat := strategy.NewWatchStrategy(identifier.GroupVersionKind, client)
+			strat := strategy.NewWatchStrategy(identifier.GroupVersionKind, client, conditionWatches)
 			status := strat.Watch(op, resource, span)
-			if status != nil {
+			if op.Aborted() {
+				span.SetStatus(codes.Error, "Deployment cancelled")
+			} else if status != nil {
 				span.AddEvent(status.Message)
 				if status.GetState().IsError() {
 					span.SetStatus(codes.Error, status.Message)
@@ -138,24 +404,8 @@ func Run(op *operation.Operation, client kubeclient.Interface) {
 
 	op.StatusChan <- pb.NewInProgressStatus(op.Request, "All resources saved to Kubernetes; waiting for completion")
 
-	go func() {
-		op.Logger.Debugf("Waiting for resources to be successfully rolled out")
-		wait.Wait()
-		op.Logger.Debugf("Finished monitoring all resources")
-		op.Cancel()
-
-		errCount := len(errors)
-		if errCount > 0 {
-			err := <-errors
-			close(errors)
-			aggregateError := fmt.Errorf("%s (total of %d errors)", err, errCount)
-			op.StatusChan <- pb.NewFailureStatus(op.Request, aggregateError)
-			op.Trace.SetStatus(codes.Error, aggregateError.Error())
-		} else {
-			op.StatusChan <- pb.NewSuccessStatus(op.Request)
-			op.Trace.SetStatus(codes.Ok, "All resources rolled out successfully")
-		}
+	op.Logger.Debugf("Waiting for resources to be successfully rolled out")
+	wait.Wait()
 
-		op.Trace.End()
-	}()
+	return targets
 }
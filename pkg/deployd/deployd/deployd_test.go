@@ -20,11 +20,13 @@ import (
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	rbac_v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 
+	"github.com/nais/deploy/pkg/deployd/config"
 	"github.com/nais/deploy/pkg/deployd/deployd"
 	"github.com/nais/deploy/pkg/deployd/kubeclient"
 	"github.com/nais/deploy/pkg/deployd/operation"
@@ -39,6 +41,7 @@ type testSpec struct {
 	timeout           time.Duration        // time to allow for deploy to reach end state
 	endStatus         *pb.DeploymentStatus // which end state we expect
 	deployedResources []client.Object      // list of Kubernetes resources expected to be applied to the cluster - only checks name and namespace
+	absentResources   []client.Object      // list of Kubernetes resources expected to NOT exist in the cluster after the deploy has finished
 	processing        processCallback      // processing that happens in a coroutine together with deployd.Run(). Requires all resources in `deployedResources` to exist.
 }
 
@@ -173,6 +176,26 @@ var tests = []testSpec{
 		},
 		deployedResources: nil,
 	},
+
+	// Resources are only applied if all of them pass the server-side dry run
+	{
+		fixture: "testdata/dryrun-partialfailure.json",
+		timeout: 2 * time.Second,
+		endStatus: &pb.DeploymentStatus{
+			State: pb.DeploymentState_failure,
+			Message: "nais.io/v1alpha1, Kind=Application, Namespace=aura, Name=myapplication-dryrun-one: creating resource: strict decoding error:\n| ⚠️ unknown field \"spec.unknownField\"\n| The field might be misspelled, incorrectly indented, or unsupported. Fields are case sensitive.\n| Please verify your resource against the reference documentation at https://doc.nais.io/workloads/application/reference/application-spec/\n" +
+				"nais.io/v1alpha1, Kind=Application, Namespace=aura, Name=myapplication-dryrun-two: creating resource: strict decoding error:\n| ⚠️ unknown field \"spec.otherField\"\n| The field might be misspelled, incorrectly indented, or unsupported. Fields are case sensitive.\n| Please verify your resource against the reference documentation at https://doc.nais.io/workloads/application/reference/application-spec/ (total of 2 errors)",
+		},
+		deployedResources: nil,
+		absentResources: []client.Object{
+			&v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "dryrun-valid",
+					Namespace: "aura",
+				},
+			},
+		},
+	},
 }
 
 type testRig struct {
@@ -476,11 +499,16 @@ func subTest(t *testing.T, rig *testRig, test testSpec, team string) {
 	if err != nil {
 		return
 	}
-	deployd.Run(op, teamClient)
+	deployd.Run(op, teamClient, &config.Config{}, nil)
 
 	err = waitFinish(rig.statusChan, test.endStatus, test.fixture)
 	assert.NoError(t, err)
 
+	for _, resource := range test.absentResources {
+		err = resourceExists(ctx, rig, resource)
+		assert.True(t, errors.IsNotFound(err), "resource %s should not exist in cluster", resource.GetName())
+	}
+
 	wg.Wait()
 }
 
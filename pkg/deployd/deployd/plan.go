@@ -0,0 +1,76 @@
+package deployd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nais/deploy/pkg/deployd/config"
+	"github.com/nais/deploy/pkg/deployd/kubeclient"
+	"github.com/nais/deploy/pkg/deployd/strategy"
+	"github.com/nais/deploy/pkg/k8sutils"
+	"github.com/nais/deploy/pkg/pb"
+	otrace "go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Plan compares the resources of a plan request with the objects in the cluster, without changing anything.
+// Every resource is run through a server-side dry run, so that defaults and changes made by admission webhooks
+// are part of the comparison. Resources that the cluster would reject are returned as errors.
+func Plan(ctx context.Context, client kubeclient.Interface, cfg *config.Config, request *pb.DeploymentRequest) *pb.DeploymentPlan {
+	plan := &pb.DeploymentPlan{
+		ID:      request.GetID(),
+		Cluster: cfg.Cluster,
+	}
+
+	resources, err := k8sutils.ResourcesFromDeploymentRequest(request)
+	if err != nil {
+		plan.Errors = append(plan.Errors, &pb.ResourceError{Message: err.Error()})
+		return plan
+	}
+
+	for _, resource := range resources {
+		if len(request.GetDeploySet()) > 0 {
+			addDeploySetLabel(&resource, request.GetDeploySet())
+		}
+
+		diff, err := planResource(ctx, client, cfg, resource)
+		if err != nil {
+			plan.Errors = append(plan.Errors, &pb.ResourceError{
+				Resource: k8sutils.ResourceIdentifier(resource).KubernetesResource(),
+				Message:  err.Error(),
+			})
+			continue
+		}
+		plan.Resources = append(plan.Resources, diff)
+	}
+
+	return plan
+}
+
+func planResource(ctx context.Context, client kubeclient.Interface, cfg *config.Config, resource unstructured.Unstructured) (*pb.ResourceDiff, error) {
+	resourceInterface, err := client.ResourceInterface(&resource)
+	if err != nil {
+		return nil, err
+	}
+
+	live, err := resourceInterface.Get(ctx, resource.GetName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		live = nil
+	} else if err != nil {
+		return nil, fmt.Errorf("get existing resource: %w", err)
+	}
+
+	deployStrategy, err := strategy.NewDryRunDeployStrategy(strategy.DeployStrategyName(resource, cfg.DeployStrategy), resourceInterface)
+	if err != nil {
+		return nil, err
+	}
+
+	predicted, err := deployStrategy.Deploy(ctx, resource, otrace.SpanFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return k8sutils.DiffLive(live, *predicted), nil
+}
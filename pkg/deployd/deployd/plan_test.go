@@ -0,0 +1,104 @@
+package deployd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/nais/deploy/pkg/deployd/config"
+	"github.com/nais/deploy/pkg/deployd/kubeclient"
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var configMaps = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+// configMapClient serves config maps from a fake dynamic client.
+type configMapClient struct {
+	dynamic *dynamicfake.FakeDynamicClient
+}
+
+var _ kubeclient.Interface = &configMapClient{}
+
+func (c *configMapClient) Kubernetes() kubernetes.Interface {
+	return nil
+}
+
+func (c *configMapClient) ResourceInterface(resource *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	return c.dynamic.Resource(configMaps).Namespace(resource.GetNamespace()), nil
+}
+
+func (c *configMapClient) Impersonate(team string) (kubeclient.Interface, error) {
+	return c, nil
+}
+
+func configMap(name, level string) *unstructured.Unstructured {
+	resource := &unstructured.Unstructured{Object: map[string]any{
+		"data": map[string]any{"level": level},
+	}}
+	resource.SetAPIVersion("v1")
+	resource.SetKind("ConfigMap")
+	resource.SetNamespace("aura")
+	resource.SetName(name)
+	return resource
+}
+
+func TestPlan(t *testing.T) {
+	live := configMap("unchanged", "info")
+	live.SetResourceVersion("42")
+	live.SetUID("1234")
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		configMaps: "ConfigMapList",
+	}, live, configMap("changed", "info"))
+	client.PrependReactor("create", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		created := action.(k8stesting.CreateAction).GetObject().(*unstructured.Unstructured)
+		if created.GetName() == "rejected" {
+			return true, nil, fmt.Errorf("admission webhook denied the request")
+		}
+		return false, nil, nil
+	})
+
+	resources := make([]json.RawMessage, 0)
+	for _, resource := range []*unstructured.Unstructured{
+		configMap("unchanged", "info"),
+		configMap("changed", "debug"),
+		configMap("added", "info"),
+		configMap("rejected", "info"),
+	} {
+		data, err := resource.MarshalJSON()
+		assert.NoError(t, err)
+		resources = append(resources, data)
+	}
+	data, err := json.Marshal(resources)
+	assert.NoError(t, err)
+	kube, err := pb.KubernetesFromJSONResources(data)
+	assert.NoError(t, err)
+
+	plan := Plan(context.Background(), &configMapClient{dynamic: client}, &config.Config{Cluster: "dev"}, &pb.DeploymentRequest{
+		ID:         "123",
+		Kubernetes: kube,
+	})
+
+	assert.Equal(t, "123", plan.GetID())
+	assert.Equal(t, "dev", plan.GetCluster())
+	assert.Len(t, plan.GetResources(), 3)
+	assert.Equal(t, pb.ResourceChange_unchanged, plan.GetResources()[0].GetChange())
+	assert.Equal(t, pb.ResourceChange_changed, plan.GetResources()[1].GetChange())
+	assert.Equal(t, "data.level", plan.GetResources()[1].GetFields()[0].GetPath())
+	assert.Equal(t, "debug", plan.GetResources()[1].GetFields()[0].GetNew())
+	assert.Equal(t, pb.ResourceChange_added, plan.GetResources()[2].GetChange())
+	assert.Equal(t, "added", plan.GetResources()[2].GetResource().GetName())
+
+	assert.Len(t, plan.GetErrors(), 1)
+	assert.Equal(t, "rejected", plan.GetErrors()[0].GetResource().GetName())
+	assert.Contains(t, plan.GetErrors()[0].GetMessage(), "admission webhook denied the request")
+}
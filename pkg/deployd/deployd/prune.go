@@ -0,0 +1,174 @@
+package deployd
+
+import (
+	"fmt"
+
+	"github.com/nais/deploy/pkg/deployd/kubeclient"
+	"github.com/nais/deploy/pkg/deployd/operation"
+	"github.com/nais/deploy/pkg/k8sutils"
+	"github.com/nais/deploy/pkg/pb"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// Label a resource as part of the deploy set it is deployed with.
+func addDeploySetLabel(resource *unstructured.Unstructured, deploySet string) {
+	labels := resource.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[k8sutils.DeploySetLabel] = deploySet
+	resource.SetLabels(labels)
+}
+
+// Kinds that controllers create for other resources, copying their labels without setting an owner reference.
+// The endpoints controller, for instance, copies the labels of every Service to its Endpoints.
+// These are never pruned, as they would otherwise be deleted by every deployment of the deploy set.
+var unprunableKinds = map[schema.GroupKind]bool{
+	{Group: "", Kind: "Endpoints"}:                     true,
+	{Group: "discovery.k8s.io", Kind: "EndpointSlice"}: true,
+	{Group: "", Kind: "Event"}:                         true,
+	{Group: "events.k8s.io", Kind: "Event"}:            true,
+}
+
+// Return every kind of namespaced resource that can be listed and deleted in the cluster.
+func prunableKinds(client kubeclient.Interface) ([]schema.GroupVersionKind, error) {
+	resourceLists, err := client.Kubernetes().Discovery().ServerPreferredNamespacedResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+
+	kinds := make([]schema.GroupVersionKind, 0)
+	for _, resourceList := range resourceLists {
+		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range resourceList.APIResources {
+			verbs := discovery.SupportsAllVerbs{Verbs: []string{"list", "delete"}}
+			if !verbs.Match(resourceList.GroupVersion, &resource) {
+				continue
+			}
+			kinds = append(kinds, groupVersion.WithKind(resource.Kind))
+		}
+	}
+
+	return kinds, nil
+}
+
+// Delete resources that are labelled with the deploy set of the deployment, but are not part of it.
+// Only the namespaces the deployment touches are searched, and only resources of the given kinds are considered.
+// Resources owned by other resources are left alone, as their owner may have copied the label to them,
+// and so are resources of kinds that controllers create with copied labels.
+// Returns the number of resources that could not be pruned.
+func prune(op *operation.Operation, client kubeclient.Interface, kinds []schema.GroupVersionKind, resources []unstructured.Unstructured) int {
+	deploySet := op.Request.GetDeploySet()
+	selector := metav1.FormatLabelSelector(&metav1.LabelSelector{
+		MatchLabels: map[string]string{k8sutils.DeploySetLabel: deploySet},
+	})
+
+	type key struct {
+		groupKind schema.GroupKind
+		namespace string
+		name      string
+	}
+
+	deployed := make(map[key]bool)
+	namespaces := make(map[string]bool)
+	for _, resource := range resources {
+		namespace := resource.GetNamespace()
+		if len(namespace) == 0 {
+			namespace = op.Request.GetTeam()
+		}
+		deployed[key{resource.GroupVersionKind().GroupKind(), namespace, resource.GetName()}] = true
+		namespaces[namespace] = true
+	}
+
+	failed := 0
+	for namespace := range namespaces {
+		for _, kind := range kinds {
+			if unprunableKinds[kind.GroupKind()] {
+				continue
+			}
+
+			list := &unstructured.Unstructured{}
+			list.SetGroupVersionKind(kind)
+			list.SetNamespace(namespace)
+
+			resourceInterface, err := client.ResourceInterface(list)
+			if err != nil {
+				op.Logger.Warnf("Prune %s: %s", kind, err)
+				continue
+			}
+
+			existing, err := resourceInterface.List(op.Context, metav1.ListOptions{LabelSelector: selector})
+			if errors.IsForbidden(err) || errors.IsNotFound(err) || errors.IsMethodNotSupported(err) {
+				continue
+			} else if err != nil {
+				failed++
+				op.Logger.Errorf("List %s in namespace %s for pruning: %s", kind, namespace, err)
+				op.StatusChan <- pb.NewInProgressStatus(op.Request, "Failed to find %s resources to prune in namespace %s: %s", kind.Kind, namespace, err)
+				continue
+			}
+
+			for _, item := range existing.Items {
+				if deployed[key{item.GroupVersionKind().GroupKind(), item.GetNamespace(), item.GetName()}] {
+					continue
+				}
+				if len(item.GetOwnerReferences()) > 0 || item.GetDeletionTimestamp() != nil {
+					continue
+				}
+
+				identifier := k8sutils.ResourceIdentifier(item)
+				logger := op.Logger.WithFields(log.Fields{
+					"name":      identifier.Name,
+					"namespace": identifier.Namespace,
+					"gvk":       identifier.GroupVersionKind,
+				})
+
+				uid := item.GetUID()
+				propagation := metav1.DeletePropagationBackground
+				err = resourceInterface.Delete(op.Context, item.GetName(), metav1.DeleteOptions{
+					Preconditions:     &metav1.Preconditions{UID: &uid},
+					PropagationPolicy: &propagation,
+				})
+				if errors.IsNotFound(err) {
+					continue
+				} else if err != nil {
+					failed++
+					logger.Errorf("Prune: %s", err)
+					op.StatusChan <- pb.NewInProgressStatus(op.Request, "Failed to prune %s: %s", identifier.String(), err)
+					continue
+				}
+
+				logger.Infof("Pruned resource removed from deploy set %q", deploySet)
+				st := pb.NewInProgressStatus(op.Request, "Pruned %s; it is no longer part of deploy set %q", identifier.String(), deploySet)
+				st.Pruned = identifier.KubernetesResource()
+				op.StatusChan <- st
+			}
+		}
+	}
+
+	return failed
+}
+
+// Prune the deploy set of a successful deployment, and return an error if any resource could not be pruned.
+func pruneDeploySet(op *operation.Operation, client kubeclient.Interface, resources []unstructured.Unstructured) error {
+	op.StatusChan <- pb.NewInProgressStatus(op.Request, "Pruning resources removed from deploy set %q", op.Request.GetDeploySet())
+
+	kinds, err := prunableKinds(client)
+	if err != nil {
+		return fmt.Errorf("all resources deployed, but resources removed from the deploy set could not be found: %w", err)
+	}
+
+	failed := prune(op, client, kinds, resources)
+	if failed > 0 {
+		return fmt.Errorf("all resources deployed, but pruning resources removed from the deploy set failed %d times", failed)
+	}
+
+	return nil
+}
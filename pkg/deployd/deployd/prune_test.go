@@ -0,0 +1,113 @@
+package deployd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nais/deploy/pkg/deployd/operation"
+	"github.com/nais/deploy/pkg/k8sutils"
+	"github.com/nais/deploy/pkg/pb"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+var endpoints = schema.GroupVersionResource{Version: "v1", Resource: "endpoints"}
+
+// kindClient serves ConfigMaps and Endpoints from a fake dynamic client.
+type kindClient struct {
+	configMapClient
+}
+
+func (c *kindClient) ResourceInterface(resource *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	if resource.GetKind() == "Endpoints" {
+		return c.dynamic.Resource(endpoints).Namespace(resource.GetNamespace()), nil
+	}
+	return c.configMapClient.ResourceInterface(resource)
+}
+
+func deploySetConfigMap(name, deploySet string) *unstructured.Unstructured {
+	resource := configMap(name, "info")
+	if len(deploySet) > 0 {
+		resource.SetLabels(map[string]string{k8sutils.DeploySetLabel: deploySet})
+	}
+	return resource
+}
+
+func TestPrune(t *testing.T) {
+	owned := deploySetConfigMap("owned", "myapp")
+	owned.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "nais.io/v1alpha1", Kind: "Application", Name: "myapp", UID: "1234"}})
+
+	// The endpoints controller copies the labels of a Service to its Endpoints, without an owner reference.
+	copied := &unstructured.Unstructured{}
+	copied.SetAPIVersion("v1")
+	copied.SetKind("Endpoints")
+	copied.SetNamespace("aura")
+	copied.SetName("myapp")
+	copied.SetLabels(map[string]string{k8sutils.DeploySetLabel: "myapp"})
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		configMaps: "ConfigMapList",
+		endpoints:  "EndpointsList",
+	},
+		deploySetConfigMap("kept", "myapp"),
+		deploySetConfigMap("removed", "myapp"),
+		deploySetConfigMap("other-set", "otherapp"),
+		deploySetConfigMap("unlabelled", ""),
+		owned,
+		copied,
+	)
+
+	statusChan := make(chan *pb.DeploymentStatus, 16)
+	op := &operation.Operation{
+		Context: context.Background(),
+		Logger:  log.WithField("test", t.Name()),
+		Request: &pb.DeploymentRequest{
+			ID:        "123",
+			Team:      "aura",
+			DeploySet: "myapp",
+			Prune:     true,
+		},
+		StatusChan: statusChan,
+	}
+
+	kinds := []schema.GroupVersionKind{{Version: "v1", Kind: "ConfigMap"}, {Version: "v1", Kind: "Endpoints"}}
+	failed := prune(op, &kindClient{configMapClient{dynamic: client}}, kinds, []unstructured.Unstructured{*deploySetConfigMap("kept", "myapp")})
+	close(statusChan)
+	assert.Equal(t, 0, failed)
+
+	pruned := make([]string, 0)
+	for st := range statusChan {
+		if st.GetPruned() != nil {
+			pruned = append(pruned, st.GetPruned().GetName())
+		}
+	}
+	assert.Equal(t, []string{"removed"}, pruned)
+
+	resourceInterface := client.Resource(configMaps).Namespace("aura")
+	_, err := resourceInterface.Get(context.Background(), "removed", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err), "resource removed from deploy set should be pruned")
+
+	for _, name := range []string{"kept", "other-set", "unlabelled", "owned"} {
+		_, err = resourceInterface.Get(context.Background(), name, metav1.GetOptions{})
+		assert.NoError(t, err, "%s should not be pruned", name)
+	}
+
+	_, err = client.Resource(endpoints).Namespace("aura").Get(context.Background(), "myapp", metav1.GetOptions{})
+	assert.NoError(t, err, "endpoints with labels copied from a service should not be pruned")
+}
+
+func TestAddDeploySetLabel(t *testing.T) {
+	resource := configMap("foo", "info")
+	resource.SetLabels(map[string]string{"team": "aura"})
+
+	addDeploySetLabel(resource, "myapp")
+
+	assert.Equal(t, map[string]string{"team": "aura", k8sutils.DeploySetLabel: "myapp"}, resource.GetLabels())
+}
@@ -0,0 +1,244 @@
+package deployd
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/nais/deploy/pkg/deployd/operation"
+	"github.com/nais/deploy/pkg/k8sutils"
+	"github.com/nais/deploy/pkg/pb"
+	log "github.com/sirupsen/logrus"
+)
+
+// Scheduler serializes deployments that touch the same resources, so that two deployments
+// never roll out the same resource at the same time. Deployments of unrelated resources run concurrently.
+//
+// When a deployment touches resources that are already being deployed, its concurrency policy decides
+// whether it waits in line, cancels the other deployments, or is rejected.
+//
+// Status updates for queued, rejected and cancelled deployments are returned to the caller instead of being
+// sent on the status channel, as the caller is usually the only reader of that channel.
+type Scheduler struct {
+	lock    sync.Mutex
+	running []*job
+	queue   []*job
+	deploy  func(req *pb.DeploymentRequest)
+}
+
+type job struct {
+	request   *pb.DeploymentRequest
+	resources map[k8sutils.Identifier]bool
+	op        *operation.Operation
+	// Cancelled before the operation was started.
+	aborted bool
+	// Number of deployments this job is waiting for, as last reported.
+	position int
+}
+
+// NewScheduler returns a scheduler that starts deployments by calling deploy in a new goroutine.
+func NewScheduler(deploy func(req *pb.DeploymentRequest)) *Scheduler {
+	return &Scheduler{
+		deploy: deploy,
+	}
+}
+
+func newJob(req *pb.DeploymentRequest) *job {
+	// Requests with invalid resources conflict with nothing, and fail when they are run.
+	resources, _ := k8sutils.ResourcesFromDeploymentRequest(req)
+	identifiers := make(map[k8sutils.Identifier]bool)
+	for _, identifier := range k8sutils.Identifiers(resources) {
+		identifiers[identifier] = true
+	}
+	return &job{
+		request:   req,
+		resources: identifiers,
+	}
+}
+
+func (j *job) conflicts(other *job) bool {
+	for identifier := range j.resources {
+		if other.resources[identifier] {
+			return true
+		}
+	}
+	return false
+}
+
+func (j *job) abort() {
+	if j.op == nil {
+		j.aborted = true
+		return
+	}
+	j.op.Abort()
+}
+
+// Submit starts a deployment, or applies its concurrency policy if any of its resources are already being deployed.
+// Deployments that are already running or queued are ignored, as hookd may send a request again after losing the connection.
+// Returns the status updates that must be reported to hookd.
+func (s *Scheduler) Submit(req *pb.DeploymentRequest) []*pb.DeploymentStatus {
+	j := newJob(req)
+	logger := log.WithFields(req.LogFields())
+	statuses := make([]*pb.DeploymentStatus, 0)
+
+	s.lock.Lock()
+
+	conflicts := s.conflicting(j, s.running, s.queue)
+
+	switch {
+	case s.find(req.GetID()) != nil:
+		logger.Warnf("Ignoring deployment request; deployment is already running")
+
+	case len(conflicts) == 0:
+		s.start(j)
+
+	case req.GetConcurrency() == pb.ConcurrencyPolicy_reject:
+		logger.Warnf("Rejecting deployment; resources are already being deployed by %d other deployments", len(conflicts))
+		statuses = append(statuses, pb.NewErrorStatus(req, fmt.Errorf("resources are already being deployed by deployment %s", conflicts[0].request.GetID())))
+
+	case req.GetConcurrency() == pb.ConcurrencyPolicy_supersede:
+		for _, other := range conflicts {
+			log.WithFields(other.request.LogFields()).Infof("Deployment superseded by %s", req.GetID())
+			statuses = append(statuses, s.cancel(other)...)
+		}
+		s.queue = append(s.queue, j)
+		statuses = append(statuses, s.reschedule()...)
+
+	default:
+		s.queue = append(s.queue, j)
+		statuses = append(statuses, s.reschedule()...)
+	}
+
+	s.lock.Unlock()
+
+	return statuses
+}
+
+// Cancel stops a running deployment, or removes it from the queue.
+// Returns the status updates that must be reported to hookd.
+func (s *Scheduler) Cancel(req *pb.DeploymentRequest) []*pb.DeploymentStatus {
+	logger := log.WithFields(req.LogFields())
+	statuses := make([]*pb.DeploymentStatus, 0)
+
+	s.lock.Lock()
+
+	j := s.find(req.GetID())
+	if j == nil {
+		logger.Warnf("Received cancel request for deployment that is not running")
+	} else {
+		logger.Infof("Cancelling deployment")
+		statuses = append(statuses, s.cancel(j)...)
+		statuses = append(statuses, s.reschedule()...)
+	}
+
+	s.lock.Unlock()
+
+	return statuses
+}
+
+// Started registers the operation of a running deployment, so that it can be cancelled.
+func (s *Scheduler) Started(op *operation.Operation) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, j := range s.running {
+		if j.request.GetID() == op.Request.GetID() {
+			j.op = op
+			if j.aborted {
+				op.Abort()
+			}
+			return
+		}
+	}
+}
+
+// Finished must be called with every final deployment status.
+// The deployment's resources are released, and any deployments waiting for them are started.
+// Returns the status updates that must be reported to hookd.
+func (s *Scheduler) Finished(status *pb.DeploymentStatus) []*pb.DeploymentStatus {
+	id := status.GetRequest().GetID()
+	statuses := make([]*pb.DeploymentStatus, 0)
+
+	s.lock.Lock()
+
+	for i, j := range s.running {
+		if j.request.GetID() == id {
+			s.running = append(s.running[:i], s.running[i+1:]...)
+			statuses = append(statuses, s.reschedule()...)
+			break
+		}
+	}
+
+	s.lock.Unlock()
+
+	return statuses
+}
+
+func (s *Scheduler) find(id string) *job {
+	for _, j := range s.running {
+		if j.request.GetID() == id {
+			return j
+		}
+	}
+	for _, j := range s.queue {
+		if j.request.GetID() == id {
+			return j
+		}
+	}
+	return nil
+}
+
+// Returns all jobs in the given lists whose resources overlap with this job.
+func (s *Scheduler) conflicting(j *job, lists ...[]*job) []*job {
+	conflicts := make([]*job, 0)
+	for _, list := range lists {
+		for _, other := range list {
+			if other != j && j.conflicts(other) {
+				conflicts = append(conflicts, other)
+			}
+		}
+	}
+	return conflicts
+}
+
+func (s *Scheduler) start(j *job) {
+	log.WithFields(j.request.LogFields()).Debugf("Starting deployment")
+	s.running = append(s.running, j)
+	go s.deploy(j.request)
+}
+
+// Cancel a job. Running jobs are aborted and release their resources once they report their final status.
+// Queued jobs are removed from the queue immediately.
+func (s *Scheduler) cancel(j *job) []*pb.DeploymentStatus {
+	for i, queued := range s.queue {
+		if queued == j {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			return []*pb.DeploymentStatus{pb.NewCancelledStatus(j.request)}
+		}
+	}
+	j.abort()
+	return nil
+}
+
+// Start every queued job that no longer conflicts with a running job or a job ahead of it in the queue.
+// Jobs that still have to wait are notified whenever their position in the queue changes.
+func (s *Scheduler) reschedule() []*pb.DeploymentStatus {
+	statuses := make([]*pb.DeploymentStatus, 0)
+	waiting := make([]*job, 0, len(s.queue))
+
+	for _, j := range s.queue {
+		position := len(s.conflicting(j, s.running, waiting))
+		if position == 0 {
+			s.start(j)
+			continue
+		}
+		if position != j.position {
+			j.position = position
+			statuses = append(statuses, pb.NewQueuePositionStatus(j.request, position))
+		}
+		waiting = append(waiting, j)
+	}
+
+	s.queue = waiting
+
+	return statuses
+}
@@ -0,0 +1,211 @@
+package deployd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nais/deploy/pkg/deployd/operation"
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+// Create a deployment request for config maps with the given names.
+func schedulerRequest(t *testing.T, id string, policy pb.ConcurrencyPolicy, names ...string) *pb.DeploymentRequest {
+	resources := make([]string, len(names))
+	for i, name := range names {
+		resources[i] = fmt.Sprintf(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":%q,"namespace":"aura"}}`, name)
+	}
+	kube, err := pb.KubernetesFromJSONResources([]byte("[" + strings.Join(resources, ",") + "]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &pb.DeploymentRequest{
+		ID:          id,
+		Kubernetes:  kube,
+		Concurrency: policy,
+	}
+}
+
+type schedulerTest struct {
+	scheduler *Scheduler
+	started   chan string
+	statuses  chan *pb.DeploymentStatus
+}
+
+func newSchedulerTest() *schedulerTest {
+	test := &schedulerTest{
+		started:  make(chan string, 16),
+		statuses: make(chan *pb.DeploymentStatus, 16),
+	}
+	test.scheduler = NewScheduler(func(req *pb.DeploymentRequest) {
+		test.started <- req.GetID()
+	})
+	return test
+}
+
+// Call the scheduler like the deployd main loop does, collecting the returned status updates.
+func (test *schedulerTest) submit(req *pb.DeploymentRequest) {
+	test.collect(test.scheduler.Submit(req))
+}
+
+func (test *schedulerTest) cancel(req *pb.DeploymentRequest) {
+	test.collect(test.scheduler.Cancel(req))
+}
+
+func (test *schedulerTest) finished(status *pb.DeploymentStatus) {
+	test.collect(test.scheduler.Finished(status))
+}
+
+func (test *schedulerTest) collect(statuses []*pb.DeploymentStatus) {
+	for _, status := range statuses {
+		test.statuses <- status
+	}
+}
+
+// Register a running operation with the scheduler, as deployd does before running a deployment.
+func (test *schedulerTest) operation(req *pb.DeploymentRequest) *operation.Operation {
+	ctx, cancel := context.WithCancel(context.Background())
+	op := &operation.Operation{
+		Context: ctx,
+		Cancel:  cancel,
+		Request: req,
+	}
+	test.scheduler.Started(op)
+	return op
+}
+
+func (test *schedulerTest) status() *pb.DeploymentStatus {
+	select {
+	case status := <-test.statuses:
+		return status
+	default:
+		return nil
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	t.Run("deployments of unrelated resources run concurrently", func(t *testing.T) {
+		test := newSchedulerTest()
+		test.submit(schedulerRequest(t, "1", pb.ConcurrencyPolicy_queue, "foo"))
+		test.submit(schedulerRequest(t, "2", pb.ConcurrencyPolicy_queue, "bar"))
+
+		assert.ElementsMatch(t, []string{"1", "2"}, []string{<-test.started, <-test.started})
+		assert.Nil(t, test.status())
+	})
+
+	t.Run("deployments of the same resources are queued in order", func(t *testing.T) {
+		test := newSchedulerTest()
+		first := schedulerRequest(t, "1", pb.ConcurrencyPolicy_queue, "foo", "bar")
+		test.submit(first)
+		assert.Equal(t, "1", <-test.started)
+
+		test.submit(schedulerRequest(t, "2", pb.ConcurrencyPolicy_queue, "bar"))
+		test.submit(schedulerRequest(t, "3", pb.ConcurrencyPolicy_queue, "bar", "baz"))
+
+		status := test.status()
+		assert.Equal(t, "2", status.GetRequest().GetID())
+		assert.Equal(t, pb.DeploymentState_queued, status.GetState())
+		assert.Contains(t, status.GetMessage(), "position 1 in queue")
+
+		status = test.status()
+		assert.Equal(t, "3", status.GetRequest().GetID())
+		assert.Contains(t, status.GetMessage(), "position 2 in queue")
+
+		test.finished(pb.NewSuccessStatus(first))
+		assert.Equal(t, "2", <-test.started)
+
+		status = test.status()
+		assert.Equal(t, "3", status.GetRequest().GetID())
+		assert.Contains(t, status.GetMessage(), "position 1 in queue")
+
+		assert.Len(t, test.started, 0)
+	})
+
+	t.Run("superseding deployment cancels running and queued deployments", func(t *testing.T) {
+		test := newSchedulerTest()
+		first := schedulerRequest(t, "1", pb.ConcurrencyPolicy_queue, "foo")
+		test.submit(first)
+		assert.Equal(t, "1", <-test.started)
+		op := test.operation(first)
+
+		test.submit(schedulerRequest(t, "2", pb.ConcurrencyPolicy_queue, "foo"))
+		assert.Equal(t, "2", test.status().GetRequest().GetID())
+
+		test.submit(schedulerRequest(t, "3", pb.ConcurrencyPolicy_supersede, "foo"))
+		assert.True(t, op.Aborted())
+
+		status := test.status()
+		assert.Equal(t, "2", status.GetRequest().GetID())
+		assert.True(t, status.GetCancelled())
+
+		status = test.status()
+		assert.Equal(t, "3", status.GetRequest().GetID())
+		assert.Equal(t, pb.DeploymentState_queued, status.GetState())
+
+		test.finished(pb.NewCancelledStatus(first))
+		assert.Equal(t, "3", <-test.started)
+	})
+
+	t.Run("deployment is rejected if resources are being deployed", func(t *testing.T) {
+		test := newSchedulerTest()
+		test.submit(schedulerRequest(t, "1", pb.ConcurrencyPolicy_queue, "foo"))
+		assert.Equal(t, "1", <-test.started)
+
+		test.submit(schedulerRequest(t, "2", pb.ConcurrencyPolicy_reject, "foo"))
+
+		status := test.status()
+		assert.Equal(t, "2", status.GetRequest().GetID())
+		assert.Equal(t, pb.DeploymentState_error, status.GetState())
+		assert.Equal(t, "resources are already being deployed by deployment 1", status.GetMessage())
+	})
+
+	t.Run("deployment request received twice is only started once", func(t *testing.T) {
+		test := newSchedulerTest()
+		test.submit(schedulerRequest(t, "1", pb.ConcurrencyPolicy_queue, "foo"))
+		assert.Equal(t, "1", <-test.started)
+
+		test.submit(schedulerRequest(t, "1", pb.ConcurrencyPolicy_queue, "foo"))
+		assert.Nil(t, test.status())
+		assert.Len(t, test.started, 0)
+	})
+
+	t.Run("deployment cancelled before it is started is aborted on start", func(t *testing.T) {
+		test := newSchedulerTest()
+		request := schedulerRequest(t, "1", pb.ConcurrencyPolicy_queue, "foo")
+		test.submit(request)
+		assert.Equal(t, "1", <-test.started)
+
+		test.cancel(request)
+		op := test.operation(request)
+		assert.True(t, op.Aborted())
+		assert.Error(t, op.Context.Err())
+	})
+
+	t.Run("status updates do not block on a full status channel", func(t *testing.T) {
+		// deployd's main loop is the only reader of the status channel, and also calls the scheduler.
+		// The scheduler must hand status updates back to it rather than sending them on the channel.
+		scheduler := NewScheduler(func(req *pb.DeploymentRequest) {})
+		first := schedulerRequest(t, "1", pb.ConcurrencyPolicy_queue, "foo")
+		scheduler.Submit(first)
+
+		done := make(chan []*pb.DeploymentStatus)
+		go func() {
+			statuses := make([]*pb.DeploymentStatus, 0)
+			for i := 2; i <= 64; i++ {
+				statuses = append(statuses, scheduler.Submit(schedulerRequest(t, fmt.Sprint(i), pb.ConcurrencyPolicy_queue, "foo"))...)
+			}
+			statuses = append(statuses, scheduler.Finished(pb.NewSuccessStatus(first))...)
+			done <- statuses
+		}()
+
+		select {
+		case statuses := <-done:
+			assert.Len(t, statuses, 63+62)
+		case <-time.After(5 * time.Second):
+			t.Fatal("scheduler blocked while reporting status updates")
+		}
+	})
+}
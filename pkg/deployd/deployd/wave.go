@@ -0,0 +1,57 @@
+package deployd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/nais/deploy/pkg/k8sutils"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// WaveAnnotation assigns a resource to a deploy wave. Waves are deployed in ascending numerical order,
+// and every resource in a wave must be successfully rolled out before the next wave is started.
+// Resources without the annotation belong to wave 0.
+const WaveAnnotation = "deploy.nais.io/wave"
+
+type wave struct {
+	number    int
+	resources []unstructured.Unstructured
+}
+
+func resourceWave(resource unstructured.Unstructured) (int, error) {
+	value, ok := resource.GetAnnotations()[WaveAnnotation]
+	if !ok {
+		return 0, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: annotation %s must be an integer, got %q", k8sutils.ResourceIdentifier(resource).String(), WaveAnnotation, value)
+	}
+	return number, nil
+}
+
+// Group resources into waves, ordered by wave number.
+// Resources keep their original order within each wave.
+func groupWaves(resources []unstructured.Unstructured) ([]wave, error) {
+	byNumber := make(map[int][]unstructured.Unstructured)
+
+	for _, resource := range resources {
+		number, err := resourceWave(resource)
+		if err != nil {
+			return nil, err
+		}
+		byNumber[number] = append(byNumber[number], resource)
+	}
+
+	waves := make([]wave, 0, len(byNumber))
+	for number, resources := range byNumber {
+		waves = append(waves, wave{number: number, resources: resources})
+	}
+
+	sort.Slice(waves, func(i, j int) bool {
+		return waves[i].number < waves[j].number
+	})
+
+	return waves, nil
+}
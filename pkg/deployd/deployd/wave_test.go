@@ -0,0 +1,153 @@
+package deployd
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nais/deploy/pkg/deployd/config"
+	"github.com/nais/deploy/pkg/deployd/operation"
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/nais/deploy/pkg/telemetry"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func waveResource(name, wave string) unstructured.Unstructured {
+	resource := unstructured.Unstructured{}
+	resource.SetAPIVersion("v1")
+	resource.SetKind("ConfigMap")
+	resource.SetNamespace("aura")
+	resource.SetName(name)
+	if len(wave) > 0 {
+		resource.SetAnnotations(map[string]string{
+			WaveAnnotation: wave,
+		})
+	}
+	return resource
+}
+
+func TestGroupWaves(t *testing.T) {
+	t.Run("resources are grouped by wave in ascending order", func(t *testing.T) {
+		waves, err := groupWaves([]unstructured.Unstructured{
+			waveResource("application", "10"),
+			waveResource("topic", "-1"),
+			waveResource("configmap", ""),
+			waveResource("migration", "-1"),
+			waveResource("secret", "0"),
+		})
+		assert.NoError(t, err)
+
+		names := func(w wave) []string {
+			result := make([]string, len(w.resources))
+			for i := range w.resources {
+				result[i] = w.resources[i].GetName()
+			}
+			return result
+		}
+
+		if assert.Len(t, waves, 3) {
+			assert.Equal(t, -1, waves[0].number)
+			assert.Equal(t, []string{"topic", "migration"}, names(waves[0]))
+			assert.Equal(t, 0, waves[1].number)
+			assert.Equal(t, []string{"configmap", "secret"}, names(waves[1]))
+			assert.Equal(t, 10, waves[2].number)
+			assert.Equal(t, []string{"application"}, names(waves[2]))
+		}
+	})
+
+	t.Run("invalid wave annotation is rejected", func(t *testing.T) {
+		_, err := groupWaves([]unstructured.Unstructured{
+			waveResource("application", "first"),
+		})
+		assert.EqualError(t, err, `/v1, Kind=ConfigMap, Namespace=aura, Name=application: annotation deploy.nais.io/wave must be an integer, got "first"`)
+	})
+}
+
+// dryRunResource honors server-side dry runs, which the fake dynamic client ignores. The "dependent" resource
+// can't be validated until the "dependency" resource exists, like a resource in a namespace created by an earlier wave.
+type dryRunResource struct {
+	dynamic.ResourceInterface
+}
+
+func (r *dryRunResource) Create(ctx context.Context, obj *unstructured.Unstructured, opts metav1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	if len(opts.DryRun) == 0 {
+		return r.ResourceInterface.Create(ctx, obj, opts, subresources...)
+	}
+	if obj.GetName() == "dependent" {
+		_, err := r.Get(ctx, "dependency", metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+type dryRunClient struct {
+	configMapClient
+}
+
+func (c *dryRunClient) ResourceInterface(resource *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	resourceInterface, err := c.configMapClient.ResourceInterface(resource)
+	if err != nil {
+		return nil, err
+	}
+	return &dryRunResource{resourceInterface}, nil
+}
+
+func TestDeployWaves(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, _ = telemetry.New(ctx, "test", "")
+
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+
+	resources, err := json.Marshal([]unstructured.Unstructured{
+		waveResource("dependent", "0"),
+		waveResource("dependency", "-1"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kube, err := pb.KubernetesFromJSONResources(resources)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statusChan := make(chan *pb.DeploymentStatus, 32)
+	opctx, opcancel := context.WithCancel(ctx)
+	_, span := telemetry.Tracer().Start(opctx, t.Name())
+	op := &operation.Operation{
+		Context:    opctx,
+		Cancel:     opcancel,
+		Logger:     log.WithField("test", t.Name()),
+		Request:    &pb.DeploymentRequest{ID: "1", Team: "aura", Kubernetes: kube},
+		Trace:      span,
+		StatusChan: statusChan,
+	}
+
+	Run(op, &dryRunClient{configMapClient{dynamic: client}}, &config.Config{}, nil)
+
+	for {
+		select {
+		case st := <-statusChan:
+			if !st.GetState().Finished() {
+				continue
+			}
+			assert.Equal(t, pb.DeploymentState_success, st.GetState(), st.GetMessage())
+			for _, name := range []string{"dependency", "dependent"} {
+				_, err := client.Resource(configMaps).Namespace("aura").Get(ctx, name, metav1.GetOptions{})
+				assert.NoError(t, err, "%s should be deployed", name)
+			}
+			return
+		case <-ctx.Done():
+			t.Fatal("deployment did not finish")
+		}
+	}
+}
@@ -3,6 +3,7 @@ package operation
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/nais/deploy/pkg/k8sutils"
 	"github.com/nais/deploy/pkg/pb"
@@ -18,6 +19,19 @@ type Operation struct {
 	Request    *pb.DeploymentRequest
 	Trace      trace.Span
 	StatusChan chan<- *pb.DeploymentStatus
+
+	aborted atomic.Bool
+}
+
+// Abort cancels the operation on request from the user, as opposed to the deadline expiring.
+func (op *Operation) Abort() {
+	op.aborted.Store(true)
+	op.Cancel()
+}
+
+// Aborted returns true if the operation was cancelled by Abort.
+func (op *Operation) Aborted() bool {
+	return op.aborted.Load()
 }
 
 func (op *Operation) ExtractResources() ([]unstructured.Unstructured, error) {
@@ -0,0 +1,107 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// FieldManager is the name deploy uses to claim ownership of fields when using server-side apply.
+const FieldManager = "nais-deploy"
+
+// serverSideApplyStrategy applies resources using Kubernetes server-side apply.
+// Only the fields present in the resource are claimed by deploy; fields set by other
+// controllers, such as replicas managed by a HorizontalPodAutoscaler, are left alone.
+type serverSideApplyStrategy struct {
+	client   dynamic.ResourceInterface
+	dryRun   []string
+	snapshot *Snapshot
+}
+
+func (s serverSideApplyStrategy) Deploy(ctx context.Context, resource unstructured.Unstructured, trace trace.Span) (*unstructured.Unstructured, error) {
+	// Apply does not need the existing resource, so only fetch it if we are asked to keep a snapshot.
+	if s.snapshot != nil {
+		existing, err := s.client.Get(ctx, resource.GetName(), metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			s.snapshot.record(s.client, resource.GetName(), nil)
+		} else if err != nil {
+			return nil, fmt.Errorf("get existing resource: %w", err)
+		} else {
+			s.snapshot.record(s.client, resource.GetName(), existing)
+		}
+	}
+
+	// Server-side apply rejects requests that specify these fields.
+	resource.SetResourceVersion("")
+	resource.SetManagedFields(nil)
+
+	data, err := json.Marshal(resource.Object)
+	if err != nil {
+		return nil, fmt.Errorf("encoding resource: %w", err)
+	}
+
+	force := false
+	applied, err := s.client.Patch(ctx, resource.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+		DryRun:          s.dryRun,
+		FieldManager:    FieldManager,
+		FieldValidation: metav1.FieldValidationStrict,
+		Force:           &force,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("applying resource: %w", transformApplyError(resource, err))
+	}
+
+	return applied, nil
+}
+
+func transformApplyError(resource unstructured.Unstructured, err error) error {
+	if errors.IsConflict(err) {
+		return transformFieldManagerConflictError(err)
+	}
+	return transformStrictDecodingError(resource, err)
+}
+
+// transformFieldManagerConflictError lists every field that is owned by another field manager,
+// so that the user can tell which controller they are fighting with.
+func transformFieldManagerConflictError(err error) error {
+	statusErr, ok := err.(errors.APIStatus)
+	if !ok || statusErr.Status().Details == nil {
+		return err
+	}
+
+	conflicts := make([]metav1.StatusCause, 0)
+	for _, cause := range statusErr.Status().Details.Causes {
+		if cause.Type == metav1.CauseTypeFieldManagerConflict {
+			conflicts = append(conflicts, cause)
+		}
+	}
+
+	if len(conflicts) == 0 {
+		return err
+	}
+
+	s := &strings.Builder{}
+	s.WriteString("field ownership conflict:")
+
+	for _, cause := range conflicts {
+		s.WriteString("\n| ⚠️ ")
+		if len(cause.Field) > 0 {
+			s.WriteString(cause.Field)
+			s.WriteString(": ")
+		}
+		s.WriteString(cause.Message)
+	}
+
+	s.WriteString("\n| Another controller or client already manages these fields on the existing resource.")
+	s.WriteString("\n| Remove the fields from your resource, or stop the other party from managing them.")
+
+	return fmt.Errorf("%s", s.String())
+}
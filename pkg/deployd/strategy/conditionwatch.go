@@ -0,0 +1,216 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nais/deploy/pkg/deployd/kubeclient"
+	"github.com/nais/deploy/pkg/deployd/operation"
+	"github.com/nais/deploy/pkg/pb"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Condition matches a single entry in a resource's `status.conditions` list.
+type Condition struct {
+	Type   string
+	Status metav1.ConditionStatus
+}
+
+func (c Condition) String() string {
+	return fmt.Sprintf("%s=%s", c.Type, c.Status)
+}
+
+// ParseCondition parses a condition in the form `Type=Status`, e.g. `Ready=True`.
+func ParseCondition(condition string) (Condition, error) {
+	parts := strings.SplitN(condition, "=", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return Condition{}, fmt.Errorf("condition %q must be in the form Type=Status", condition)
+	}
+	return Condition{
+		Type:   parts[0],
+		Status: metav1.ConditionStatus(parts[1]),
+	}, nil
+}
+
+// ConditionWatches configures which resource kinds are watched by inspecting their status conditions,
+// and which conditions signal success and failure.
+type ConditionWatches struct {
+	Resources []schema.GroupVersionKind
+	Success   Condition
+	Failure   *Condition
+}
+
+// NewConditionWatches parses a list of resource kinds in the form `Kind.version.group`, e.g. `Topic.v1.kafka.nais.io`,
+// and the success and failure conditions in the form `Type=Status`. The failure condition is optional.
+// Returns nil if no resource kinds are configured.
+func NewConditionWatches(resources []string, success, failure string) (*ConditionWatches, error) {
+	if len(resources) == 0 {
+		return nil, nil
+	}
+
+	watches := &ConditionWatches{
+		Resources: make([]schema.GroupVersionKind, 0, len(resources)),
+	}
+
+	for _, resource := range resources {
+		gvk, _ := schema.ParseKindArg(resource)
+		if gvk == nil || len(gvk.Group) == 0 {
+			return nil, fmt.Errorf("resource %q must be in the form Kind.version.group", resource)
+		}
+		watches.Resources = append(watches.Resources, *gvk)
+	}
+
+	var err error
+	watches.Success, err = ParseCondition(success)
+	if err != nil {
+		return nil, fmt.Errorf("success condition: %w", err)
+	}
+
+	if len(failure) > 0 {
+		condition, err := ParseCondition(failure)
+		if err != nil {
+			return nil, fmt.Errorf("failure condition: %w", err)
+		}
+		watches.Failure = &condition
+	}
+
+	return watches, nil
+}
+
+// Matches returns true if resources of this kind should be watched by their status conditions.
+func (c *ConditionWatches) Matches(gvk schema.GroupVersionKind) bool {
+	if c == nil {
+		return false
+	}
+	for _, resource := range c.Resources {
+		if resource == gvk {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionWatch waits for any resource exposing `status.conditions` to reach the success condition.
+type conditionWatch struct {
+	client  kubeclient.Interface
+	success Condition
+	failure *Condition
+}
+
+func (c conditionWatch) Watch(op *operation.Operation, resource unstructured.Unstructured, trace trace.Span) *pb.DeploymentStatus {
+	var current *unstructured.Unstructured
+	var err error
+
+	client, err := c.client.ResourceInterface(&resource)
+	if err != nil {
+		return pb.NewErrorStatus(op.Request, fmt.Errorf("unable to set up condition watcher: %w", err))
+	}
+
+	kind := resource.GetKind()
+	progress := &progressReporter{op: op, trace: trace}
+
+	ctx, cancel := context.WithCancel(op.Context)
+	defer cancel()
+
+	for ctx.Err() == nil {
+		current, err = client.Get(ctx, resource.GetName(), metav1.GetOptions{})
+		if err != nil {
+			waitForPoll(ctx)
+			continue
+		}
+
+		if !generationObserved(current) {
+			op.Logger.Debugf("Still waiting for %s to observe generation %d...", kind, current.GetGeneration())
+			waitForPoll(ctx)
+			continue
+		}
+
+		conditions := currentConditions(current)
+
+		if c.failure != nil {
+			if condition, ok := conditions[c.failure.Type]; ok && condition.Status == c.failure.Status {
+				return pb.NewFailureStatus(op.Request, fmt.Errorf("%s/%s: %s", kind, current.GetName(), condition.describe()))
+			}
+		}
+
+		condition, ok := conditions[c.success.Type]
+		if ok && condition.Status == c.success.Status {
+			return pb.NewSuccessStatus(op.Request)
+		}
+
+		if ok {
+			progress.report("%s/%s: waiting for %s, currently %s", kind, current.GetName(), c.success, condition.describe())
+		} else {
+			progress.report("%s/%s: waiting for %s", kind, current.GetName(), c.success)
+		}
+
+		waitForPoll(ctx)
+	}
+
+	if err != nil {
+		return pb.NewErrorStatus(op.Request, fmt.Errorf("%s; last error was: %s", ErrDeploymentTimeout, err))
+	}
+
+	return pb.NewErrorStatus(op.Request, ErrDeploymentTimeout)
+}
+
+type resourceCondition struct {
+	Type    string
+	Status  metav1.ConditionStatus
+	Reason  string
+	Message string
+}
+
+func (c resourceCondition) describe() string {
+	s := fmt.Sprintf("%s=%s", c.Type, c.Status)
+	if len(c.Reason) > 0 {
+		s += fmt.Sprintf(" (%s)", c.Reason)
+	}
+	if len(c.Message) > 0 {
+		s += ": " + c.Message
+	}
+	return s
+}
+
+// generationObserved returns false if the resource reports an observed generation older than the current generation.
+// Resources that don't report an observed generation are assumed to be up to date.
+func generationObserved(resource *unstructured.Unstructured) bool {
+	observed, found, err := unstructured.NestedInt64(resource.Object, "status", "observedGeneration")
+	if err != nil || !found {
+		return true
+	}
+	return observed >= resource.GetGeneration()
+}
+
+// currentConditions returns the resource's status conditions keyed by type.
+// Conditions that report an observed generation older than the current generation are stale, and left out.
+func currentConditions(resource *unstructured.Unstructured) map[string]resourceCondition {
+	conditions := make(map[string]resourceCondition)
+
+	list, _, _ := unstructured.NestedSlice(resource.Object, "status", "conditions")
+	for _, item := range list {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		observed, found, err := unstructured.NestedInt64(fields, "observedGeneration")
+		if err == nil && found && observed < resource.GetGeneration() {
+			continue
+		}
+
+		condition := resourceCondition{}
+		condition.Type, _, _ = unstructured.NestedString(fields, "type")
+		status, _, _ := unstructured.NestedString(fields, "status")
+		condition.Status = metav1.ConditionStatus(status)
+		condition.Reason, _, _ = unstructured.NestedString(fields, "reason")
+		condition.Message, _, _ = unstructured.NestedString(fields, "message")
+		conditions[condition.Type] = condition
+	}
+
+	return conditions
+}
@@ -0,0 +1,119 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace/noop"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func topic(generation int64, status map[string]interface{}) *unstructured.Unstructured {
+	resource := &unstructured.Unstructured{}
+	resource.SetAPIVersion("kafka.nais.io/v1")
+	resource.SetKind("Topic")
+	resource.SetNamespace("aura")
+	resource.SetName("mytopic")
+	resource.SetGeneration(generation)
+	if status != nil {
+		resource.Object["status"] = status
+	}
+	return resource
+}
+
+func condition(conditionType, status, reason string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":   conditionType,
+		"status": status,
+		"reason": reason,
+	}
+}
+
+func TestNewConditionWatches(t *testing.T) {
+	watches, err := NewConditionWatches(nil, "", "")
+	assert.NoError(t, err)
+	assert.Nil(t, watches)
+
+	watches, err = NewConditionWatches([]string{"Topic.v1.kafka.nais.io", "Redis.v1alpha1.aiven.io"}, "Ready=True", "Failed=True")
+	assert.NoError(t, err)
+	assert.True(t, watches.Matches(schema.GroupVersionKind{Group: "kafka.nais.io", Version: "v1", Kind: "Topic"}))
+	assert.True(t, watches.Matches(schema.GroupVersionKind{Group: "aiven.io", Version: "v1alpha1", Kind: "Redis"}))
+	assert.False(t, watches.Matches(schema.GroupVersionKind{Group: "aiven.io", Version: "v1alpha1", Kind: "OpenSearch"}))
+	assert.Equal(t, Condition{Type: "Ready", Status: "True"}, watches.Success)
+	assert.Equal(t, &Condition{Type: "Failed", Status: "True"}, watches.Failure)
+
+	_, err = NewConditionWatches([]string{"Topic"}, "Ready=True", "")
+	assert.EqualError(t, err, `resource "Topic" must be in the form Kind.version.group`)
+
+	_, err = NewConditionWatches([]string{"Topic.v1.kafka.nais.io"}, "Ready", "")
+	assert.EqualError(t, err, `success condition: condition "Ready" must be in the form Type=Status`)
+}
+
+func TestConditionWatch(t *testing.T) {
+	defer func(interval time.Duration) { requestInterval = interval }(requestInterval)
+	requestInterval = time.Millisecond
+
+	watch := func(resource *unstructured.Unstructured) *pb.DeploymentStatus {
+		client := &fakeClient{
+			dynamic: newFakeDynamicClient(resource),
+			gvr:     topicGVR,
+		}
+		op, cancel := newOperation(time.Second)
+		defer cancel()
+		strat := conditionWatch{
+			client:  client,
+			success: Condition{Type: "Ready", Status: "True"},
+			failure: &Condition{Type: "Failed", Status: "True"},
+		}
+		return strat.Watch(op, *topic(0, nil), noop.Span{})
+	}
+
+	t.Run("success condition completes rollout", func(t *testing.T) {
+		status := watch(topic(2, map[string]interface{}{
+			"observedGeneration": int64(2),
+			"conditions":         []interface{}{condition("Ready", "True", "")},
+		}))
+		assert.Equal(t, pb.DeploymentState_success, status.GetState())
+	})
+
+	t.Run("failure condition fails rollout", func(t *testing.T) {
+		status := watch(topic(2, map[string]interface{}{
+			"observedGeneration": int64(2),
+			"conditions": []interface{}{
+				condition("Ready", "False", ""),
+				condition("Failed", "True", "InvalidConfig"),
+			},
+		}))
+		assert.Equal(t, pb.DeploymentState_failure, status.GetState())
+		assert.Equal(t, "Topic/mytopic: Failed=True (InvalidConfig)", status.GetMessage())
+	})
+
+	t.Run("stale conditions are ignored until generation is observed", func(t *testing.T) {
+		status := watch(topic(2, map[string]interface{}{
+			"observedGeneration": int64(1),
+			"conditions":         []interface{}{condition("Ready", "True", "")},
+		}))
+		assert.Equal(t, pb.DeploymentState_error, status.GetState())
+		assert.Equal(t, ErrDeploymentTimeout.Error(), status.GetMessage())
+	})
+
+	t.Run("cancelled rollout stops polling immediately", func(t *testing.T) {
+		requestInterval = time.Hour
+		defer func() { requestInterval = time.Millisecond }()
+
+		client := &fakeClient{
+			dynamic: newFakeDynamicClient(topic(2, map[string]interface{}{"observedGeneration": int64(1)})),
+			gvr:     topicGVR,
+		}
+		op, cancel := newOperation(time.Minute)
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		start := time.Now()
+		status := conditionWatch{client: client, success: Condition{Type: "Ready", Status: "True"}}.Watch(op, *topic(0, nil), noop.Span{})
+		assert.Equal(t, pb.DeploymentState_error, status.GetState())
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
@@ -0,0 +1,102 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nais/deploy/pkg/deployd/kubeclient"
+	"github.com/nais/deploy/pkg/deployd/operation"
+	"github.com/nais/deploy/pkg/pb"
+	"go.opentelemetry.io/otel/trace"
+	batch "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// How long to look for schedule errors reported by the cronjob controller before the schedule is considered accepted.
+var cronJobSettleTime = time.Second * 15
+
+// Warning events emitted by the cronjob controller when it is unable to schedule a cronjob.
+var cronJobScheduleErrorReasons = map[string]bool{
+	"UnparseableSchedule": true,
+	"InvalidSchedule":     true,
+	"UnknownTimeZone":     true,
+}
+
+type cronJob struct {
+	client kubeclient.Interface
+}
+
+func (c cronJob) Watch(op *operation.Operation, resource unstructured.Unstructured, trace trace.Span) *pb.DeploymentStatus {
+	var cronjob *batch.CronJob
+	var eventList *v1.EventList
+	var err error
+
+	client := c.client.Kubernetes().BatchV1().CronJobs(resource.GetNamespace())
+	eventsClient := c.client.Kubernetes().CoreV1().Events(resource.GetNamespace())
+	progress := &progressReporter{op: op, trace: trace}
+	watchStart := time.Now().Truncate(time.Second)
+
+	ctx, cancel := context.WithCancel(op.Context)
+	defer cancel()
+
+	for {
+		cronjob, err = client.Get(ctx, resource.GetName(), metav1.GetOptions{})
+		if err == nil {
+			eventList, err = eventsClient.List(ctx, metav1.ListOptions{
+				FieldSelector: fmt.Sprintf("involvedObject.kind=CronJob,involvedObject.name=%s", cronjob.Name),
+			})
+		}
+
+		if err == nil {
+			if event := cronJobScheduleError(cronjob, eventList.Items, watchStart); event != nil {
+				return pb.NewFailureStatus(op.Request, fmt.Errorf("CronJob/%s: schedule %q was rejected: %s", cronjob.Name, cronjob.Spec.Schedule, event.Message))
+			}
+
+			if time.Since(watchStart) >= cronJobSettleTime {
+				if cronjob.Spec.Suspend != nil && *cronjob.Spec.Suspend {
+					progress.report("CronJob/%s: schedule %q accepted, but the cronjob is suspended", cronjob.Name, cronjob.Spec.Schedule)
+				} else {
+					progress.report("CronJob/%s: schedule %q accepted", cronjob.Name, cronjob.Spec.Schedule)
+				}
+				return pb.NewSuccessStatus(op.Request)
+			}
+
+			op.Logger.Debugf("Still waiting for cronjob schedule to be accepted...")
+		}
+
+		if !waitForPoll(ctx) {
+			break
+		}
+	}
+
+	if err != nil {
+		return pb.NewErrorStatus(op.Request, fmt.Errorf("%s; last error was: %s", ErrDeploymentTimeout, err))
+	}
+
+	return pb.NewErrorStatus(op.Request, ErrDeploymentTimeout)
+}
+
+// cronJobScheduleError returns the first warning event emitted after the deployment started
+// that tells us the cronjob controller could not parse the schedule or time zone.
+func cronJobScheduleError(cronjob *batch.CronJob, events []v1.Event, since time.Time) *v1.Event {
+	for i := range events {
+		event := &events[i]
+		if event.InvolvedObject.Kind != "CronJob" || event.InvolvedObject.Name != cronjob.Name {
+			continue
+		}
+		if len(event.InvolvedObject.UID) > 0 && event.InvolvedObject.UID != cronjob.UID {
+			continue
+		}
+		if event.Type != v1.EventTypeWarning || !cronJobScheduleErrorReasons[event.Reason] {
+			continue
+		}
+		if event.LastTimestamp.Time.Before(since) {
+			continue
+		}
+		return event
+	}
+	return nil
+}
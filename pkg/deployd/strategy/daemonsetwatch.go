@@ -0,0 +1,84 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nais/deploy/pkg/deployd/kubeclient"
+	"github.com/nais/deploy/pkg/deployd/operation"
+	"github.com/nais/deploy/pkg/pb"
+	"go.opentelemetry.io/otel/trace"
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+type daemonSet struct {
+	client kubeclient.Interface
+}
+
+func (d daemonSet) Watch(op *operation.Operation, resource unstructured.Unstructured, trace trace.Span) *pb.DeploymentStatus {
+	client := d.client.Kubernetes().AppsV1().DaemonSets(resource.GetNamespace())
+	progress := &progressReporter{op: op, trace: trace}
+
+	ctx, cancel := context.WithCancel(op.Context)
+	defer cancel()
+
+	watcher := newObjectWatcher(resource.GetName(), func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+		return client.List(ctx, options)
+	}, client.Watch)
+
+	// Wait until the daemonset is present in the cluster, and has finished rolling out.
+	condition := func(event watch.Event) (bool, error) {
+		set, ok := event.Object.(*apps.DaemonSet)
+		if !ok || event.Type == watch.Deleted || set.GetName() != resource.GetName() {
+			return false, nil
+		}
+
+		if daemonSetComplete(set) {
+			return true, nil
+		}
+
+		if set.Status.ObservedGeneration >= set.Generation {
+			progress.report("DaemonSet/%s: %d of %d pods updated, %d available", set.Name, set.Status.UpdatedNumberScheduled, set.Status.DesiredNumberScheduled, set.Status.NumberAvailable)
+		}
+
+		op.Logger.Debugf("Still waiting for daemonset to finish rollout...")
+
+		return false, nil
+	}
+
+	err := watcher.Until(ctx, &apps.DaemonSet{}, nil, condition)
+	if err == nil {
+		return pb.NewSuccessStatus(op.Request)
+	}
+
+	if err := watcher.LastError(); err != nil {
+		err = fmt.Errorf("%s; last error was: %w", ErrDeploymentTimeout, err)
+		trace.AddEvent(err.Error())
+		return pb.NewErrorStatus(op.Request, err)
+	}
+
+	return pb.NewErrorStatus(op.Request, ErrDeploymentTimeout)
+}
+
+// daemonSetComplete considers a daemonset to be complete once the controller has observed the
+// latest generation, and an updated pod is available on every node that should run one.
+//
+// Pods are not replaced automatically with the OnDelete update strategy, so in that case
+// the daemonset is complete as soon as the controller has observed it.
+func daemonSetComplete(set *apps.DaemonSet) bool {
+	status := set.Status
+	if status.ObservedGeneration < set.Generation {
+		return false
+	}
+
+	if set.Spec.UpdateStrategy.Type == apps.OnDeleteDaemonSetStrategyType {
+		return true
+	}
+
+	return status.UpdatedNumberScheduled == status.DesiredNumberScheduled &&
+		status.NumberAvailable == status.DesiredNumberScheduled
+}
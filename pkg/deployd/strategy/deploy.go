@@ -12,8 +12,45 @@ import (
 	"k8s.io/client-go/dynamic"
 )
 
-func NewDeployStrategy(namespacedResource dynamic.ResourceInterface) DeployStrategy {
-	return createOrUpdateStrategy{client: namespacedResource}
+const (
+	// Annotation on a resource that overrides the deploy strategy configured for the cluster.
+	DeployStrategyAnnotation = "deploy.nais.io/deploy-strategy"
+
+	CreateOrUpdate  = "create-or-update"
+	ServerSideApply = "server-side-apply"
+)
+
+// NewDeployStrategy returns the named deploy strategy. If snapshot is non-nil,
+// the state of the resource prior to deployment is recorded into it.
+func NewDeployStrategy(name string, namespacedResource dynamic.ResourceInterface, snapshot *Snapshot) (DeployStrategy, error) {
+	return newDeployStrategy(name, namespacedResource, nil, snapshot)
+}
+
+// NewDryRunDeployStrategy returns a deploy strategy that sends its requests to the API server as a server-side dry run.
+// Resources are validated and run through admission, but nothing is persisted.
+func NewDryRunDeployStrategy(name string, namespacedResource dynamic.ResourceInterface) (DeployStrategy, error) {
+	return newDeployStrategy(name, namespacedResource, []string{metav1.DryRunAll}, nil)
+}
+
+func newDeployStrategy(name string, namespacedResource dynamic.ResourceInterface, dryRun []string, snapshot *Snapshot) (DeployStrategy, error) {
+	switch name {
+	case CreateOrUpdate, "":
+		return createOrUpdateStrategy{client: namespacedResource, dryRun: dryRun, snapshot: snapshot}, nil
+	case ServerSideApply:
+		return serverSideApplyStrategy{client: namespacedResource, dryRun: dryRun, snapshot: snapshot}, nil
+	default:
+		return nil, fmt.Errorf("unknown deploy strategy %q; valid strategies are %q and %q", name, CreateOrUpdate, ServerSideApply)
+	}
+}
+
+// DeployStrategyName returns the deploy strategy requested by the resource's annotations,
+// or the fallback value if the resource does not specify one.
+func DeployStrategyName(resource unstructured.Unstructured, fallback string) string {
+	name, ok := resource.GetAnnotations()[DeployStrategyAnnotation]
+	if !ok || len(name) == 0 {
+		return fallback
+	}
+	return name
 }
 
 type DeployStrategy interface {
@@ -21,13 +58,17 @@ type DeployStrategy interface {
 }
 
 type createOrUpdateStrategy struct {
-	client dynamic.ResourceInterface
+	client   dynamic.ResourceInterface
+	dryRun   []string
+	snapshot *Snapshot
 }
 
 func (c createOrUpdateStrategy) Deploy(ctx context.Context, resource unstructured.Unstructured, trace trace.Span) (*unstructured.Unstructured, error) {
 	existing, err := c.client.Get(ctx, resource.GetName(), metav1.GetOptions{})
 	if errors.IsNotFound(err) {
+		c.snapshot.record(c.client, resource.GetName(), nil)
 		deployed, err := c.client.Create(ctx, &resource, metav1.CreateOptions{
+			DryRun:          c.dryRun,
 			FieldValidation: metav1.FieldValidationStrict,
 		})
 		if err != nil {
@@ -38,8 +79,10 @@ func (c createOrUpdateStrategy) Deploy(ctx context.Context, resource unstructure
 		return nil, fmt.Errorf("get existing resource: %w", err)
 	}
 
+	c.snapshot.record(c.client, resource.GetName(), existing)
 	resource.SetResourceVersion(existing.GetResourceVersion())
 	updated, err := c.client.Update(ctx, &resource, metav1.UpdateOptions{
+		DryRun:          c.dryRun,
 		FieldValidation: metav1.FieldValidationStrict,
 	})
 	if err != nil {
@@ -0,0 +1,194 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace/noop"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var (
+	configMapGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	topicGVR     = schema.GroupVersionResource{Group: "kafka.nais.io", Version: "v1", Resource: "topics"}
+)
+
+func configMap(name string, annotations map[string]string) *unstructured.Unstructured {
+	resource := &unstructured.Unstructured{}
+	resource.SetAPIVersion("v1")
+	resource.SetKind("ConfigMap")
+	resource.SetNamespace("aura")
+	resource.SetName(name)
+	resource.SetAnnotations(annotations)
+	_ = unstructured.SetNestedField(resource.Object, "bar", "data", "foo")
+	return resource
+}
+
+func newFakeDynamicClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		configMapGVR: "ConfigMapList",
+		topicGVR:     "TopicList",
+	}, objects...)
+}
+
+// The fake dynamic client does not pass patch options on to reactors, so record them here.
+type patchOptionsRecorder struct {
+	dynamic.ResourceInterface
+	options *metav1.PatchOptions
+}
+
+func (r *patchOptionsRecorder) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	r.options = &options
+	return r.ResourceInterface.Patch(ctx, name, pt, data, options, subresources...)
+}
+
+func TestDeployStrategyName(t *testing.T) {
+	assert.Equal(t, CreateOrUpdate, DeployStrategyName(*configMap("foo", nil), CreateOrUpdate))
+	assert.Equal(t, ServerSideApply, DeployStrategyName(*configMap("foo", nil), ServerSideApply))
+	assert.Equal(t, ServerSideApply, DeployStrategyName(*configMap("foo", map[string]string{
+		DeployStrategyAnnotation: ServerSideApply,
+	}), CreateOrUpdate))
+	assert.Equal(t, CreateOrUpdate, DeployStrategyName(*configMap("foo", map[string]string{
+		DeployStrategyAnnotation: CreateOrUpdate,
+	}), ServerSideApply))
+}
+
+func TestNewDeployStrategy(t *testing.T) {
+	strat, err := NewDeployStrategy("", nil, nil)
+	assert.NoError(t, err)
+	assert.IsType(t, createOrUpdateStrategy{}, strat)
+
+	strat, err = NewDeployStrategy(ServerSideApply, nil, nil)
+	assert.NoError(t, err)
+	assert.IsType(t, serverSideApplyStrategy{}, strat)
+
+	_, err = NewDeployStrategy("replace", nil, nil)
+	assert.EqualError(t, err, `unknown deploy strategy "replace"; valid strategies are "create-or-update" and "server-side-apply"`)
+}
+
+func TestNewDryRunDeployStrategy(t *testing.T) {
+	strat, err := NewDryRunDeployStrategy(CreateOrUpdate, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, createOrUpdateStrategy{dryRun: []string{metav1.DryRunAll}}, strat)
+
+	strat, err = NewDryRunDeployStrategy(ServerSideApply, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, serverSideApplyStrategy{dryRun: []string{metav1.DryRunAll}}, strat)
+}
+
+func TestCreateOrUpdateStrategy(t *testing.T) {
+	ctx := context.Background()
+	span := noop.Span{}
+
+	existing := configMap("existing", nil)
+	existing.SetResourceVersion("42")
+	client := newFakeDynamicClient(existing)
+	resourceClient := client.Resource(configMapGVR).Namespace("aura")
+	strat := createOrUpdateStrategy{client: resourceClient}
+
+	t.Run("new resource is created", func(t *testing.T) {
+		_, err := strat.Deploy(ctx, *configMap("new", nil), span)
+		assert.NoError(t, err)
+
+		_, err = resourceClient.Get(ctx, "new", metav1.GetOptions{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("existing resource is updated", func(t *testing.T) {
+		updated := configMap("existing", nil)
+		_ = unstructured.SetNestedField(updated.Object, "baz", "data", "foo")
+		_, err := strat.Deploy(ctx, *updated, span)
+		assert.NoError(t, err)
+
+		saved, err := resourceClient.Get(ctx, "existing", metav1.GetOptions{})
+		assert.NoError(t, err)
+		value, _, _ := unstructured.NestedString(saved.Object, "data", "foo")
+		assert.Equal(t, "baz", value)
+	})
+}
+
+func TestServerSideApplyStrategy(t *testing.T) {
+	ctx := context.Background()
+	span := noop.Span{}
+
+	t.Run("resource is applied with field manager and without force", func(t *testing.T) {
+		var action *k8stesting.PatchActionImpl
+
+		client := newFakeDynamicClient()
+		client.PrependReactor("patch", "configmaps", func(a k8stesting.Action) (bool, runtime.Object, error) {
+			patch := a.(k8stesting.PatchActionImpl)
+			action = &patch
+			return true, configMap(action.GetName(), nil), nil
+		})
+
+		resource := configMap("foo", nil)
+		resource.SetResourceVersion("42")
+
+		recorder := &patchOptionsRecorder{ResourceInterface: client.Resource(configMapGVR).Namespace("aura")}
+		strat := serverSideApplyStrategy{client: recorder}
+		applied, err := strat.Deploy(ctx, *resource, span)
+		assert.NoError(t, err)
+		assert.Equal(t, "foo", applied.GetName())
+
+		if assert.NotNil(t, action) {
+			assert.Equal(t, types.ApplyPatchType, action.GetPatchType())
+			assert.Equal(t, "aura", action.GetNamespace())
+			assert.Equal(t, "foo", action.GetName())
+			assert.NotContains(t, string(action.GetPatch()), "resourceVersion")
+		}
+
+		if assert.NotNil(t, recorder.options) {
+			assert.Equal(t, FieldManager, recorder.options.FieldManager)
+			assert.Equal(t, metav1.FieldValidationStrict, recorder.options.FieldValidation)
+			if assert.NotNil(t, recorder.options.Force) {
+				assert.False(t, *recorder.options.Force)
+			}
+		}
+	})
+
+	t.Run("field ownership conflicts are reported", func(t *testing.T) {
+		client := newFakeDynamicClient()
+		client.PrependReactor("patch", "configmaps", func(a k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.NewApplyConflict([]metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldManagerConflict,
+					Message: `conflict with "kube-controller-manager" using apps/v1`,
+					Field:   ".spec.replicas",
+				},
+				{
+					Type:    metav1.CauseTypeFieldManagerConflict,
+					Message: `conflict with "istio-sidecar-injector" using v1`,
+					Field:   `.spec.template.spec.containers[name="istio-proxy"]`,
+				},
+			}, "Apply failed with 2 conflicts")
+		})
+
+		strat := serverSideApplyStrategy{client: client.Resource(configMapGVR).Namespace("aura")}
+		_, err := strat.Deploy(ctx, *configMap("foo", nil), span)
+		assert.EqualError(t, err, "applying resource: field ownership conflict:"+
+			"\n| ⚠️ .spec.replicas: conflict with \"kube-controller-manager\" using apps/v1"+
+			"\n| ⚠️ .spec.template.spec.containers[name=\"istio-proxy\"]: conflict with \"istio-sidecar-injector\" using v1"+
+			"\n| Another controller or client already manages these fields on the existing resource."+
+			"\n| Remove the fields from your resource, or stop the other party from managing them.")
+	})
+
+	t.Run("other errors are passed through", func(t *testing.T) {
+		client := newFakeDynamicClient()
+		client.PrependReactor("patch", "configmaps", func(a k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.NewForbidden(configMapGVR.GroupResource(), "foo", nil)
+		})
+
+		strat := serverSideApplyStrategy{client: client.Resource(configMapGVR).Namespace("aura")}
+		_, err := strat.Deploy(ctx, *configMap("foo", nil), span)
+		assert.True(t, errors.IsForbidden(err))
+	})
+}
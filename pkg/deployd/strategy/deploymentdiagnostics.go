@@ -0,0 +1,268 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	// How often to inspect the pods of a deployment that is still rolling out.
+	podDiagnosticsInterval = time.Second * 10
+
+	// A container that has been restarted this many times while in CrashLoopBackOff fails the rollout.
+	crashLoopRestartLimit int32 = 3
+)
+
+const revisionAnnotation = "deployment.kubernetes.io/revision"
+
+// Generic detail for containers that are not ready, replaced by the last probe failure message when there is one.
+const notReadyDetail = "readiness probe is failing"
+
+// Waiting reasons that will not resolve without a change to the deployment.
+var fatalWaitingReasons = map[string]bool{
+	"ImagePullBackOff":  true,
+	"InvalidImageName":  true,
+	"ErrImageNeverPull": true,
+}
+
+// Waiting reasons that are part of normal container startup.
+var startingWaitingReasons = map[string]bool{
+	"ContainerCreating": true,
+	"PodInitializing":   true,
+}
+
+// podProblem describes a container that prevents a pod from becoming ready.
+type podProblem struct {
+	Pod       string
+	Container string
+	Reason    string
+	Detail    string
+	// Fatal problems will not resolve by themselves, and fail the rollout immediately.
+	Fatal bool
+}
+
+func (p podProblem) String() string {
+	s := fmt.Sprintf("Pod/%s container %q: %s", p.Pod, p.Container, p.Reason)
+	if len(p.Detail) > 0 {
+		s += " (" + p.Detail + ")"
+	}
+	return s
+}
+
+// progressDeadlineExceeded returns true if the deployment controller has given up waiting for the rollout to progress.
+func progressDeadlineExceeded(deployment *apps.Deployment) (bool, string) {
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == apps.DeploymentProgressing && condition.Status == v1.ConditionFalse && condition.Reason == "ProgressDeadlineExceeded" {
+			return true, condition.Message
+		}
+	}
+	return false, ""
+}
+
+// Describe the last time a container terminated, e.g. `last terminated with OOMKilled, exit code 137`.
+func lastTermination(status v1.ContainerStatus) string {
+	terminated := status.LastTerminationState.Terminated
+	if terminated == nil {
+		terminated = status.State.Terminated
+	}
+	if terminated == nil {
+		return ""
+	}
+	reason := terminated.Reason
+	if len(reason) == 0 {
+		reason = "unknown reason"
+	}
+	return fmt.Sprintf("last terminated with %s, exit code %d", reason, terminated.ExitCode)
+}
+
+func containerProblem(pod *v1.Pod, status v1.ContainerStatus) *podProblem {
+	problem := &podProblem{
+		Pod:       pod.Name,
+		Container: status.Name,
+	}
+
+	details := make([]string, 0, 2)
+
+	switch {
+	case status.State.Waiting != nil && len(status.State.Waiting.Reason) > 0:
+		waiting := status.State.Waiting
+		if startingWaitingReasons[waiting.Reason] {
+			return nil
+		}
+		problem.Reason = waiting.Reason
+		problem.Fatal = fatalWaitingReasons[waiting.Reason]
+		if waiting.Reason == "CrashLoopBackOff" {
+			problem.Fatal = status.RestartCount >= crashLoopRestartLimit
+			details = append(details, fmt.Sprintf("restarted %d times", status.RestartCount))
+		} else if len(waiting.Message) > 0 {
+			details = append(details, waiting.Message)
+		}
+
+	case status.State.Terminated != nil && status.State.Terminated.ExitCode != 0:
+		problem.Reason = "Terminated"
+
+	case status.State.Running != nil && !status.Ready:
+		if status.Started != nil && !*status.Started {
+			// Startup probe has not yet succeeded; this is expected for slow starting containers.
+			return nil
+		}
+		problem.Reason = "NotReady"
+		details = append(details, notReadyDetail)
+
+	default:
+		return nil
+	}
+
+	if termination := lastTermination(status); len(termination) > 0 {
+		details = append(details, termination)
+	}
+
+	problem.Detail = strings.Join(details, ", ")
+
+	return problem
+}
+
+// podProblems lists every container in the given pods that prevents the pod from becoming ready.
+func podProblems(pods []v1.Pod) []podProblem {
+	problems := make([]podProblem, 0)
+
+	for i := range pods {
+		pod := &pods[i]
+		statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			if problem := containerProblem(pod, status); problem != nil {
+				problems = append(problems, *problem)
+			}
+		}
+	}
+
+	return problems
+}
+
+// newReplicaSet returns the replicaset that runs the current revision of the deployment, or nil if it has not been created yet.
+func newReplicaSet(ctx context.Context, client kubernetes.Interface, deployment *apps.Deployment) (*apps.ReplicaSet, error) {
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	replicaSets, err := client.AppsV1().ReplicaSets(deployment.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make([]apps.ReplicaSet, 0, len(replicaSets.Items))
+	for _, rs := range replicaSets.Items {
+		owner := metav1.GetControllerOf(&rs)
+		if owner != nil && owner.UID == deployment.UID {
+			owned = append(owned, rs)
+		}
+	}
+
+	revision := deployment.Annotations[revisionAnnotation]
+	for i := range owned {
+		if len(revision) > 0 && owned[i].Annotations[revisionAnnotation] == revision {
+			return &owned[i], nil
+		}
+	}
+
+	if len(owned) == 0 || len(revision) > 0 {
+		return nil, nil
+	}
+
+	sort.Slice(owned, func(i, j int) bool {
+		return owned[j].CreationTimestamp.Before(&owned[i].CreationTimestamp)
+	})
+
+	return &owned[0], nil
+}
+
+// deploymentPodProblems inspects the pods of the current revision of the deployment.
+// Failing readiness probes are described using the most recent probe failure event, if any.
+func deploymentPodProblems(ctx context.Context, client kubernetes.Interface, deployment *apps.Deployment) ([]podProblem, error) {
+	rs, err := newReplicaSet(ctx, client, deployment)
+	if err != nil || rs == nil {
+		return nil, err
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(rs.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	podList, err := client.CoreV1().Pods(deployment.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pods := make([]v1.Pod, 0, len(podList.Items))
+	for _, pod := range podList.Items {
+		owner := metav1.GetControllerOf(&pod)
+		if owner != nil && owner.UID == rs.UID {
+			pods = append(pods, pod)
+		}
+	}
+
+	problems := podProblems(pods)
+
+	for i := range problems {
+		if problems[i].Reason != "NotReady" {
+			continue
+		}
+		message, err := lastProbeFailure(ctx, client, deployment.Namespace, problems[i].Pod)
+		if err == nil && len(message) > 0 {
+			// Keep any other details, such as the last termination of the container.
+			problems[i].Detail = strings.Replace(problems[i].Detail, notReadyDetail, message, 1)
+		}
+	}
+
+	return problems, nil
+}
+
+// lastProbeFailure returns the message of the most recent failed probe event for a pod.
+func lastProbeFailure(ctx context.Context, client kubernetes.Interface, namespace, pod string) (string, error) {
+	events, err := client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("involvedObject.kind=Pod,involvedObject.name=%s", pod),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var latest *v1.Event
+	for i := range events.Items {
+		event := &events.Items[i]
+		if event.InvolvedObject.Name != pod || event.Reason != "Unhealthy" {
+			continue
+		}
+		if latest == nil || latest.LastTimestamp.Before(&event.LastTimestamp) {
+			latest = event
+		}
+	}
+
+	if latest == nil {
+		return "", nil
+	}
+
+	return latest.Message, nil
+}
+
+// Describe why a deployment failed to roll out, including any problems with its pods.
+func deploymentFailure(ctx context.Context, client kubernetes.Interface, deployment *apps.Deployment, reasons ...string) error {
+	problems, _ := deploymentPodProblems(ctx, client, deployment)
+	for _, problem := range problems {
+		reasons = append(reasons, problem.String())
+	}
+	return fmt.Errorf("Deployment/%s failed to roll out: %s", deployment.Name, strings.Join(reasons, "; "))
+}
@@ -0,0 +1,201 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace/noop"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func crashLoopingContainer(restarts int32) v1.ContainerStatus {
+	return v1.ContainerStatus{
+		Name:         "main",
+		RestartCount: restarts,
+		State: v1.ContainerState{
+			Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 40s restarting failed container"},
+		},
+		LastTerminationState: v1.ContainerState{
+			Terminated: &v1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137},
+		},
+	}
+}
+
+func podWithContainers(name string, statuses ...v1.ContainerStatus) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "aura",
+			Labels:    map[string]string{"app": "myapplication"},
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "ReplicaSet", Name: "myapplication-5d8f7c9b4", UID: "replicaset-uid", Controller: boolPtr(true)},
+			},
+		},
+		Status: v1.PodStatus{
+			ContainerStatuses: statuses,
+		},
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestPodProblems(t *testing.T) {
+	problems := podProblems([]v1.Pod{
+		podWithContainers("starting", v1.ContainerStatus{
+			Name:  "main",
+			State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+		}),
+		podWithContainers("healthy", v1.ContainerStatus{
+			Name:  "main",
+			Ready: true,
+			State: v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+		}),
+		podWithContainers("crashing", crashLoopingContainer(1)),
+		podWithContainers("oom", crashLoopingContainer(3)),
+		podWithContainers("image", v1.ContainerStatus{
+			Name:  "main",
+			State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: `Back-off pulling image "nginx:nonexistent"`}},
+		}),
+		podWithContainers("unready", v1.ContainerStatus{
+			Name:    "main",
+			Started: boolPtr(true),
+			State:   v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+		}),
+	})
+
+	if assert.Len(t, problems, 4) {
+		assert.Equal(t, `Pod/crashing container "main": CrashLoopBackOff (restarted 1 times, last terminated with OOMKilled, exit code 137)`, problems[0].String())
+		assert.False(t, problems[0].Fatal)
+		assert.Equal(t, `Pod/oom container "main": CrashLoopBackOff (restarted 3 times, last terminated with OOMKilled, exit code 137)`, problems[1].String())
+		assert.True(t, problems[1].Fatal)
+		assert.Equal(t, `Pod/image container "main": ImagePullBackOff (Back-off pulling image "nginx:nonexistent")`, problems[2].String())
+		assert.True(t, problems[2].Fatal)
+		assert.Equal(t, `Pod/unready container "main": NotReady (readiness probe is failing)`, problems[3].String())
+		assert.False(t, problems[3].Fatal)
+	}
+}
+
+func TestDeploymentWatchDiagnostics(t *testing.T) {
+	defer func(interval time.Duration) { podDiagnosticsInterval = interval }(podDiagnosticsInterval)
+	podDiagnosticsInterval = 10 * time.Millisecond
+
+	rolloutDeployment := func(resourceVersion string, conditions ...apps.DeploymentCondition) *apps.Deployment {
+		deployment := appsDeployment(resourceVersion, apps.DeploymentStatus{
+			ObservedGeneration: 2,
+			Replicas:           2,
+			UpdatedReplicas:    1,
+			Conditions:         conditions,
+		})
+		deployment.UID = "deployment-uid"
+		deployment.Annotations = map[string]string{revisionAnnotation: "2"}
+		deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "myapplication"}}
+		return deployment
+	}
+
+	replicaSet := &apps.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "myapplication-5d8f7c9b4",
+			Namespace:   "aura",
+			UID:         "replicaset-uid",
+			Labels:      map[string]string{"app": "myapplication"},
+			Annotations: map[string]string{revisionAnnotation: "2"},
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "Deployment", Name: "myapplication", UID: "deployment-uid", Controller: boolPtr(true)},
+			},
+		},
+		Spec: apps.ReplicaSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "myapplication"}},
+		},
+	}
+
+	t.Run("crash looping pods fail the rollout before the deadline", func(t *testing.T) {
+		pod := podWithContainers("myapplication-5d8f7c9b4-abcde", crashLoopingContainer(5))
+		clientset := fake.NewClientset(rolloutDeployment("1"), replicaSet, &pod)
+		watchers := watchReactor(clientset, "deployments")
+
+		op, cancel := newOperation(5 * time.Second)
+		defer cancel()
+		statusChan := make(chan *pb.DeploymentStatus, 16)
+		op.StatusChan = statusChan
+
+		go func() {
+			watcher := <-watchers
+			watcher.Modify(rolloutDeployment("2"))
+		}()
+
+		status := deployment{client: &fakeClient{static: clientset}}.Watch(op, deploymentResource(), noop.Span{})
+		assert.Equal(t, pb.DeploymentState_failure, status.GetState())
+		assert.Equal(t, `Deployment/myapplication failed to roll out: Pod/myapplication-5d8f7c9b4-abcde container "main": CrashLoopBackOff (restarted 5 times, last terminated with OOMKilled, exit code 137)`, status.GetMessage())
+
+		progress := <-statusChan
+		assert.Equal(t, pb.DeploymentState_in_progress, progress.GetState())
+		assert.Equal(t, `Deployment/myapplication: Pod/myapplication-5d8f7c9b4-abcde container "main": CrashLoopBackOff (restarted 5 times, last terminated with OOMKilled, exit code 137)`, progress.GetMessage())
+	})
+
+	t.Run("exceeded progress deadline fails the rollout", func(t *testing.T) {
+		pod := podWithContainers("myapplication-5d8f7c9b4-abcde", v1.ContainerStatus{
+			Name:    "main",
+			Started: boolPtr(true),
+			State:   v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+		})
+		clientset := fake.NewClientset(rolloutDeployment("1"), replicaSet, &pod, &v1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "probe", Namespace: "aura"},
+			InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: pod.Name},
+			Reason:         "Unhealthy",
+			Message:        "Readiness probe failed: HTTP probe failed with statuscode: 503",
+		})
+		watchers := watchReactor(clientset, "deployments")
+
+		op, cancel := newOperation(5 * time.Second)
+		defer cancel()
+
+		go func() {
+			watcher := <-watchers
+			watcher.Modify(rolloutDeployment("2", apps.DeploymentCondition{
+				Type:    apps.DeploymentProgressing,
+				Status:  v1.ConditionFalse,
+				Reason:  "ProgressDeadlineExceeded",
+				Message: `ReplicaSet "myapplication-5d8f7c9b4" has timed out progressing.`,
+			}))
+		}()
+
+		status := deployment{client: &fakeClient{static: clientset}}.Watch(op, deploymentResource(), noop.Span{})
+		assert.Equal(t, pb.DeploymentState_failure, status.GetState())
+		assert.Equal(t, `Deployment/myapplication failed to roll out: ProgressDeadlineExceeded: ReplicaSet "myapplication-5d8f7c9b4" has timed out progressing.; `+
+			`Pod/myapplication-5d8f7c9b4-abcde container "main": NotReady (Readiness probe failed: HTTP probe failed with statuscode: 503)`, status.GetMessage())
+	})
+
+	t.Run("probe failure is reported together with the last termination", func(t *testing.T) {
+		pod := podWithContainers("myapplication-5d8f7c9b4-abcde", v1.ContainerStatus{
+			Name:         "main",
+			Started:      boolPtr(true),
+			RestartCount: 1,
+			State:        v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+			LastTerminationState: v1.ContainerState{
+				Terminated: &v1.ContainerStateTerminated{Reason: "Error", ExitCode: 1},
+			},
+		})
+		clientset := fake.NewClientset(rolloutDeployment("1"), replicaSet, &pod, &v1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "probe", Namespace: "aura"},
+			InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: pod.Name},
+			Reason:         "Unhealthy",
+			Message:        "Readiness probe failed: connection refused",
+		})
+
+		op, cancel := newOperation(5 * time.Second)
+		defer cancel()
+
+		problems, err := deploymentPodProblems(op.Context, clientset, rolloutDeployment("2"))
+		assert.NoError(t, err)
+		if assert.Len(t, problems, 1) {
+			assert.Equal(t, `Pod/myapplication-5d8f7c9b4-abcde container "main": NotReady (Readiness probe failed: connection refused, last terminated with Error, exit code 1)`, problems[0].String())
+		}
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/nais/deploy/pkg/deployd/kubeclient"
@@ -15,6 +17,8 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 type deployment struct {
@@ -22,20 +26,25 @@ type deployment struct {
 }
 
 func (d deployment) Watch(op *operation.Operation, resource unstructured.Unstructured, trace trace.Span) *pb.DeploymentStatus {
-	var cur *apps.Deployment
-	var nova *apps.Deployment
-	var err error
 	var resourceVersion int
 	var updated bool
+	var lock sync.Mutex
+	var latest *apps.Deployment
+	var failure *pb.DeploymentStatus
 
-	client := d.client.Kubernetes().AppsV1().Deployments(resource.GetNamespace())
+	kube := d.client.Kubernetes()
+	client := kube.AppsV1().Deployments(resource.GetNamespace())
 
 	ctx, cancel := context.WithCancel(op.Context)
 	defer cancel()
 
+	watcher := newObjectWatcher(resource.GetName(), func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+		return client.List(ctx, options)
+	}, client.Watch)
+
 	// For native Kubernetes deployment objects, get the current deployment object.
 	for ctx.Err() == nil {
-		cur, err = client.Get(op.Context, resource.GetName(), metav1.GetOptions{})
+		cur, err := client.Get(op.Context, resource.GetName(), metav1.GetOptions{})
 		if err == nil {
 			resourceVersion, _ = strconv.Atoi(cur.GetResourceVersion())
 			op.Logger.Debugf("Found current deployment at version %d: %s", resourceVersion, cur.GetSelfLink())
@@ -43,29 +52,43 @@ func (d deployment) Watch(op *operation.Operation, resource unstructured.Unstruc
 			op.Logger.Debugf("Deployment '%s' in namespace '%s' is not currently present in the cluster.", resource.GetName(), resource.GetNamespace())
 		} else {
 			op.Logger.Debugf("Recoverable error while polling for deployment object: %s", err)
-			time.Sleep(requestInterval)
+			waitForPoll(ctx)
 			continue
 		}
 		break
 	}
 
-	// Wait until the new deployment object is present in the cluster.
-	for ctx.Err() == nil {
-		nova, err = client.Get(op.Context, resource.GetName(), metav1.GetOptions{})
-		if err != nil {
-			time.Sleep(requestInterval)
-			continue
+	// Wait until the new deployment object is present in the cluster, and has finished rolling out.
+	condition := func(event watch.Event) (bool, error) {
+		nova, ok := event.Object.(*apps.Deployment)
+		if !ok || event.Type == watch.Deleted || nova.GetName() != resource.GetName() {
+			return false, nil
 		}
 
 		rv, _ := strconv.Atoi(nova.GetResourceVersion())
 		if rv > resourceVersion {
-			op.Logger.Tracef("New deployment appeared at version %d: %s", rv, cur.GetSelfLink())
+			op.Logger.Tracef("New deployment appeared at version %d: %s", rv, nova.GetSelfLink())
 			resourceVersion = rv
 			updated = true
 		}
 
 		if updated && deploymentComplete(nova, &nova.Status) {
-			return pb.NewSuccessStatus(op.Request)
+			return true, nil
+		}
+
+		// Pods can only be inspected once the deployment controller has created the new replicaset.
+		if updated && nova.Status.ObservedGeneration >= nova.Generation {
+			lock.Lock()
+			latest = nova.DeepCopy()
+			lock.Unlock()
+
+			if exceeded, message := progressDeadlineExceeded(nova); exceeded {
+				err := deploymentFailure(ctx, kube, nova, "ProgressDeadlineExceeded: "+message)
+				lock.Lock()
+				failure = pb.NewFailureStatus(op.Request, err)
+				lock.Unlock()
+				return true, nil
+			}
 		}
 
 		op.Logger.WithFields(log.Fields{
@@ -75,10 +98,75 @@ func (d deployment) Watch(op *operation.Operation, resource unstructured.Unstruc
 			"deployment_observed_generation": nova.Status.ObservedGeneration,
 		}).Debugf("Still waiting for deployment to finish rollout...")
 
-		time.Sleep(requestInterval)
+		return false, nil
+	}
+
+	// Report problems with the pods of the new replicaset while waiting for the rollout,
+	// and fail without waiting for the deadline if any of them will not resolve by themselves.
+	diagnosticsDone := make(chan struct{})
+	go func() {
+		defer close(diagnosticsDone)
+
+		reported := make(map[string]bool)
+		ticker := time.NewTicker(podDiagnosticsInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			lock.Lock()
+			current := latest
+			lock.Unlock()
+			if current == nil {
+				continue
+			}
+
+			problems, err := deploymentPodProblems(ctx, kube, current)
+			if err != nil {
+				op.Logger.Debugf("Recoverable error while inspecting deployment pods: %s", err)
+				continue
+			}
+
+			fatal := make([]string, 0)
+			for _, problem := range problems {
+				message := fmt.Sprintf("Deployment/%s: %s", current.Name, problem)
+				if !reported[message] {
+					reported[message] = true
+					trace.AddEvent(message)
+					op.StatusChan <- pb.NewInProgressStatus(op.Request, "%s", message)
+				}
+				if problem.Fatal {
+					fatal = append(fatal, problem.String())
+				}
+			}
+
+			if len(fatal) > 0 {
+				lock.Lock()
+				failure = pb.NewFailureStatus(op.Request, fmt.Errorf("Deployment/%s failed to roll out: %s", current.Name, strings.Join(fatal, "; ")))
+				lock.Unlock()
+				cancel()
+				return
+			}
+		}
+	}()
+
+	err := watcher.Until(ctx, &apps.Deployment{}, nil, condition)
+	cancel()
+	<-diagnosticsDone
+
+	if failure != nil {
+		return failure
+	}
+
+	if err == nil {
+		return pb.NewSuccessStatus(op.Request)
 	}
 
-	if err != nil {
+	if err := watcher.LastError(); err != nil {
 		return pb.NewErrorStatus(op.Request, fmt.Errorf("%s; last error was: %s", ErrDeploymentTimeout, err))
 	}
 
@@ -0,0 +1,101 @@
+package strategy
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace/noop"
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func appsDeployment(resourceVersion string, status apps.DeploymentStatus) *apps.Deployment {
+	return &apps.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "myapplication",
+			Namespace:       "aura",
+			Generation:      2,
+			ResourceVersion: resourceVersion,
+		},
+		Spec: apps.DeploymentSpec{
+			Replicas: int32Ptr(2),
+		},
+		Status: status,
+	}
+}
+
+func deploymentResource() unstructured.Unstructured {
+	resource := unstructured.Unstructured{}
+	resource.SetAPIVersion("apps/v1")
+	resource.SetKind("Deployment")
+	resource.SetNamespace("aura")
+	resource.SetName("myapplication")
+	return resource
+}
+
+var completeDeploymentStatus = apps.DeploymentStatus{
+	ObservedGeneration: 2,
+	Replicas:           2,
+	UpdatedReplicas:    2,
+	AvailableReplicas:  2,
+}
+
+func TestDeploymentWatch(t *testing.T) {
+	t.Run("rollout completes when a newer version of the deployment is complete", func(t *testing.T) {
+		clientset := fake.NewClientset(appsDeployment("1", apps.DeploymentStatus{}))
+		watchers := watchReactor(clientset, "deployments")
+
+		op, cancel := newOperation(5 * time.Second)
+		defer cancel()
+
+		go func() {
+			watcher := <-watchers
+			watcher.Modify(appsDeployment("2", apps.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 1}))
+			watcher.Modify(appsDeployment("3", completeDeploymentStatus))
+		}()
+
+		status := deployment{client: &fakeClient{static: clientset}}.Watch(op, deploymentResource(), noop.Span{})
+		assert.Equal(t, pb.DeploymentState_success, status.GetState())
+		assert.Equal(t, "Deployment completed successfully.", status.GetMessage())
+	})
+
+	t.Run("existing complete deployment must be updated before rollout completes", func(t *testing.T) {
+		clientset := fake.NewClientset(appsDeployment("1", completeDeploymentStatus))
+		watchReactor(clientset, "deployments")
+
+		op, cancel := newOperation(100 * time.Millisecond)
+		defer cancel()
+
+		status := deployment{client: &fakeClient{static: clientset}}.Watch(op, deploymentResource(), noop.Span{})
+		assert.Equal(t, pb.DeploymentState_error, status.GetState())
+		assert.Equal(t, ErrDeploymentTimeout.Error(), status.GetMessage())
+	})
+
+	t.Run("watch is re-established after expiry", func(t *testing.T) {
+		clientset := fake.NewClientset(appsDeployment("1", apps.DeploymentStatus{}))
+		watchers := watchReactor(clientset, "deployments")
+
+		op, cancel := newOperation(5 * time.Second)
+		defer cancel()
+
+		go func() {
+			watcher := <-watchers
+			watcher.Error(&metav1.Status{
+				Status:  metav1.StatusFailure,
+				Code:    http.StatusGone,
+				Reason:  metav1.StatusReasonExpired,
+				Message: "too old resource version",
+			})
+			watcher = <-watchers
+			watcher.Modify(appsDeployment("2", completeDeploymentStatus))
+		}()
+
+		status := deployment{client: &fakeClient{static: clientset}}.Watch(op, deploymentResource(), noop.Span{})
+		assert.Equal(t, pb.DeploymentState_success, status.GetState())
+	})
+}
@@ -3,7 +3,6 @@ package strategy
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/nais/deploy/pkg/deployd/kubeclient"
 	"github.com/nais/deploy/pkg/deployd/operation"
@@ -12,6 +11,8 @@ import (
 	v1 "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 type job struct {
@@ -19,37 +20,44 @@ type job struct {
 }
 
 func (j job) Watch(op *operation.Operation, resource unstructured.Unstructured, trace trace.Span) *pb.DeploymentStatus {
-	var job *v1.Job
-	var err error
+	var status *pb.DeploymentStatus
 
 	client := j.client.Kubernetes().BatchV1().Jobs(resource.GetNamespace())
 
 	ctx, cancel := context.WithCancel(op.Context)
 	defer cancel()
 
-	// Wait until the new job object is present in the cluster.
-	for ctx.Err() == nil {
-		job, err = client.Get(op.Context, resource.GetName(), metav1.GetOptions{})
+	watcher := newObjectWatcher(resource.GetName(), func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+		return client.List(ctx, options)
+	}, client.Watch)
 
-		if err != nil {
-			time.Sleep(requestInterval)
-			continue
+	// Wait until the new job object is present in the cluster, and has either completed or failed.
+	condition := func(event watch.Event) (bool, error) {
+		job, ok := event.Object.(*v1.Job)
+		if !ok || event.Type == watch.Deleted || job.GetName() != resource.GetName() {
+			return false, nil
 		}
 
 		if jobComplete(job) {
-			return nil
+			return true, nil
 		}
 
-		if status, condition := jobFailed(job); status {
-			return pb.NewFailureStatus(op.Request, fmt.Errorf("job failed: %s", condition.String()))
+		if failed, condition := jobFailed(job); failed {
+			status = pb.NewFailureStatus(op.Request, fmt.Errorf("job failed: %s", condition.String()))
+			return true, nil
 		}
 
 		op.Logger.Debugf("Still waiting for job to complete...")
 
-		time.Sleep(requestInterval)
+		return false, nil
 	}
 
-	if err != nil {
+	err := watcher.Until(ctx, &v1.Job{}, nil, condition)
+	if err == nil {
+		return status
+	}
+
+	if err := watcher.LastError(); err != nil {
 		err = fmt.Errorf("%s; last error was: %w", ErrDeploymentTimeout, err)
 		trace.AddEvent(err.Error())
 		return pb.NewErrorStatus(op.Request, err)
@@ -0,0 +1,85 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace/noop"
+	batch "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func batchJob(conditions ...batch.JobCondition) *batch.Job {
+	return &batch.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "migration",
+			Namespace: "aura",
+		},
+		Status: batch.JobStatus{
+			Conditions: conditions,
+		},
+	}
+}
+
+func jobResource() unstructured.Unstructured {
+	resource := unstructured.Unstructured{}
+	resource.SetAPIVersion("batch/v1")
+	resource.SetKind("Job")
+	resource.SetNamespace("aura")
+	resource.SetName("migration")
+	return resource
+}
+
+func TestJobWatch(t *testing.T) {
+	t.Run("completed job returns no status", func(t *testing.T) {
+		clientset := fake.NewClientset(batchJob())
+		watchers := watchReactor(clientset, "jobs")
+
+		op, cancel := newOperation(5 * time.Second)
+		defer cancel()
+
+		go func() {
+			watcher := <-watchers
+			watcher.Modify(batchJob(batch.JobCondition{Type: batch.JobComplete, Status: v1.ConditionTrue}))
+		}()
+
+		status := job{client: &fakeClient{static: clientset}}.Watch(op, jobResource(), noop.Span{})
+		assert.Nil(t, status)
+	})
+
+	t.Run("failed job returns failure", func(t *testing.T) {
+		clientset := fake.NewClientset(batchJob())
+		watchers := watchReactor(clientset, "jobs")
+
+		op, cancel := newOperation(5 * time.Second)
+		defer cancel()
+
+		failed := batch.JobCondition{Type: batch.JobFailed, Status: v1.ConditionTrue, Reason: "BackoffLimitExceeded"}
+
+		go func() {
+			watcher := <-watchers
+			watcher.Modify(batchJob(failed))
+		}()
+
+		status := job{client: &fakeClient{static: clientset}}.Watch(op, jobResource(), noop.Span{})
+		assert.Equal(t, pb.DeploymentState_failure, status.GetState())
+		assert.Equal(t, "job failed: "+failed.String(), status.GetMessage())
+	})
+
+	t.Run("job that never finishes times out", func(t *testing.T) {
+		clientset := fake.NewClientset(batchJob())
+		watchReactor(clientset, "jobs")
+
+		op, cancel := newOperation(100 * time.Millisecond)
+		defer cancel()
+
+		status := job{client: &fakeClient{static: clientset}}.Watch(op, jobResource(), noop.Span{})
+		assert.Equal(t, pb.DeploymentState_error, status.GetState())
+		assert.Equal(t, ErrDeploymentTimeout.Error(), status.GetMessage())
+	})
+}
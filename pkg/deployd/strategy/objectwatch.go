@@ -0,0 +1,73 @@
+package strategy
+
+import (
+	"context"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+type listFunc func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error)
+type watchFunc func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error)
+
+// objectWatcher streams changes to a single named object from the API server.
+// The underlying informer re-lists and re-establishes the watch if it expires or the connection is dropped.
+// The last error returned by the API server is kept so that it can be reported if the rollout times out.
+type objectWatcher struct {
+	name  string
+	list  listFunc
+	watch watchFunc
+
+	lock    sync.Mutex
+	lastErr error
+}
+
+func newObjectWatcher(name string, list listFunc, watch watchFunc) *objectWatcher {
+	return &objectWatcher{
+		name:  name,
+		list:  list,
+		watch: watch,
+	}
+}
+
+// Until blocks until condition returns true for a change to the watched object, or the context expires.
+// The precondition is called once with the initial state of the object, and may be nil.
+func (w *objectWatcher) Until(ctx context.Context, objType runtime.Object, precondition watchtools.PreconditionFunc, condition watchtools.ConditionFunc) error {
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", w.name).String()
+
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			list, err := w.list(ctx, options)
+			w.setError(err)
+			return list, err
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			watcher, err := w.watch(ctx, options)
+			w.setError(err)
+			return watcher, err
+		},
+	}
+
+	_, err := watchtools.UntilWithSync(ctx, lw, objType, precondition, condition)
+	return err
+}
+
+// LastError returns the last error returned by the API server, or nil if the last request succeeded.
+func (w *objectWatcher) LastError() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.lastErr
+}
+
+func (w *objectWatcher) setError(err error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.lastErr = err
+}
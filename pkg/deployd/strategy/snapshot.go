@@ -0,0 +1,89 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// Snapshot holds the state of a resource as it was in the cluster before a deployment changed it,
+// so that the change can be reverted if the rollout fails.
+type Snapshot struct {
+	// Previous is the resource as it existed before the deployment, or nil if the deployment created it.
+	Previous *unstructured.Unstructured
+
+	client dynamic.ResourceInterface
+	name   string
+}
+
+func (s *Snapshot) record(client dynamic.ResourceInterface, name string, previous *unstructured.Unstructured) {
+	if s == nil {
+		return
+	}
+	s.client = client
+	s.name = name
+	if previous != nil {
+		s.Previous = previous.DeepCopy()
+	}
+}
+
+// Created returns true if the resource did not exist before the deployment.
+func (s *Snapshot) Created() bool {
+	return s.Previous == nil
+}
+
+// Restore reverts the resource to the state recorded in the snapshot.
+// Resources created by the deployment are deleted.
+func (s *Snapshot) Restore(ctx context.Context) error {
+	if s.client == nil {
+		return fmt.Errorf("no snapshot recorded")
+	}
+
+	if s.Created() {
+		propagation := metav1.DeletePropagationBackground
+		err := s.client.Delete(ctx, s.name, metav1.DeleteOptions{
+			PropagationPolicy: &propagation,
+		})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("delete resource: %w", err)
+		}
+		return nil
+	}
+
+	current, err := s.client.Get(ctx, s.name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		restored := s.restorable()
+		restored.SetResourceVersion("")
+		restored.SetUID("")
+		_, err = s.client.Create(ctx, restored, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("re-create resource: %w", err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("get existing resource: %w", err)
+	}
+
+	restored := s.restorable()
+	restored.SetResourceVersion(current.GetResourceVersion())
+	_, err = s.client.Update(ctx, restored, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("update resource: %w", err)
+	}
+
+	return nil
+}
+
+// Strip server-managed fields from the previous resource so that it can be written back to the cluster.
+func (s *Snapshot) restorable() *unstructured.Unstructured {
+	restored := s.Previous.DeepCopy()
+	restored.SetManagedFields(nil)
+	restored.SetGeneration(0)
+	restored.SetCreationTimestamp(metav1.Time{})
+	unstructured.RemoveNestedField(restored.Object, "status")
+	return restored
+}
@@ -0,0 +1,76 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace/noop"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.Background()
+	span := noop.Span{}
+
+	t.Run("created resources are deleted on restore", func(t *testing.T) {
+		resourceClient := newFakeDynamicClient().Resource(configMapGVR).Namespace("aura")
+		snapshot := &Snapshot{}
+		strat, err := NewDeployStrategy(CreateOrUpdate, resourceClient, snapshot)
+		assert.NoError(t, err)
+
+		_, err = strat.Deploy(ctx, *configMap("new", nil), span)
+		assert.NoError(t, err)
+		assert.True(t, snapshot.Created())
+
+		err = snapshot.Restore(ctx)
+		assert.NoError(t, err)
+
+		_, err = resourceClient.Get(ctx, "new", metav1.GetOptions{})
+		assert.True(t, errors.IsNotFound(err))
+	})
+
+	t.Run("updated resources are restored to their previous version", func(t *testing.T) {
+		existing := configMap("existing", nil)
+		existing.SetResourceVersion("42")
+		resourceClient := newFakeDynamicClient(existing).Resource(configMapGVR).Namespace("aura")
+		snapshot := &Snapshot{}
+		strat, err := NewDeployStrategy(CreateOrUpdate, resourceClient, snapshot)
+		assert.NoError(t, err)
+
+		updated := configMap("existing", nil)
+		_ = unstructured.SetNestedField(updated.Object, "baz", "data", "foo")
+		_, err = strat.Deploy(ctx, *updated, span)
+		assert.NoError(t, err)
+		assert.False(t, snapshot.Created())
+
+		err = snapshot.Restore(ctx)
+		assert.NoError(t, err)
+
+		saved, err := resourceClient.Get(ctx, "existing", metav1.GetOptions{})
+		assert.NoError(t, err)
+		value, _, _ := unstructured.NestedString(saved.Object, "data", "foo")
+		assert.Equal(t, "bar", value)
+	})
+
+	t.Run("deleted resources are re-created on restore", func(t *testing.T) {
+		resourceClient := newFakeDynamicClient().Resource(configMapGVR).Namespace("aura")
+		snapshot := &Snapshot{}
+		previous := configMap("gone", nil)
+		previous.SetResourceVersion("42")
+		snapshot.record(resourceClient, "gone", previous)
+
+		err := snapshot.Restore(ctx)
+		assert.NoError(t, err)
+
+		_, err = resourceClient.Get(ctx, "gone", metav1.GetOptions{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("restore without recorded state fails", func(t *testing.T) {
+		err := (&Snapshot{}).Restore(ctx)
+		assert.EqualError(t, err, "no snapshot recorded")
+	})
+}
@@ -0,0 +1,101 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nais/deploy/pkg/deployd/kubeclient"
+	"github.com/nais/deploy/pkg/deployd/operation"
+	"github.com/nais/deploy/pkg/pb"
+	"go.opentelemetry.io/otel/trace"
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+type statefulSet struct {
+	client kubeclient.Interface
+}
+
+func (s statefulSet) Watch(op *operation.Operation, resource unstructured.Unstructured, trace trace.Span) *pb.DeploymentStatus {
+	client := s.client.Kubernetes().AppsV1().StatefulSets(resource.GetNamespace())
+	progress := &progressReporter{op: op, trace: trace}
+
+	ctx, cancel := context.WithCancel(op.Context)
+	defer cancel()
+
+	watcher := newObjectWatcher(resource.GetName(), func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+		return client.List(ctx, options)
+	}, client.Watch)
+
+	// Wait until the statefulset is present in the cluster, and has finished rolling out.
+	condition := func(event watch.Event) (bool, error) {
+		set, ok := event.Object.(*apps.StatefulSet)
+		if !ok || event.Type == watch.Deleted || set.GetName() != resource.GetName() {
+			return false, nil
+		}
+
+		if statefulSetComplete(set) {
+			return true, nil
+		}
+
+		if set.Status.ObservedGeneration >= set.Generation {
+			progress.report("StatefulSet/%s: %d of %d replicas updated, %d ready", set.Name, set.Status.UpdatedReplicas, statefulSetReplicas(set), set.Status.ReadyReplicas)
+		}
+
+		op.Logger.Debugf("Still waiting for statefulset to finish rollout...")
+
+		return false, nil
+	}
+
+	err := watcher.Until(ctx, &apps.StatefulSet{}, nil, condition)
+	if err == nil {
+		return pb.NewSuccessStatus(op.Request)
+	}
+
+	if err := watcher.LastError(); err != nil {
+		err = fmt.Errorf("%s; last error was: %w", ErrDeploymentTimeout, err)
+		trace.AddEvent(err.Error())
+		return pb.NewErrorStatus(op.Request, err)
+	}
+
+	return pb.NewErrorStatus(op.Request, ErrDeploymentTimeout)
+}
+
+func statefulSetReplicas(set *apps.StatefulSet) int32 {
+	if set.Spec.Replicas == nil {
+		return 1
+	}
+	return *set.Spec.Replicas
+}
+
+// statefulSetComplete considers a statefulset to be complete once the controller has observed the
+// latest generation, all desired replicas are ready, and all pods are running the update revision.
+//
+// Pods are not replaced automatically with the OnDelete update strategy, so in that case
+// the statefulset is complete as soon as the controller has observed it.
+// With a partitioned rolling update, only pods with an ordinal at or above the partition are updated.
+func statefulSetComplete(set *apps.StatefulSet) bool {
+	status := set.Status
+	if status.ObservedGeneration < set.Generation {
+		return false
+	}
+
+	if set.Spec.UpdateStrategy.Type == apps.OnDeleteStatefulSetStrategyType {
+		return true
+	}
+
+	replicas := statefulSetReplicas(set)
+	if status.ReadyReplicas < replicas {
+		return false
+	}
+
+	rollingUpdate := set.Spec.UpdateStrategy.RollingUpdate
+	if rollingUpdate != nil && rollingUpdate.Partition != nil && *rollingUpdate.Partition > 0 {
+		return status.UpdatedReplicas >= replicas-*rollingUpdate.Partition
+	}
+
+	return status.UpdatedReplicas == replicas && status.CurrentRevision == status.UpdateRevision
+}
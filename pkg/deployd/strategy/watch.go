@@ -1,6 +1,7 @@
 package strategy
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -28,7 +29,41 @@ func (c NoOp) Watch(op *operation.Operation, resource unstructured.Unstructured,
 	return nil
 }
 
-func NewWatchStrategy(gvk schema.GroupVersionKind, client kubeclient.Interface) WatchStrategy {
+// waitForPoll blocks for one request interval, or until the context is done.
+// It returns false if the context is done, so that polling loops can stop immediately on timeout or cancellation.
+func waitForPoll(ctx context.Context) bool {
+	ticker := time.NewTicker(requestInterval)
+	defer ticker.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-ticker.C:
+		return true
+	}
+}
+
+// progressReporter sends rollout progress for a single resource to the status channel,
+// skipping messages that are identical to the previous one.
+type progressReporter struct {
+	op    *operation.Operation
+	trace trace.Span
+	last  string
+}
+
+func (p *progressReporter) report(format string, args ...interface{}) {
+	status := pb.NewInProgressStatus(p.op.Request, format, args...)
+	if status.Message == p.last {
+		return
+	}
+	p.last = status.Message
+	p.trace.AddEvent(status.Message)
+	p.op.StatusChan <- status
+}
+
+// NewWatchStrategy returns the strategy used to monitor the rollout of a resource of the given kind.
+// Kinds without built-in support are watched by their status conditions if they are listed in conditions.
+func NewWatchStrategy(gvk schema.GroupVersionKind, client kubeclient.Interface, conditions *ConditionWatches) WatchStrategy {
 	if gvk.Group == "nais.io" && (gvk.Kind == "Application" || gvk.Kind == "Naisjob") {
 		return naisResource{client: client}
 	}
@@ -41,5 +76,21 @@ func NewWatchStrategy(gvk schema.GroupVersionKind, client kubeclient.Interface)
 		return job{client: client}
 	}
 
+	if gvk.Group == "apps" && gvk.Kind == "StatefulSet" {
+		return statefulSet{client: client}
+	}
+
+	if gvk.Group == "apps" && gvk.Kind == "DaemonSet" {
+		return daemonSet{client: client}
+	}
+
+	if gvk.Group == "batch" && gvk.Kind == "CronJob" && gvk.Version == "v1" {
+		return cronJob{client: client}
+	}
+
+	if conditions.Matches(gvk) {
+		return conditionWatch{client: client, success: conditions.Success, failure: conditions.Failure}
+	}
+
 	return NoOp{}
 }
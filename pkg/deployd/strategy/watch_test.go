@@ -0,0 +1,293 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nais/deploy/pkg/deployd/kubeclient"
+	"github.com/nais/deploy/pkg/deployd/operation"
+	"github.com/nais/deploy/pkg/pb"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace/noop"
+	apps "k8s.io/api/apps/v1"
+	batch "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// fakeClient serves typed resources from a fake clientset, and unstructured resources of a single kind from a fake dynamic client.
+type fakeClient struct {
+	static  kubernetes.Interface
+	dynamic dynamic.Interface
+	gvr     schema.GroupVersionResource
+}
+
+var _ kubeclient.Interface = &fakeClient{}
+
+func (c *fakeClient) Kubernetes() kubernetes.Interface {
+	return c.static
+}
+
+func (c *fakeClient) ResourceInterface(resource *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	return c.dynamic.Resource(c.gvr).Namespace(resource.GetNamespace()), nil
+}
+
+func (c *fakeClient) Impersonate(team string) (kubeclient.Interface, error) {
+	return c, nil
+}
+
+// Return a new fake watcher every time the resource is watched, so that tests can simulate watch expiry.
+func watchReactor(clientset *fake.Clientset, resource string) <-chan *watch.FakeWatcher {
+	watchers := make(chan *watch.FakeWatcher, 16)
+	clientset.PrependWatchReactor(resource, func(action k8stesting.Action) (bool, watch.Interface, error) {
+		watcher := watch.NewFakeWithChanSize(16, false)
+		watchers <- watcher
+		return true, watcher, nil
+	})
+	return watchers
+}
+
+func newOperation(timeout time.Duration) (*operation.Operation, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	return &operation.Operation{
+		Context:    ctx,
+		Cancel:     cancel,
+		Logger:     log.NewEntry(log.StandardLogger()),
+		Request:    &pb.DeploymentRequest{},
+		StatusChan: make(chan *pb.DeploymentStatus, 16),
+	}, cancel
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestNewWatchStrategy(t *testing.T) {
+	assert.IsType(t, statefulSet{}, NewWatchStrategy(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "StatefulSet"}, nil, nil))
+	assert.IsType(t, daemonSet{}, NewWatchStrategy(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "DaemonSet"}, nil, nil))
+	assert.IsType(t, cronJob{}, NewWatchStrategy(schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "CronJob"}, nil, nil))
+	assert.IsType(t, NoOp{}, NewWatchStrategy(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, nil, nil))
+}
+
+func TestStatefulSetComplete(t *testing.T) {
+	newSet := func(status apps.StatefulSetStatus) *apps.StatefulSet {
+		return &apps.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Generation: 2},
+			Spec: apps.StatefulSetSpec{
+				Replicas: int32Ptr(3),
+			},
+			Status: status,
+		}
+	}
+
+	complete := apps.StatefulSetStatus{
+		ObservedGeneration: 2,
+		ReadyReplicas:      3,
+		UpdatedReplicas:    3,
+		CurrentRevision:    "web-2",
+		UpdateRevision:     "web-2",
+	}
+	assert.True(t, statefulSetComplete(newSet(complete)))
+
+	stale := complete
+	stale.ObservedGeneration = 1
+	assert.False(t, statefulSetComplete(newSet(stale)))
+
+	notReady := complete
+	notReady.ReadyReplicas = 2
+	assert.False(t, statefulSetComplete(newSet(notReady)))
+
+	oldRevision := complete
+	oldRevision.CurrentRevision = "web-1"
+	assert.False(t, statefulSetComplete(newSet(oldRevision)))
+
+	partitioned := newSet(oldRevision)
+	partitioned.Status.UpdatedReplicas = 1
+	partitioned.Spec.UpdateStrategy.RollingUpdate = &apps.RollingUpdateStatefulSetStrategy{Partition: int32Ptr(2)}
+	assert.True(t, statefulSetComplete(partitioned))
+
+	onDelete := newSet(oldRevision)
+	onDelete.Spec.UpdateStrategy.Type = apps.OnDeleteStatefulSetStrategyType
+	assert.True(t, statefulSetComplete(onDelete))
+}
+
+func TestDaemonSetComplete(t *testing.T) {
+	newSet := func(status apps.DaemonSetStatus) *apps.DaemonSet {
+		return &apps.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Generation: 2},
+			Status:     status,
+		}
+	}
+
+	complete := apps.DaemonSetStatus{
+		ObservedGeneration:     2,
+		DesiredNumberScheduled: 5,
+		UpdatedNumberScheduled: 5,
+		NumberAvailable:        5,
+	}
+	assert.True(t, daemonSetComplete(newSet(complete)))
+
+	stale := complete
+	stale.ObservedGeneration = 1
+	assert.False(t, daemonSetComplete(newSet(stale)))
+
+	updating := complete
+	updating.UpdatedNumberScheduled = 4
+	assert.False(t, daemonSetComplete(newSet(updating)))
+
+	unavailable := complete
+	unavailable.NumberAvailable = 4
+	assert.False(t, daemonSetComplete(newSet(unavailable)))
+
+	onDelete := newSet(updating)
+	onDelete.Spec.UpdateStrategy.Type = apps.OnDeleteDaemonSetStrategyType
+	assert.True(t, daemonSetComplete(onDelete))
+}
+
+func TestCronJobScheduleError(t *testing.T) {
+	since := time.Now().Truncate(time.Second)
+	cronjob := &batch.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup", UID: "1234"},
+	}
+
+	event := func(name, reason string, timestamp time.Time) v1.Event {
+		return v1.Event{
+			InvolvedObject: v1.ObjectReference{Kind: "CronJob", Name: name, UID: "1234"},
+			Type:           v1.EventTypeWarning,
+			Reason:         reason,
+			Message:        "unparseable schedule",
+			LastTimestamp:  metav1.NewTime(timestamp),
+		}
+	}
+
+	assert.Nil(t, cronJobScheduleError(cronjob, []v1.Event{
+		event("backup", "UnparseableSchedule", since.Add(-time.Minute)),
+		event("other", "UnparseableSchedule", since),
+		event("backup", "SuccessfulCreate", since),
+	}, since))
+
+	found := cronJobScheduleError(cronjob, []v1.Event{
+		event("backup", "UnparseableSchedule", since),
+	}, since)
+	if assert.NotNil(t, found) {
+		assert.Equal(t, "unparseable schedule", found.Message)
+	}
+}
+
+func appsResource(kind, name string) unstructured.Unstructured {
+	resource := unstructured.Unstructured{}
+	resource.SetAPIVersion("apps/v1")
+	resource.SetKind(kind)
+	resource.SetNamespace("aura")
+	resource.SetName(name)
+	return resource
+}
+
+func TestStatefulSetWatch(t *testing.T) {
+	newSet := func(ready int32) *apps.StatefulSet {
+		return &apps.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "aura", Generation: 2},
+			Spec:       apps.StatefulSetSpec{Replicas: int32Ptr(3)},
+			Status: apps.StatefulSetStatus{
+				ObservedGeneration: 2,
+				ReadyReplicas:      ready,
+				UpdatedReplicas:    3,
+				CurrentRevision:    "web-2",
+				UpdateRevision:     "web-2",
+			},
+		}
+	}
+
+	t.Run("completed rollout returns success", func(t *testing.T) {
+		clientset := fake.NewClientset(newSet(1))
+		watchers := watchReactor(clientset, "statefulsets")
+
+		op, cancel := newOperation(5 * time.Second)
+		defer cancel()
+		statusChan := make(chan *pb.DeploymentStatus, 16)
+		op.StatusChan = statusChan
+
+		go func() {
+			watcher := <-watchers
+			watcher.Modify(newSet(3))
+		}()
+
+		status := statefulSet{client: &fakeClient{static: clientset}}.Watch(op, appsResource("StatefulSet", "web"), noop.Span{})
+		assert.Equal(t, pb.DeploymentState_success, status.GetState())
+		assert.Equal(t, "StatefulSet/web: 3 of 3 replicas updated, 1 ready", (<-statusChan).GetMessage())
+	})
+
+	t.Run("cancelled rollout returns immediately", func(t *testing.T) {
+		clientset := fake.NewClientset(newSet(1))
+		watchReactor(clientset, "statefulsets")
+
+		op, cancel := newOperation(time.Minute)
+		cancel()
+
+		status := statefulSet{client: &fakeClient{static: clientset}}.Watch(op, appsResource("StatefulSet", "web"), noop.Span{})
+		assert.Equal(t, pb.DeploymentState_error, status.GetState())
+		assert.Equal(t, ErrDeploymentTimeout.Error(), status.GetMessage())
+	})
+}
+
+func TestDaemonSetWatch(t *testing.T) {
+	newSet := func(available int32) *apps.DaemonSet {
+		return &apps.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "aura", Generation: 2},
+			Status: apps.DaemonSetStatus{
+				ObservedGeneration:     2,
+				DesiredNumberScheduled: 2,
+				UpdatedNumberScheduled: 2,
+				NumberAvailable:        available,
+			},
+		}
+	}
+
+	clientset := fake.NewClientset(newSet(0))
+	watchers := watchReactor(clientset, "daemonsets")
+
+	op, cancel := newOperation(5 * time.Second)
+	defer cancel()
+	statusChan := make(chan *pb.DeploymentStatus, 16)
+	op.StatusChan = statusChan
+
+	go func() {
+		watcher := <-watchers
+		watcher.Modify(newSet(2))
+	}()
+
+	status := daemonSet{client: &fakeClient{static: clientset}}.Watch(op, appsResource("DaemonSet", "agent"), noop.Span{})
+	assert.Equal(t, pb.DeploymentState_success, status.GetState())
+	assert.Equal(t, "DaemonSet/agent: 2 of 2 pods updated, 0 available", (<-statusChan).GetMessage())
+}
+
+func TestCronJobWatchCancel(t *testing.T) {
+	clientset := fake.NewClientset(&batch.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "aura"},
+		Spec:       batch.CronJobSpec{Schedule: "0 3 * * *"},
+	})
+
+	resource := unstructured.Unstructured{}
+	resource.SetAPIVersion("batch/v1")
+	resource.SetKind("CronJob")
+	resource.SetNamespace("aura")
+	resource.SetName("backup")
+
+	// The watch must give up as soon as the deployment is cancelled, not after the full settle time.
+	op, cancel := newOperation(time.Minute)
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	status := cronJob{client: &fakeClient{static: clientset}}.Watch(op, resource, noop.Span{})
+	assert.Equal(t, pb.DeploymentState_error, status.GetState())
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,66 @@
+package deployserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nais/deploy/pkg/hookd/approval"
+	"github.com/nais/deploy/pkg/hookd/freeze"
+	"github.com/nais/deploy/pkg/pb"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Approve dispatches a deployment that is waiting for approval by the team of the approver.
+func (ds *deployServer) Approve(ctx context.Context, request *pb.ApprovalRequest) (*pb.DeploymentStatus, error) {
+	return ds.decide(ctx, request, true)
+}
+
+// Reject stops a deployment that is waiting for approval by the team of the approver.
+func (ds *deployServer) Reject(ctx context.Context, request *pb.ApprovalRequest) (*pb.DeploymentStatus, error) {
+	return ds.decide(ctx, request, false)
+}
+
+func (ds *deployServer) decide(ctx context.Context, request *pb.ApprovalRequest, approve bool) (*pb.DeploymentStatus, error) {
+	team := authenticatedTeam(ctx, request.GetTeam())
+	logger := log.WithFields(log.Fields{
+		pb.LogFieldCorrelationID: request.GetID(),
+		pb.LogFieldTeam:          team,
+		"approver":               request.GetApprover(),
+	})
+	logger.Infof("Received approval request (approve: %t)", approve)
+
+	// Without a team, the gate would let the caller act for any team.
+	if len(team) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "team must be specified")
+	}
+
+	st, err := ds.approvals.Decide(ctx, approval.Decision{
+		DeploymentID: request.GetID(),
+		Approve:      approve,
+		Team:         team,
+		Approver:     request.GetApprover(),
+		Comment:      request.GetComment(),
+	})
+	if err != nil {
+		return nil, approvalError(err, logger)
+	}
+
+	return st, nil
+}
+
+func approvalError(err error, logger *log.Entry) error {
+	var frozen *freeze.FrozenError
+	switch {
+	case errors.Is(err, approval.ErrApproverRequired):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, approval.ErrNotPending), errors.As(err, &frozen):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, approval.ErrNotApprover), errors.Is(err, approval.ErrSelfApproval):
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		logger.Errorf("Decide deployment approval: %s", err)
+		return ErrDatabaseUnavailable
+	}
+}
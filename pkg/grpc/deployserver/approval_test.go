@@ -0,0 +1,131 @@
+package deployserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nais/api/pkg/apiclient"
+	"github.com/nais/api/pkg/apiclient/protoapi"
+	"github.com/nais/deploy/pkg/grpc/dispatchserver"
+	"github.com/nais/deploy/pkg/hookd/approval"
+	"github.com/nais/deploy/pkg/hookd/database"
+	database_mapper "github.com/nais/deploy/pkg/hookd/database/mapper"
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDeployRequiringApproval(t *testing.T) {
+	kube, err := pb.KubernetesFromJSONResources([]byte(`[{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo","namespace":"aura"}}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiClients, apiMocks := apiclient.NewMockClient(t)
+	apiMocks.Deployments.EXPECT().CreateDeployment(mock.Anything, mock.Anything).Return(&protoapi.CreateDeploymentResponse{}, nil)
+	apiMocks.Deployments.EXPECT().CreateDeploymentK8SResource(mock.Anything, mock.Anything).Return(&protoapi.CreateDeploymentK8SResourceResponse{}, nil)
+
+	store := &database.MockDeploymentStore{}
+	store.On("WriteDeployment", mock.Anything, mock.Anything).Return(nil).Once()
+	store.On("WriteDeploymentResource", mock.Anything, mock.Anything).Return(nil).Once()
+
+	approvalStore := database.NewMockApprovalStore(t)
+	approvalStore.On("WriteDeploymentApproval", mock.Anything, mock.MatchedBy(func(a database.DeploymentApproval) bool {
+		return a.Cluster == "prod-gcp" && a.Approvers == "approvers" && a.State == database.ApprovalPending
+	})).Return(nil).Once()
+
+	// Only the pending status is reported; nothing is sent to deployd until the deployment is approved.
+	dispatcher := &dispatchserver.MockDispatchServer{}
+	dispatcher.On("HandleDeploymentStatus", mock.Anything, mock.MatchedBy(func(st *pb.DeploymentStatus) bool {
+		return st.GetState() == pb.DeploymentState_pending
+	})).Return(nil).Once()
+
+	gate := approval.NewGate(approvalStore, dispatcher, map[string]string{"prod-gcp": "approvers"})
+	ds := NewWithOptions(dispatcher, store, nil, apiClients.Deployments(), Options{Approvals: gate})
+	st, err := ds.Deploy(context.Background(), &pb.DeploymentRequest{Team: "aura", Cluster: "prod-gcp", Kubernetes: kube})
+	assert.NoError(t, err)
+	assert.Equal(t, pb.DeploymentState_pending, st.GetState())
+	assert.Contains(t, st.GetMessage(), "team 'approvers'")
+	dispatcher.AssertExpectations(t)
+	store.AssertExpectations(t)
+}
+
+func TestApprove(t *testing.T) {
+	pending, err := database_mapper.DeploymentApproval(&pb.DeploymentRequest{ID: "1", Team: "aura", Cluster: "prod-gcp", DeployerUsername: "alice"}, "aura")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name    string
+		team    string
+		request *pb.ApprovalRequest
+		code    codes.Code
+	}{
+		{"approver is required", "aura", &pb.ApprovalRequest{ID: "1"}, codes.InvalidArgument},
+		{"team is required", "", &pb.ApprovalRequest{ID: "1", Approver: "bob"}, codes.InvalidArgument},
+		{"deployer can't approve", "aura", &pb.ApprovalRequest{ID: "1", Approver: "alice"}, codes.PermissionDenied},
+		{"deploying team can't approve under another name", "aura", &pb.ApprovalRequest{ID: "1", Approver: "bob"}, codes.PermissionDenied},
+		{"other teams can't approve", "other", &pb.ApprovalRequest{ID: "1", Approver: "bob"}, codes.PermissionDenied},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			approvalStore := database.NewMockApprovalStore(t)
+			approvalStore.On("DeploymentApproval", mock.Anything, "1").Return(&pending, nil).Maybe()
+
+			ds := NewWithOptions(nil, nil, nil, nil, Options{Approvals: approval.NewGate(approvalStore, nil, nil)})
+			_, err := ds.Approve(teamContext(test.team), test.request)
+			assert.Equal(t, test.code, status.Code(err))
+		})
+	}
+
+	t.Run("deployments can't be approved during a freeze", func(t *testing.T) {
+		pending, err := database_mapper.DeploymentApproval(&pb.DeploymentRequest{ID: "1", Team: "aura", Cluster: "prod-gcp"}, "approvers")
+		if err != nil {
+			t.Fatal(err)
+		}
+		approvalStore := database.NewMockApprovalStore(t)
+		approvalStore.On("DeploymentApproval", mock.Anything, "1").Return(&pending, nil).Once()
+		freezes := database.NewMockFreezeStore(t)
+		freezes.On("ApplicableFreezes", mock.Anything, "aura", "prod-gcp", mock.Anything).Return([]*database.Freeze{{ID: "1", Reason: "incident"}}, nil).Once()
+
+		ds := NewWithOptions(nil, nil, nil, nil, Options{Approvals: approval.NewGate(approvalStore, nil, nil).WithFreezes(freezes)})
+		_, err = ds.Approve(teamContext("approvers"), &pb.ApprovalRequest{ID: "1", Approver: "bob"})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("deployments are not waiting for approval if no cluster requires it", func(t *testing.T) {
+		ds := New(nil, nil, nil, nil)
+		_, err := ds.Reject(teamContext("aura"), &pb.ApprovalRequest{ID: "1", Approver: "bob"})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+}
+
+func TestCancelPendingDeployment(t *testing.T) {
+	pending, err := database_mapper.DeploymentApproval(&pb.DeploymentRequest{ID: "1", Team: "aura", Cluster: "prod-gcp"}, "aura")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancelled := pending
+	cancelled.State = database.ApprovalCancelled
+
+	store := &database.MockDeploymentStore{}
+	store.On("Deployment", mock.Anything, "1").Return(&database.Deployment{ID: "1", Team: "aura"}, nil).Once()
+	store.On("DeploymentStatus", mock.Anything, "1").Return([]database.DeploymentStatus{{DeploymentID: "1", Status: "pending"}}, nil).Once()
+
+	approvalStore := database.NewMockApprovalStore(t)
+	approvalStore.On("DecideDeploymentApproval", mock.Anything, "1", database.ApprovalCancelled, "aura", "", mock.Anything).Return(&cancelled, nil).Once()
+
+	// deployd never saw the deployment, so it is not asked to cancel it.
+	dispatcher := &dispatchserver.MockDispatchServer{}
+	dispatcher.On("HandleDeploymentStatus", mock.Anything, mock.MatchedBy(func(st *pb.DeploymentStatus) bool {
+		return st.GetCancelled()
+	})).Return(nil).Once()
+
+	ds := NewWithOptions(dispatcher, store, nil, nil, Options{Approvals: approval.NewGate(approvalStore, dispatcher, nil)})
+	st, err := ds.Cancel(teamContext("aura"), &pb.DeploymentRequest{ID: "1"})
+	assert.NoError(t, err)
+	assert.True(t, st.GetCancelled())
+	dispatcher.AssertExpectations(t)
+}
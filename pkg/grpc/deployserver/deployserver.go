@@ -2,35 +2,74 @@ package deployserver
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/nais/api/pkg/apiclient/protoapi"
 	"github.com/nais/deploy/pkg/grpc/dispatchserver"
+	"github.com/nais/deploy/pkg/hookd/approval"
 	"github.com/nais/deploy/pkg/hookd/database"
 	database_mapper "github.com/nais/deploy/pkg/hookd/database/mapper"
+	"github.com/nais/deploy/pkg/hookd/policy"
 	"github.com/nais/deploy/pkg/k8sutils"
 	"github.com/nais/deploy/pkg/pb"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 var ErrDatabaseUnavailable = status.Errorf(codes.Unavailable, "database is unavailable; try again later")
 
+// Server serves the deploy API, and tears down preview environments once they have expired.
+type Server interface {
+	pb.DeployServer
+	ExpirePreviews(ctx context.Context, interval time.Duration)
+}
+
 type deployServer struct {
 	pb.UnimplementedDeployServer
 	dispatchServer  dispatchserver.DispatchServer
 	deploymentStore database.DeploymentStore
+	freezeStore     database.FreezeStore
+	policies        *policy.Engine
+	approvals       *approval.Gate
 	redirect        map[string]string
 	apiClient       protoapi.DeploymentsClient
+	manifestMaxSize int
+	baseURL         string
+}
+
+// Options holds the optional dependencies of the deploy server.
+// Deployment freezes, policies and approvals are not checked unless their dependency is set.
+type Options struct {
+	FreezeStore database.FreezeStore
+	Policies    *policy.Engine
+	Approvals   *approval.Gate
+	// Deployment manifests larger than this, after compression, are not stored. Zero disables storing them.
+	ManifestMaxSize int
+	// URL where hookd can be reached, used to link to the logs of earlier deployments.
+	BaseURL string
+}
+
+func New(dispatchServer dispatchserver.DispatchServer, deploymentStore database.DeploymentStore, redirect map[string]string, apiClient protoapi.DeploymentsClient) Server {
+	return NewWithOptions(dispatchServer, deploymentStore, redirect, apiClient, Options{})
 }
 
-func New(dispatchServer dispatchserver.DispatchServer, deploymentStore database.DeploymentStore, redirect map[string]string, apiClient protoapi.DeploymentsClient) pb.DeployServer {
+func NewWithOptions(dispatchServer dispatchserver.DispatchServer, deploymentStore database.DeploymentStore, redirect map[string]string, apiClient protoapi.DeploymentsClient, opts Options) Server {
 	return &deployServer{
 		deploymentStore: deploymentStore,
+		freezeStore:     opts.FreezeStore,
+		policies:        opts.Policies,
+		approvals:       opts.Approvals,
 		dispatchServer:  dispatchServer,
 		redirect:        redirect,
 		apiClient:       apiClient,
+		manifestMaxSize: opts.ManifestMaxSize,
+		baseURL:         opts.BaseURL,
 	}
 }
 
@@ -56,6 +95,11 @@ func (ds *deployServer) addToDatabase(ctx context.Context, request *pb.Deploymen
 		logger.Infof("Resource %d: %s", i+1, identifiers[i])
 	}
 
+	operation := pb.DeploymentOperation_deploy
+	if request.GetDelete() {
+		operation = pb.DeploymentOperation_undeploy
+	}
+
 	cluster := request.GetCluster()
 	deployment := database.Deployment{
 		ID:               request.GetID(),
@@ -63,6 +107,7 @@ func (ds *deployServer) addToDatabase(ctx context.Context, request *pb.Deploymen
 		Cluster:          &cluster,
 		Created:          pb.TimestampAsTime(request.GetTime()),
 		GitHubRepository: request.GetRepository().FullNamePtr(),
+		Operation:        operation.String(),
 	}
 
 	// Write deployment request to database
@@ -90,6 +135,7 @@ func (ds *deployServer) addToDatabase(ctx context.Context, request *pb.Deploymen
 				Kind:         id.Kind,
 				Name:         id.Name,
 				Namespace:    id.Namespace,
+				Deleted:      request.GetDelete(),
 			})
 
 			if err != nil {
@@ -104,6 +150,11 @@ func (ds *deployServer) addToDatabase(ctx context.Context, request *pb.Deploymen
 				}
 			}
 		}
+
+		// Undeployments only carry the identity of each resource, which is not worth comparing or deploying again.
+		if !request.GetDelete() {
+			ds.writeManifest(ctx, request, resources)
+		}
 	} else {
 		logger.Error(err)
 		return ErrDatabaseUnavailable
@@ -112,6 +163,29 @@ func (ds *deployServer) addToDatabase(ctx context.Context, request *pb.Deploymen
 	return nil
 }
 
+// Store the resources of a deployment, so that it can be compared with other deployments and made again.
+// Deployments are not stopped if the manifest can't be stored.
+func (ds *deployServer) writeManifest(ctx context.Context, request *pb.DeploymentRequest, resources []unstructured.Unstructured) {
+	logger := log.WithFields(request.LogFields())
+
+	manifest, err := database_mapper.DeploymentManifest(request, resources)
+	if err != nil {
+		logger.Errorf("Encode deployment manifest: %s", err)
+		return
+	}
+
+	size := len(manifest.Data) + len(manifest.Request)
+	if size > ds.manifestMaxSize {
+		logger.Warnf("Deployment manifest is too large to store (%d bytes compressed, limit is %d)", size, ds.manifestMaxSize)
+		return
+	}
+
+	err = ds.deploymentStore.WriteDeploymentManifest(ctx, manifest)
+	if err != nil {
+		logger.Errorf("Write deployment manifest to database: %s", err)
+	}
+}
+
 func (ds *deployServer) writeDeploymentResourceToNaisApi(ctx context.Context, naisApiDeploymentID *string, meta k8sutils.Identifier) error {
 	_, err := ds.apiClient.CreateDeploymentK8SResource(ctx, protoapi.CreateDeploymentK8SResourceRequest_builder{
 		DeploymentId: naisApiDeploymentID,
@@ -159,21 +233,64 @@ func (ds *deployServer) writeDeploymentToNaisApi(ctx context.Context, request *p
 	return &respID, nil
 }
 
+// Returns the team the client authenticated as, falling back to the team given in the request.
+func authenticatedTeam(ctx context.Context, requestTeam string) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get("team")) > 0 {
+		return md.Get("team")[0]
+	}
+	return requestTeam
+}
+
 func (ds *deployServer) Deploy(ctx context.Context, request *pb.DeploymentRequest) (*pb.DeploymentStatus, error) {
+	// Resources are only deleted through Undeploy, or by requests made by hookd itself, such as preview teardowns.
+	request.Delete = false
+	// Plans are requested through Plan, and only hookd resumes deployments after deployd restarts.
+	request.Plan = false
+	request.Resume = false
+
+	return ds.deploy(ctx, request)
+}
+
+// Give a deployment request an ID, store it and dispatch it to deployd.
+func (ds *deployServer) deploy(ctx context.Context, request *pb.DeploymentRequest) (*pb.DeploymentStatus, error) {
 	uuidstr, err := ds.uuidgen()
 	if err != nil {
 		return nil, err
 	}
 	request.ID = uuidstr
+	request.Cancel = false
 
 	logger := log.WithFields(request.LogFields())
 	logger.Infof("Received deployment request")
 
-	for requestCluster, targetCluster := range ds.redirect {
-		if request.GetCluster() == requestCluster {
-			request.Cluster = targetCluster
-			logger.Infof("Redirecting deployment from %s to %s", requestCluster, targetCluster)
-			break
+	ds.redirectCluster(request, logger)
+
+	// Preview environments torn down by hookd itself are not subject to freezes.
+	if len(request.GetPreview()) == 0 || !request.GetDelete() {
+		err = ds.checkFreezes(ctx, request, logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(request.GetPreview()) > 0 && !request.GetDelete() {
+		err = validatePreview(request)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = validateDeploySet(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deletions only carry the identity of each resource, so there is nothing to check.
+	var warnings []string
+	if !request.GetDelete() {
+		warnings, err = ds.checkPolicies(request, logger)
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -185,13 +302,33 @@ func (ds *deployServer) Deploy(ctx context.Context, request *pb.DeploymentReques
 	}
 	logger.Debugf("Deployment committed to database")
 
-	err = ds.dispatchServer.SendDeploymentRequest(ctx, request)
-	if err != nil {
-		logger.Errorf("Dispatch deployment: %s", err)
-		return nil, err
+	if len(request.GetPreview()) > 0 && !request.GetDelete() {
+		err = ds.trackPreview(ctx, request)
+		if err != nil {
+			logger.Errorf("Write preview environment to database: %s", err)
+			return nil, ErrDatabaseUnavailable
+		}
+	}
+
+	// Preview environments torn down by hookd itself don't need approval either.
+	var st *pb.DeploymentStatus
+	if approvers, required := ds.approvals.Approvers(request); required && (len(request.GetPreview()) == 0 || !request.GetDelete()) {
+		st, err = ds.approvals.Hold(ctx, request, approvers)
+		if err != nil {
+			logger.Errorf("Write deployment approval to database: %s", err)
+			return nil, ErrDatabaseUnavailable
+		}
+		logger.Infof("Deployment is waiting for approval by team '%s'", approvers)
+	} else {
+		err = ds.dispatchServer.SendDeploymentRequest(ctx, request)
+		if err != nil {
+			logger.Errorf("Dispatch deployment: %s", err)
+			return nil, err
+		}
+		st = pb.NewQueuedStatus(request)
 	}
 
-	st := pb.NewQueuedStatus(request)
+	st.Warnings = warnings
 	err = ds.dispatchServer.HandleDeploymentStatus(ctx, st)
 	if err != nil {
 		logger.Errorf("Unable to store deployment status in database: %s", err)
@@ -200,6 +337,83 @@ func (ds *deployServer) Deploy(ctx context.Context, request *pb.DeploymentReques
 	return st, nil
 }
 
+// Deploy sets are used as label values on every resource in the set.
+func validateDeploySet(request *pb.DeploymentRequest) error {
+	if len(request.GetDeploySet()) == 0 {
+		if request.GetPrune() {
+			return status.Errorf(codes.InvalidArgument, "a deploy set is required to prune resources")
+		}
+		return nil
+	}
+
+	errs := validation.IsValidLabelValue(request.GetDeploySet())
+	if len(errs) > 0 {
+		return status.Errorf(codes.InvalidArgument, "invalid deploy set %q: %s", request.GetDeploySet(), strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Send deployments for clusters that have been renamed or merged to their new cluster.
+func (ds *deployServer) redirectCluster(request *pb.DeploymentRequest, logger *log.Entry) {
+	for requestCluster, targetCluster := range ds.redirect {
+		if request.GetCluster() == requestCluster {
+			request.Cluster = targetCluster
+			logger.Infof("Redirecting deployment from %s to %s", requestCluster, targetCluster)
+			return
+		}
+	}
+}
+
+// Cancel asks deployd to stop a running deployment.
+// The final status is reported by deployd once the deployment has been stopped.
+func (ds *deployServer) Cancel(ctx context.Context, request *pb.DeploymentRequest) (*pb.DeploymentStatus, error) {
+	logger := log.WithFields(request.LogFields())
+	logger.Infof("Received cancel request")
+
+	deployment, err := ds.deploymentStore.Deployment(ctx, request.GetID())
+	if err != nil {
+		if database.IsErrNotFound(err) {
+			return nil, status.Errorf(codes.NotFound, "deployment %s not found", request.GetID())
+		}
+		logger.Errorf("Get deployment from database: %s", err)
+		return nil, ErrDatabaseUnavailable
+	}
+
+	team := authenticatedTeam(ctx, request.GetTeam())
+	if team != deployment.Team {
+		return nil, status.Errorf(codes.PermissionDenied, "deployment %s does not belong to team %q", deployment.ID, team)
+	}
+
+	dbStatus, err := ds.deploymentStore.DeploymentStatus(ctx, deployment.ID)
+	if err != nil {
+		logger.Errorf("Get deployment status from database: %s", err)
+		return nil, ErrDatabaseUnavailable
+	}
+	if len(dbStatus) > 0 && database_mapper.PbStatus(dbStatus[0]).GetState().Finished() {
+		return nil, status.Errorf(codes.FailedPrecondition, "deployment %s has already finished with status %s", deployment.ID, dbStatus[0].Status)
+	}
+
+	// Deployments waiting for approval have not been dispatched, so there is nothing for deployd to stop.
+	if len(dbStatus) > 0 && database_mapper.PbStatus(dbStatus[0]).GetState() == pb.DeploymentState_pending {
+		st, err := ds.approvals.Cancel(ctx, deployment.ID, team)
+		if err != nil {
+			return nil, approvalError(err, logger)
+		}
+		return st, nil
+	}
+
+	cancelRequest := database_mapper.PbRequest(*deployment)
+	cancelRequest.Cancel = true
+
+	err = ds.dispatchServer.SendCancelRequest(ctx, cancelRequest)
+	if err != nil {
+		logger.Errorf("Dispatch cancel request: %s", err)
+		return nil, err
+	}
+
+	return pb.NewInProgressStatus(cancelRequest, "Cancellation requested"), nil
+}
+
 func (ds *deployServer) Status(request *pb.DeploymentRequest, server pb.Deploy_StatusServer) error {
 	logger := log.WithFields(request.LogFields())
 	logger.Debugf("Status stream opened")
@@ -0,0 +1,68 @@
+package deployserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nais/api/pkg/apiclient"
+	"github.com/nais/api/pkg/apiclient/protoapi"
+	"github.com/nais/deploy/pkg/grpc/dispatchserver"
+	"github.com/nais/deploy/pkg/hookd/approval"
+	"github.com/nais/deploy/pkg/hookd/database"
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDeployClearsInternalFlags(t *testing.T) {
+	kube, err := pb.KubernetesFromJSONResources([]byte(`[{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo","namespace":"aura"}}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiClients, apiMocks := apiclient.NewMockClient(t)
+	apiMocks.Deployments.EXPECT().CreateDeployment(mock.Anything, mock.Anything).Return(&protoapi.CreateDeploymentResponse{}, nil)
+	apiMocks.Deployments.EXPECT().CreateDeploymentK8SResource(mock.Anything, mock.Anything).Return(&protoapi.CreateDeploymentK8SResourceResponse{}, nil)
+
+	store := &database.MockDeploymentStore{}
+	store.On("WriteDeployment", mock.Anything, mock.Anything).Return(nil).Once()
+	store.On("WriteDeploymentResource", mock.Anything, mock.Anything).Return(nil).Once()
+
+	dispatcher := &dispatchserver.MockDispatchServer{}
+	dispatcher.On("HandleDeploymentStatus", mock.Anything, mock.Anything).Return(nil).Once()
+	dispatcher.On("SendDeploymentRequest", mock.Anything, mock.MatchedBy(func(request *pb.DeploymentRequest) bool {
+		return !request.GetDelete() && !request.GetPlan() && !request.GetResume()
+	})).Return(nil).Once()
+
+	gate := approval.NewGate(nil, dispatcher, nil)
+	ds := NewWithOptions(dispatcher, store, nil, apiClients.Deployments(), Options{Approvals: gate})
+	st, err := ds.Deploy(context.Background(), &pb.DeploymentRequest{
+		Team:       "aura",
+		Cluster:    "dev-gcp",
+		Kubernetes: kube,
+		Delete:     true,
+		Plan:       true,
+		Resume:     true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, pb.DeploymentState_queued, st.GetState())
+	dispatcher.AssertExpectations(t)
+}
+
+func TestValidateDeploySet(t *testing.T) {
+	for _, test := range []struct {
+		request *pb.DeploymentRequest
+		code    codes.Code
+	}{
+		{&pb.DeploymentRequest{}, codes.OK},
+		{&pb.DeploymentRequest{DeploySet: "myapp"}, codes.OK},
+		{&pb.DeploymentRequest{DeploySet: "myapp", Prune: true}, codes.OK},
+		{&pb.DeploymentRequest{Prune: true}, codes.InvalidArgument},
+		{&pb.DeploymentRequest{DeploySet: "navikt/myapp"}, codes.InvalidArgument},
+	} {
+		err := validateDeploySet(test.request)
+		assert.Equal(t, test.code, status.Code(err), "deploy set %q, prune %t", test.request.GetDeploySet(), test.request.GetPrune())
+	}
+}
@@ -0,0 +1,82 @@
+package deployserver
+
+import (
+	"context"
+
+	"github.com/nais/deploy/pkg/hookd/database"
+	database_mapper "github.com/nais/deploy/pkg/hookd/database/mapper"
+	"github.com/nais/deploy/pkg/k8sutils"
+	"github.com/nais/deploy/pkg/pb"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DiffDeployments shows what changed in the resources of a deployment, compared to an earlier deployment of the same application.
+func (ds *deployServer) DiffDeployments(ctx context.Context, request *pb.DiffDeploymentsRequest) (*pb.DeploymentDiff, error) {
+	team := authenticatedTeam(ctx, request.GetTeam())
+
+	to, err := ds.teamDeployment(ctx, request.GetToID(), team)
+	if err != nil {
+		return nil, err
+	}
+
+	var from *database.Deployment
+	if len(request.GetFromID()) > 0 {
+		from, err = ds.teamDeployment(ctx, request.GetFromID(), team)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		from, err = ds.deploymentStore.PreviousDeployment(ctx, to.ID)
+		if err != nil {
+			if database.IsErrNotFound(err) {
+				return nil, status.Errorf(codes.NotFound, "no earlier deployment of the resources in deployment %s", to.ID)
+			}
+			log.WithField(pb.LogFieldDeploymentID, to.ID).Errorf("Get previous deployment from database: %s", err)
+			return nil, ErrDatabaseUnavailable
+		}
+	}
+
+	toResources, err := ds.manifestResources(ctx, to.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	fromResources, err := ds.manifestResources(ctx, from.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !k8sutils.HasCommonResources(fromResources, toResources) {
+		return nil, status.Errorf(codes.FailedPrecondition, "deployments %s and %s have no resources in common", from.ID, to.ID)
+	}
+
+	return &pb.DeploymentDiff{
+		FromID:    from.ID,
+		ToID:      to.ID,
+		Resources: k8sutils.Diff(fromResources, toResources),
+	}, nil
+}
+
+func (ds *deployServer) manifestResources(ctx context.Context, deploymentID string) ([]unstructured.Unstructured, error) {
+	logger := log.WithField(pb.LogFieldDeploymentID, deploymentID)
+
+	manifest, err := ds.deploymentStore.DeploymentManifest(ctx, deploymentID)
+	if err != nil {
+		if database.IsErrNotFound(err) {
+			return nil, status.Errorf(codes.FailedPrecondition, "resources of deployment %s are not stored; they may have expired or been too large", deploymentID)
+		}
+		logger.Errorf("Get deployment manifest from database: %s", err)
+		return nil, ErrDatabaseUnavailable
+	}
+
+	resources, err := database_mapper.ManifestResources(*manifest)
+	if err != nil {
+		logger.Errorf("Decode deployment manifest: %s", err)
+		return nil, status.Errorf(codes.Internal, "decode resources of deployment %s", deploymentID)
+	}
+
+	return resources, nil
+}
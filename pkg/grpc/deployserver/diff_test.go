@@ -0,0 +1,72 @@
+package deployserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nais/deploy/pkg/hookd/database"
+	database_mapper "github.com/nais/deploy/pkg/hookd/database/mapper"
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func manifest(t *testing.T, deploymentID string, replicas int64) *database.DeploymentManifest {
+	resource := unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]any{"name": "foo", "namespace": "aura"},
+		"spec":       map[string]any{"replicas": replicas},
+	}}
+	m, err := database_mapper.DeploymentManifest(&pb.DeploymentRequest{ID: deploymentID}, []unstructured.Unstructured{resource})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &m
+}
+
+func TestDiffDeployments(t *testing.T) {
+	previous := &database.Deployment{ID: "1", Team: "aura", Created: time.Now().Add(-time.Hour)}
+	current := &database.Deployment{ID: "2", Team: "aura", Created: time.Now()}
+
+	t.Run("compare with previous deployment", func(t *testing.T) {
+		store := &database.MockDeploymentStore{}
+		store.On("Deployment", mock.Anything, "2").Return(current, nil).Once()
+		store.On("PreviousDeployment", mock.Anything, "2").Return(previous, nil).Once()
+		store.On("DeploymentManifest", mock.Anything, "1").Return(manifest(t, "1", 2), nil).Once()
+		store.On("DeploymentManifest", mock.Anything, "2").Return(manifest(t, "2", 3), nil).Once()
+
+		ds := New(nil, store, nil, nil)
+		diff, err := ds.DiffDeployments(teamContext("aura"), &pb.DiffDeploymentsRequest{ToID: "2"})
+		assert.NoError(t, err)
+		assert.Equal(t, "1", diff.GetFromID())
+		assert.Len(t, diff.GetResources(), 1)
+		assert.Equal(t, "spec.replicas", diff.GetResources()[0].GetFields()[0].GetPath())
+		assert.Equal(t, "3", diff.GetResources()[0].GetFields()[0].GetNew())
+		store.AssertExpectations(t)
+	})
+
+	t.Run("deployment of another team", func(t *testing.T) {
+		store := &database.MockDeploymentStore{}
+		store.On("Deployment", mock.Anything, "2").Return(current, nil).Once()
+		store.On("Deployment", mock.Anything, "3").Return(&database.Deployment{ID: "3", Team: "other"}, nil).Once()
+
+		ds := New(nil, store, nil, nil)
+		_, err := ds.DiffDeployments(teamContext("aura"), &pb.DiffDeploymentsRequest{ToID: "2", FromID: "3"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("manifest has expired", func(t *testing.T) {
+		store := &database.MockDeploymentStore{}
+		store.On("Deployment", mock.Anything, "1").Return(previous, nil).Once()
+		store.On("Deployment", mock.Anything, "2").Return(current, nil).Once()
+		store.On("DeploymentManifest", mock.Anything, "2").Return(nil, database.ErrNotFound).Once()
+
+		ds := New(nil, store, nil, nil)
+		_, err := ds.DiffDeployments(teamContext("aura"), &pb.DiffDeploymentsRequest{ToID: "2", FromID: "1"})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+}
@@ -0,0 +1,32 @@
+package deployserver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nais/deploy/pkg/hookd/freeze"
+	"github.com/nais/deploy/pkg/pb"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Refuse deployments while a freeze applies to the team and cluster, unless the request overrides it with a reason.
+// Overrides are logged, and are not possible for strict freezes.
+func (ds *deployServer) checkFreezes(ctx context.Context, request *pb.DeploymentRequest, logger *log.Entry) error {
+	if ds.freezeStore == nil {
+		return nil
+	}
+
+	err := freeze.Check(ctx, ds.freezeStore, request, time.Now(), logger)
+	var frozen *freeze.FrozenError
+	if errors.As(err, &frozen) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	} else if err != nil {
+		logger.Errorf("Get deployment freezes from database: %s", err)
+		return ErrDatabaseUnavailable
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package deployserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nais/deploy/pkg/hookd/database"
+	"github.com/nais/deploy/pkg/pb"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDeployDuringFreeze(t *testing.T) {
+	store := database.NewMockFreezeStore(t)
+	store.On("ApplicableFreezes", mock.Anything, "aura", "prod-gcp", mock.Anything).Return([]*database.Freeze{
+		{ID: "1", Cluster: "prod-gcp", Reason: "Christmas"},
+	}, nil).Once()
+
+	ds := NewWithOptions(nil, nil, nil, nil, Options{FreezeStore: store})
+	_, err := ds.Deploy(context.Background(), &pb.DeploymentRequest{Team: "aura", Cluster: "prod-gcp"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "deployments to cluster 'prod-gcp' are frozen until further notice: Christmas (freeze 1)")
+}
+
+func TestCheckFreezes(t *testing.T) {
+	logger := log.WithField("test", t.Name())
+	inactive := time.Now().Add(time.Hour)
+
+	for _, test := range []struct {
+		name     string
+		freeze   database.Freeze
+		override string
+		code     codes.Code
+	}{
+		{"active freeze", database.Freeze{ID: "1", Reason: "incident"}, "", codes.FailedPrecondition},
+		{"active freeze with override", database.Freeze{ID: "1", Reason: "incident"}, "hotfix for the incident", codes.OK},
+		{"strict freeze with override", database.Freeze{ID: "1", Reason: "incident", Strict: true}, "hotfix for the incident", codes.FailedPrecondition},
+		{"freeze that has not started", database.Freeze{ID: "1", Reason: "incident", Start: &inactive}, "", codes.OK},
+		{"scheduled freeze that never matches", database.Freeze{ID: "1", Reason: "leap day", Schedule: "0 0 30 2 *", Duration: "24h"}, "", codes.OK},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			store := database.NewMockFreezeStore(t)
+			store.On("ApplicableFreezes", mock.Anything, "aura", "prod-gcp", mock.Anything).Return([]*database.Freeze{&test.freeze}, nil).Once()
+
+			ds := &deployServer{freezeStore: store}
+			err := ds.checkFreezes(context.Background(), &pb.DeploymentRequest{Team: "aura", Cluster: "prod-gcp", FreezeOverride: test.override}, logger)
+			assert.Equal(t, test.code, status.Code(err))
+		})
+	}
+}
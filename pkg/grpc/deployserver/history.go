@@ -0,0 +1,165 @@
+package deployserver
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/nais/deploy/pkg/hookd/database"
+	database_mapper "github.com/nais/deploy/pkg/hookd/database/mapper"
+	"github.com/nais/deploy/pkg/pb"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 500
+)
+
+// ListDeployments returns a page of the team's deployments, newest first.
+func (ds *deployServer) ListDeployments(ctx context.Context, request *pb.ListDeploymentsRequest) (*pb.ListDeploymentsResponse, error) {
+	team := authenticatedTeam(ctx, request.GetTeam())
+	if len(team) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "team must be specified")
+	}
+
+	limit := int(request.GetLimit())
+	switch {
+	case limit < 0:
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be a positive number")
+	case limit == 0:
+		limit = DefaultListLimit
+	case limit > MaxListLimit:
+		limit = MaxListLimit
+	}
+
+	filter := database.DeploymentFilter{
+		Teams:      []string{team},
+		Clusters:   request.GetClusters(),
+		Repository: request.GetRepository(),
+		// Fetch one more deployment than requested to find out if there is another page.
+		Limit: limit + 1,
+	}
+	for _, state := range request.GetStates() {
+		filter.States = append(filter.States, state.String())
+	}
+	if request.GetSince() != nil {
+		since := request.GetSince().AsTime()
+		filter.Since = &since
+	}
+	if request.GetUntil() != nil {
+		until := request.GetUntil().AsTime()
+		filter.Until = &until
+	}
+	if len(request.GetCursor()) > 0 {
+		cursor, err := decodeCursor(request.GetCursor())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid cursor: %s", err)
+		}
+		filter.After = cursor
+	}
+
+	deployments, err := ds.deploymentStore.ListDeployments(ctx, filter)
+	if err != nil {
+		log.Errorf("List deployments from database: %s", err)
+		return nil, ErrDatabaseUnavailable
+	}
+
+	response := &pb.ListDeploymentsResponse{}
+	if len(deployments) > limit {
+		deployments = deployments[:limit]
+		last := deployments[limit-1]
+		response.NextCursor, err = encodeCursor(&database.DeploymentCursor{
+			Created: last.Created,
+			ID:      last.ID,
+		})
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	response.Deployments = make([]*pb.Deployment, 0, len(deployments))
+	for _, deployment := range deployments {
+		response.Deployments = append(response.Deployments, database_mapper.PbDeployment(*deployment))
+	}
+
+	return response, nil
+}
+
+// GetDeployment returns a single deployment of the team, along with all its statuses and resources.
+func (ds *deployServer) GetDeployment(ctx context.Context, request *pb.GetDeploymentRequest) (*pb.Deployment, error) {
+	logger := log.WithField(pb.LogFieldDeploymentID, request.GetID())
+
+	deployment, err := ds.teamDeployment(ctx, request.GetID(), authenticatedTeam(ctx, request.GetTeam()))
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := ds.deploymentStore.DeploymentStatus(ctx, deployment.ID)
+	if err != nil && !database.IsErrNotFound(err) {
+		logger.Errorf("Get deployment status from database: %s", err)
+		return nil, ErrDatabaseUnavailable
+	}
+
+	resources, err := ds.deploymentStore.DeploymentResources(ctx, deployment.ID)
+	if err != nil {
+		logger.Errorf("Get deployment resources from database: %s", err)
+		return nil, ErrDatabaseUnavailable
+	}
+
+	result := database_mapper.PbDeployment(*deployment)
+	for _, st := range statuses {
+		result.Statuses = append(result.Statuses, database_mapper.PbStatus(st))
+	}
+	for _, resource := range resources {
+		result.Resources = append(result.Resources, database_mapper.PbKubernetesResource(resource))
+	}
+
+	return result, nil
+}
+
+// Look up a deployment, making sure it belongs to the given team.
+func (ds *deployServer) teamDeployment(ctx context.Context, id, team string) (*database.Deployment, error) {
+	deployment, err := ds.deploymentStore.Deployment(ctx, id)
+	if err != nil {
+		if database.IsErrNotFound(err) {
+			return nil, status.Errorf(codes.NotFound, "deployment %s not found", id)
+		}
+		log.WithField(pb.LogFieldDeploymentID, id).Errorf("Get deployment from database: %s", err)
+		return nil, ErrDatabaseUnavailable
+	}
+
+	if team != deployment.Team {
+		return nil, status.Errorf(codes.PermissionDenied, "deployment %s does not belong to team %q", deployment.ID, team)
+	}
+
+	return deployment, nil
+}
+
+// Cursors are opaque to clients; they encode the position of the last deployment on the previous page.
+func encodeCursor(cursor *database.DeploymentCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(encoded string) (*database.DeploymentCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	cursor := &database.DeploymentCursor{}
+	err = json.Unmarshal(data, cursor)
+	if err != nil {
+		return nil, err
+	}
+	if len(cursor.ID) == 0 || cursor.Created.IsZero() {
+		return nil, errors.New("cursor is incomplete")
+	}
+	return cursor, nil
+}
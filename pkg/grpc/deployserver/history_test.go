@@ -0,0 +1,118 @@
+package deployserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nais/deploy/pkg/hookd/database"
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func teamContext(team string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("team", team))
+}
+
+func TestListDeployments(t *testing.T) {
+	created := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	deployments := []*database.Deployment{
+		{ID: "3", Team: "aura", Created: created.Add(2 * time.Minute)},
+		{ID: "2", Team: "aura", Created: created.Add(time.Minute)},
+		{ID: "1", Team: "aura", Created: created},
+	}
+
+	t.Run("listing is scoped to the authenticated team", func(t *testing.T) {
+		store := &database.MockDeploymentStore{}
+		store.On("ListDeployments", mock.Anything, mock.MatchedBy(func(filter database.DeploymentFilter) bool {
+			return assert.ObjectsAreEqual([]string{"aura"}, filter.Teams) && filter.Limit == DefaultListLimit+1
+		})).Return(deployments, nil).Once()
+
+		ds := New(nil, store, nil, nil)
+		response, err := ds.ListDeployments(teamContext("aura"), &pb.ListDeploymentsRequest{Team: "other"})
+		assert.NoError(t, err)
+		assert.Len(t, response.GetDeployments(), 3)
+		assert.Empty(t, response.GetNextCursor())
+		store.AssertExpectations(t)
+	})
+
+	t.Run("pages are continued with a cursor", func(t *testing.T) {
+		store := &database.MockDeploymentStore{}
+		store.On("ListDeployments", mock.Anything, mock.MatchedBy(func(filter database.DeploymentFilter) bool {
+			return filter.After == nil && filter.Limit == 3
+		})).Return(deployments, nil).Once()
+
+		ds := New(nil, store, nil, nil)
+		response, err := ds.ListDeployments(teamContext("aura"), &pb.ListDeploymentsRequest{Limit: 2})
+		assert.NoError(t, err)
+		assert.Len(t, response.GetDeployments(), 2)
+		assert.NotEmpty(t, response.GetNextCursor())
+
+		store.On("ListDeployments", mock.Anything, mock.MatchedBy(func(filter database.DeploymentFilter) bool {
+			return filter.After != nil && filter.After.ID == "2" && filter.After.Created.Equal(deployments[1].Created)
+		})).Return(deployments[2:], nil).Once()
+
+		response, err = ds.ListDeployments(teamContext("aura"), &pb.ListDeploymentsRequest{Limit: 2, Cursor: response.GetNextCursor()})
+		assert.NoError(t, err)
+		assert.Len(t, response.GetDeployments(), 1)
+		assert.Empty(t, response.GetNextCursor())
+		store.AssertExpectations(t)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		ds := New(nil, &database.MockDeploymentStore{}, nil, nil)
+		_, err := ds.ListDeployments(teamContext("aura"), &pb.ListDeploymentsRequest{Cursor: "garbage"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("team is required", func(t *testing.T) {
+		ds := New(nil, &database.MockDeploymentStore{}, nil, nil)
+		_, err := ds.ListDeployments(context.Background(), &pb.ListDeploymentsRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestGetDeployment(t *testing.T) {
+	state := pb.DeploymentState_success.String()
+	deployment := &database.Deployment{ID: "1", Team: "aura", Created: time.Now(), State: &state}
+
+	t.Run("deployment of another team is not shown", func(t *testing.T) {
+		store := &database.MockDeploymentStore{}
+		store.On("Deployment", mock.Anything, "1").Return(deployment, nil).Once()
+
+		ds := New(nil, store, nil, nil)
+		_, err := ds.GetDeployment(teamContext("other"), &pb.GetDeploymentRequest{ID: "1", Team: "aura"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("deployment not found", func(t *testing.T) {
+		store := &database.MockDeploymentStore{}
+		store.On("Deployment", mock.Anything, "2").Return(nil, database.ErrNotFound).Once()
+
+		ds := New(nil, store, nil, nil)
+		_, err := ds.GetDeployment(teamContext("aura"), &pb.GetDeploymentRequest{ID: "2"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("deployment with statuses and resources", func(t *testing.T) {
+		store := &database.MockDeploymentStore{}
+		store.On("Deployment", mock.Anything, "1").Return(deployment, nil).Once()
+		store.On("DeploymentStatus", mock.Anything, "1").Return([]database.DeploymentStatus{
+			{DeploymentID: "1", Status: state, Message: "done"},
+		}, nil).Once()
+		store.On("DeploymentResources", mock.Anything, "1").Return([]database.DeploymentResource{
+			{DeploymentID: "1", Version: "v1", Kind: "ConfigMap", Name: "foo", Namespace: "aura"},
+		}, nil).Once()
+
+		ds := New(nil, store, nil, nil)
+		result, err := ds.GetDeployment(teamContext("aura"), &pb.GetDeploymentRequest{ID: "1"})
+		assert.NoError(t, err)
+		assert.Equal(t, pb.DeploymentState_success, result.GetState())
+		assert.Len(t, result.GetStatuses(), 1)
+		assert.Equal(t, "ConfigMap", result.GetResources()[0].GetKind())
+	})
+}
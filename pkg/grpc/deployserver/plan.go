@@ -0,0 +1,55 @@
+package deployserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/nais/deploy/pkg/k8sutils"
+	"github.com/nais/deploy/pkg/pb"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// How long to wait for deployd to answer a plan request, unless the request has an earlier deadline.
+const planTimeout = 2 * time.Minute
+
+// Plan asks deployd what a deployment request would change in the cluster, without deploying it.
+// deployd runs a server-side dry run as the team, so the answer includes any resources the cluster would reject.
+func (ds *deployServer) Plan(ctx context.Context, request *pb.DeploymentRequest) (*pb.DeploymentPlan, error) {
+	uuidstr, err := ds.uuidgen()
+	if err != nil {
+		return nil, err
+	}
+	request.ID = uuidstr
+	request.Team = authenticatedTeam(ctx, request.GetTeam())
+	request.Cancel = false
+	request.Resume = false
+	request.Delete = false
+	request.Plan = true
+
+	logger := log.WithFields(request.LogFields())
+	logger.Infof("Received plan request")
+
+	_, err = k8sutils.ResourcesFromDeploymentRequest(request)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid Kubernetes resources in request: %s", err)
+	}
+
+	ds.redirectCluster(request, logger)
+
+	deadline := time.Now().Add(planTimeout)
+	if request.GetDeadline() == nil || request.GetDeadline().AsTime().After(deadline) {
+		request.Deadline = pb.TimeAsTimestamp(deadline)
+	}
+	ctx, cancel := context.WithDeadline(ctx, request.GetDeadline().AsTime())
+	defer cancel()
+
+	plan, err := ds.dispatchServer.SendPlanRequest(ctx, request)
+	if err != nil {
+		logger.Errorf("Plan deployment: %s", err)
+		return nil, err
+	}
+
+	return plan, nil
+}
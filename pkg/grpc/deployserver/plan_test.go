@@ -0,0 +1,58 @@
+package deployserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nais/deploy/pkg/grpc/dispatchserver"
+	"github.com/nais/deploy/pkg/hookd/database"
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPlan(t *testing.T) {
+	kube, err := pb.KubernetesFromJSONResources([]byte(`[{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo","namespace":"aura"}}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("plan is requested from deployd as the authenticated team", func(t *testing.T) {
+		plan := &pb.DeploymentPlan{Cluster: "prod-gcp"}
+
+		// Plans are not stored; any database call fails the test.
+		store := &database.MockDeploymentStore{}
+		dispatcher := &dispatchserver.MockDispatchServer{}
+		dispatcher.On("SendPlanRequest", mock.Anything, mock.MatchedBy(func(request *pb.DeploymentRequest) bool {
+			return request.GetPlan() &&
+				len(request.GetID()) > 0 &&
+				request.GetTeam() == "aura" &&
+				request.GetCluster() == "prod-gcp" &&
+				request.GetDeadline().AsTime().Before(time.Now().Add(planTimeout+time.Second))
+		})).Return(plan, nil).Once()
+
+		ds := New(dispatcher, store, map[string]string{"prod-fss": "prod-gcp"}, nil)
+		result, err := ds.Plan(teamContext("aura"), &pb.DeploymentRequest{
+			Team:       "nais",
+			Cluster:    "prod-fss",
+			Kubernetes: kube,
+			Deadline:   pb.TimeAsTimestamp(time.Now().Add(time.Hour)),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, plan, result)
+		dispatcher.AssertExpectations(t)
+	})
+
+	t.Run("invalid resources are rejected", func(t *testing.T) {
+		invalid, err := pb.KubernetesFromJSONResources([]byte(`[{"metadata":{"name":"foo"}}]`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ds := New(&dispatchserver.MockDispatchServer{}, &database.MockDeploymentStore{}, nil, nil)
+		_, err = ds.Plan(teamContext("aura"), &pb.DeploymentRequest{Kubernetes: invalid})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
@@ -0,0 +1,62 @@
+package deployserver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nais/deploy/pkg/k8sutils"
+	"github.com/nais/deploy/pkg/pb"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Run the resources of a deployment request through the admission policies, and return violations that are only warnings.
+// If any violation is not a warning, the request is rejected with an InvalidArgument error listing all violations,
+// which are also attached as BadRequest details.
+func (ds *deployServer) checkPolicies(request *pb.DeploymentRequest, logger *log.Entry) ([]string, error) {
+	if ds.policies == nil {
+		return nil, nil
+	}
+
+	resources, err := k8sutils.ResourcesFromDeploymentRequest(request)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid Kubernetes resources in request: %s", err)
+	}
+
+	violations := ds.policies.Evaluate(request.GetTeam(), resources)
+
+	warnings := make([]string, 0)
+	rejections := make([]string, 0)
+	details := &errdetails.BadRequest{}
+	for _, violation := range violations {
+		if violation.Warning {
+			warnings = append(warnings, violation.String())
+			continue
+		}
+		rejections = append(rejections, violation.String())
+		details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       fmt.Sprintf("kubernetes.resources[%d]", violation.Index),
+			Description: violation.String(),
+		})
+	}
+
+	for _, warning := range warnings {
+		logger.Warnf("Policy violation: %s", warning)
+	}
+
+	if len(rejections) == 0 {
+		return warnings, nil
+	}
+
+	logger.Infof("Rejecting deployment request that violates %d policies", len(rejections))
+
+	st := status.Newf(codes.InvalidArgument, "deployment request violates policies:\n- %s", strings.Join(rejections, "\n- "))
+	withDetails, err := st.WithDetails(details)
+	if err == nil {
+		st = withDetails
+	}
+
+	return nil, st.Err()
+}
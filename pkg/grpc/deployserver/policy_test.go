@@ -0,0 +1,91 @@
+package deployserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nais/deploy/pkg/hookd/policy"
+	"github.com/nais/deploy/pkg/pb"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func testPolicyEngine(t *testing.T, warnOnly bool) *policy.Engine {
+	noLatest, err := policy.New(policy.Spec{
+		Name:   "no-latest",
+		Type:   policy.TypeImageTag,
+		Config: []byte(`{"disallowed":["latest"]}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	noClusterRoles, err := policy.New(policy.Spec{
+		Name:  "no-cluster-roles",
+		Type:  policy.TypeDenyKinds,
+		Kinds: []string{"ClusterRole"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return policy.NewEngine([]*policy.Policy{noClusterRoles, noLatest}, warnOnly)
+}
+
+func TestDeployViolatingPolicies(t *testing.T) {
+	kube, err := pb.KubernetesFromJSONResources([]byte(`[
+		{"apiVersion":"nais.io/v1alpha1","kind":"Application","metadata":{"name":"foo","namespace":"aura"},"spec":{"image":"ghcr.io/nais/foo:1.0"}},
+		{"apiVersion":"rbac.authorization.k8s.io/v1","kind":"ClusterRole","metadata":{"name":"admin"}}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ds := NewWithOptions(nil, nil, nil, nil, Options{Policies: testPolicyEngine(t, false)})
+	_, err = ds.Deploy(context.Background(), &pb.DeploymentRequest{Team: "aura", Cluster: "dev-gcp", Kubernetes: kube})
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Contains(t, st.Message(), "ClusterRole/admin: resources of kind ClusterRole are not allowed (policy no-cluster-roles)")
+
+	details := st.Details()
+	if assert.Len(t, details, 1) {
+		badRequest, ok := details[0].(*errdetails.BadRequest)
+		if assert.True(t, ok) && assert.Len(t, badRequest.GetFieldViolations(), 1) {
+			assert.Equal(t, "kubernetes.resources[1]", badRequest.GetFieldViolations()[0].GetField())
+		}
+	}
+}
+
+func TestCheckPolicies(t *testing.T) {
+	logger := log.WithField("test", t.Name())
+	kube, err := pb.KubernetesFromJSONResources([]byte(`[{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"foo","namespace":"aura"},"spec":{"template":{"spec":{"containers":[{"name":"foo","image":"foo:latest"}]}}}}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := &pb.DeploymentRequest{Team: "aura", Kubernetes: kube}
+	violation := `Deployment/foo: image "foo:latest" uses disallowed tag "latest" (policy no-latest)`
+
+	t.Run("violations are rejected", func(t *testing.T) {
+		ds := &deployServer{policies: testPolicyEngine(t, false)}
+		warnings, err := ds.checkPolicies(request, logger)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Contains(t, status.Convert(err).Message(), violation)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("violations are warnings in warn-only mode", func(t *testing.T) {
+		ds := &deployServer{policies: testPolicyEngine(t, true)}
+		warnings, err := ds.checkPolicies(request, logger)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{violation}, warnings)
+	})
+
+	t.Run("no policies", func(t *testing.T) {
+		ds := &deployServer{}
+		warnings, err := ds.checkPolicies(request, logger)
+		assert.NoError(t, err)
+		assert.Empty(t, warnings)
+	})
+}
@@ -0,0 +1,177 @@
+package deployserver
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/nais/deploy/pkg/hookd/database"
+	database_mapper "github.com/nais/deploy/pkg/hookd/database/mapper"
+	"github.com/nais/deploy/pkg/pb"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// How long a preview environment lives if the deployment request does not say.
+	defaultPreviewTTL = 72 * time.Hour
+	// Deadline for deleting the resources of a preview environment.
+	previewTeardownTimeout = 5 * time.Minute
+)
+
+// Preview environment names are used as label values on every resource in the environment.
+func validatePreview(request *pb.DeploymentRequest) error {
+	errs := validation.IsDNS1123Label(request.GetPreview())
+	if len(errs) > 0 {
+		return status.Errorf(codes.InvalidArgument, "invalid preview environment name %q: %s", request.GetPreview(), strings.Join(errs, "; "))
+	}
+	if request.GetPreviewExpires() == nil {
+		request.PreviewExpires = pb.TimeAsTimestamp(time.Now().Add(defaultPreviewTTL))
+	}
+	return nil
+}
+
+// Record the deployment as the latest one made to its preview environment, so that the environment can be torn down later.
+func (ds *deployServer) trackPreview(ctx context.Context, request *pb.DeploymentRequest) error {
+	var repository string
+	if request.GetRepository().Valid() {
+		repository = request.GetRepository().FullName()
+	}
+
+	return ds.deploymentStore.WritePreview(ctx, database.Preview{
+		Team:         request.GetTeam(),
+		Cluster:      request.GetCluster(),
+		Repository:   repository,
+		Name:         request.GetPreview(),
+		DeploymentID: request.GetID(),
+		Expires:      pb.TimestampAsTime(request.GetPreviewExpires()),
+	})
+}
+
+// TeardownPreview deletes all resources of a preview environment, without waiting for it to expire.
+// The resources are deleted by deployd as the team, in a deployment of its own.
+func (ds *deployServer) TeardownPreview(ctx context.Context, request *pb.TeardownPreviewRequest) (*pb.DeploymentStatus, error) {
+	target := &pb.DeploymentRequest{
+		Team:    authenticatedTeam(ctx, request.GetTeam()),
+		Cluster: request.GetCluster(),
+	}
+
+	logger := log.WithFields(target.LogFields()).WithField("preview", request.GetPreview())
+	logger.Infof("Received preview teardown request")
+
+	ds.redirectCluster(target, logger)
+
+	preview, err := ds.deploymentStore.DeletePreview(ctx, target.GetTeam(), target.GetCluster(), request.GetRepository(), request.GetPreview())
+	if err != nil {
+		if database.IsErrNotFound(err) {
+			return nil, status.Errorf(codes.NotFound, "preview environment %q not found in cluster %q", request.GetPreview(), target.GetCluster())
+		}
+		logger.Errorf("Get preview environment from database: %s", err)
+		return nil, ErrDatabaseUnavailable
+	}
+
+	return ds.teardown(ctx, *preview, request.GetDeadline(), request.GetTraceParent())
+}
+
+// ExpirePreviews tears down preview environments that have expired, and repeats every interval until the context is cancelled.
+// Every expired environment is claimed by a single hookd instance.
+func (ds *deployServer) ExpirePreviews(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		previews, err := ds.deploymentStore.DeleteExpiredPreviews(ctx, time.Now())
+		if err != nil {
+			log.Errorf("Find expired preview environments: %s", err)
+		}
+
+		for _, preview := range previews {
+			logger := log.WithFields(log.Fields{
+				pb.LogFieldTeam:    preview.Team,
+				pb.LogFieldCluster: preview.Cluster,
+				"preview":          preview.Name,
+			})
+			logger.Infof("Preview environment expired at %s; tearing it down", preview.Expires.Format(time.RFC3339))
+
+			_, err = ds.teardown(ctx, *preview, nil, "")
+			if err != nil {
+				logger.Errorf("Tear down expired preview environment: %s", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Delete the resources of every deployment made to a preview environment that is no longer tracked.
+// If the database or deployd is unavailable, the environment is tracked again, so that the teardown is retried once it has expired.
+func (ds *deployServer) teardown(ctx context.Context, preview database.Preview, deadline *timestamppb.Timestamp, traceParent string) (*pb.DeploymentStatus, error) {
+	logger := log.WithFields(log.Fields{
+		pb.LogFieldTeam:    preview.Team,
+		pb.LogFieldCluster: preview.Cluster,
+		"preview":          preview.Name,
+	})
+
+	st, err := ds.dispatchTeardown(ctx, preview, deadline, traceParent)
+	if status.Code(err) == codes.Unavailable {
+		retrackErr := ds.deploymentStore.WritePreview(ctx, preview)
+		if retrackErr != nil {
+			logger.Errorf("Track preview environment again after failed teardown: %s", retrackErr)
+		}
+	}
+
+	return st, err
+}
+
+func (ds *deployServer) dispatchTeardown(ctx context.Context, preview database.Preview, deadline *timestamppb.Timestamp, traceParent string) (*pb.DeploymentStatus, error) {
+	resources, err := ds.previewResources(ctx, preview)
+	if err != nil {
+		return nil, ErrDatabaseUnavailable
+	}
+	if len(resources) == 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "preview environment %q has no resources to delete", preview.Name)
+	}
+
+	request, err := database_mapper.TeardownRequest(preview, resources)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "build teardown request: %s", err)
+	}
+
+	if deadline == nil {
+		deadline = pb.TimeAsTimestamp(time.Now().Add(previewTeardownTimeout))
+	}
+	request.Deadline = deadline
+	request.TraceParent = traceParent
+
+	return ds.deploy(ctx, request)
+}
+
+// Resources of every deployment made to a preview environment. Resources that a later deployment dropped are
+// still part of the environment until it is torn down.
+func (ds *deployServer) previewResources(ctx context.Context, preview database.Preview) ([]database.DeploymentResource, error) {
+	deploymentIDs := append([]string{preview.DeploymentID}, preview.DeploymentIDs...)
+	seen := make(map[string]bool, len(deploymentIDs))
+	resources := make([]database.DeploymentResource, 0)
+
+	for _, deploymentID := range deploymentIDs {
+		if seen[deploymentID] {
+			continue
+		}
+		seen[deploymentID] = true
+
+		deploymentResources, err := ds.deploymentStore.DeploymentResources(ctx, deploymentID)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, deploymentResources...)
+	}
+
+	return resources, nil
+}
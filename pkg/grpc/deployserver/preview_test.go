@@ -0,0 +1,201 @@
+package deployserver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nais/api/pkg/apiclient"
+	"github.com/nais/api/pkg/apiclient/protoapi"
+	"github.com/nais/deploy/pkg/grpc/dispatchserver"
+	"github.com/nais/deploy/pkg/hookd/database"
+	"github.com/nais/deploy/pkg/k8sutils"
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDeployPreview(t *testing.T) {
+	kube, err := pb.KubernetesFromJSONResources([]byte(`[{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"foo-pr-12","namespace":"aura"}}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("preview environment is tracked", func(t *testing.T) {
+		apiClients, apiMocks := apiclient.NewMockClient(t)
+		apiMocks.Deployments.EXPECT().CreateDeployment(mock.Anything, mock.Anything).Return(&protoapi.CreateDeploymentResponse{}, nil)
+		apiMocks.Deployments.EXPECT().CreateDeploymentK8SResource(mock.Anything, mock.Anything).Return(&protoapi.CreateDeploymentK8SResourceResponse{}, nil)
+
+		store := &database.MockDeploymentStore{}
+		store.On("WriteDeployment", mock.Anything, mock.Anything).Return(nil).Once()
+		store.On("WriteDeploymentResource", mock.Anything, mock.Anything).Return(nil).Once()
+		store.On("WritePreview", mock.Anything, mock.MatchedBy(func(preview database.Preview) bool {
+			return preview.Team == "aura" &&
+				preview.Cluster == "dev-fss" &&
+				preview.Repository == "navikt/foo" &&
+				preview.Name == "pr-12" &&
+				len(preview.DeploymentID) > 0 &&
+				time.Until(preview.Expires) > defaultPreviewTTL-time.Minute
+		})).Return(nil).Once()
+
+		dispatcher := &dispatchserver.MockDispatchServer{}
+		dispatcher.On("SendDeploymentRequest", mock.Anything, mock.Anything).Return(nil).Once()
+		dispatcher.On("HandleDeploymentStatus", mock.Anything, mock.Anything).Return(nil).Once()
+
+		ds := New(dispatcher, store, nil, apiClients.Deployments())
+		_, err := ds.Deploy(context.Background(), &pb.DeploymentRequest{
+			Team:       "aura",
+			Cluster:    "dev-fss",
+			Kubernetes: kube,
+			Repository: &pb.GithubRepository{Owner: "navikt", Name: "foo"},
+			Preview:    "pr-12",
+		})
+		assert.NoError(t, err)
+		store.AssertExpectations(t)
+		dispatcher.AssertExpectations(t)
+	})
+
+	t.Run("invalid preview environment name is rejected", func(t *testing.T) {
+		ds := New(nil, nil, nil, nil)
+		_, err := ds.Deploy(context.Background(), &pb.DeploymentRequest{
+			Team:       "aura",
+			Cluster:    "dev-fss",
+			Kubernetes: kube,
+			Preview:    "PR 12",
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestTeardownPreview(t *testing.T) {
+	preview := &database.Preview{
+		Team:         "aura",
+		Cluster:      "dev-fss",
+		Repository:   "navikt/foo",
+		Name:         "pr-12",
+		DeploymentID: "1",
+		Expires:      time.Now().Add(time.Hour),
+	}
+	resources := []database.DeploymentResource{
+		{DeploymentID: "1", Group: "nais.io", Version: "v1alpha1", Kind: "Application", Name: "foo-pr-12", Namespace: "aura"},
+		{DeploymentID: "1", Version: "v1", Kind: "ConfigMap", Name: "foo-pr-12", Namespace: "aura"},
+	}
+
+	t.Run("resources of the latest deployment are deleted", func(t *testing.T) {
+		apiClients, apiMocks := apiclient.NewMockClient(t)
+		apiMocks.Deployments.EXPECT().CreateDeployment(mock.Anything, mock.Anything).Return(&protoapi.CreateDeploymentResponse{}, nil)
+		apiMocks.Deployments.EXPECT().CreateDeploymentK8SResource(mock.Anything, mock.Anything).Return(&protoapi.CreateDeploymentK8SResourceResponse{}, nil)
+
+		store := &database.MockDeploymentStore{}
+		store.On("DeletePreview", mock.Anything, "aura", "dev-fss", "navikt/foo", "pr-12").Return(preview, nil).Once()
+		store.On("DeploymentResources", mock.Anything, "1").Return(resources, nil).Once()
+		store.On("WriteDeployment", mock.Anything, mock.Anything).Return(nil).Once()
+		store.On("WriteDeploymentResource", mock.Anything, mock.Anything).Return(nil).Twice()
+
+		dispatcher := &dispatchserver.MockDispatchServer{}
+		dispatcher.On("HandleDeploymentStatus", mock.Anything, mock.Anything).Return(nil).Once()
+		dispatcher.On("SendDeploymentRequest", mock.Anything, mock.MatchedBy(func(request *pb.DeploymentRequest) bool {
+			resources, err := k8sutils.ResourcesFromDeploymentRequest(request)
+			return err == nil &&
+				request.GetDelete() &&
+				request.GetPreview() == "pr-12" &&
+				request.GetTeam() == "aura" &&
+				request.GetRepository().FullName() == "navikt/foo" &&
+				request.GetDeadline() != nil &&
+				len(resources) == 2 &&
+				resources[0].GetAPIVersion() == "nais.io/v1alpha1" &&
+				resources[1].GetAPIVersion() == "v1" &&
+				resources[1].GetName() == "foo-pr-12"
+		})).Return(nil).Once()
+
+		ds := New(dispatcher, store, nil, apiClients.Deployments())
+		st, err := ds.TeardownPreview(teamContext("aura"), &pb.TeardownPreviewRequest{
+			Cluster:    "dev-fss",
+			Repository: "navikt/foo",
+			Preview:    "pr-12",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, pb.DeploymentState_queued, st.GetState())
+		store.AssertExpectations(t)
+		dispatcher.AssertExpectations(t)
+	})
+
+	t.Run("resources dropped by a later deployment are deleted too", func(t *testing.T) {
+		apiClients, apiMocks := apiclient.NewMockClient(t)
+		apiMocks.Deployments.EXPECT().CreateDeployment(mock.Anything, mock.Anything).Return(&protoapi.CreateDeploymentResponse{}, nil)
+		apiMocks.Deployments.EXPECT().CreateDeploymentK8SResource(mock.Anything, mock.Anything).Return(&protoapi.CreateDeploymentK8SResourceResponse{}, nil)
+
+		redeployed := *preview
+		redeployed.DeploymentID = "2"
+		redeployed.DeploymentIDs = []string{"1", "2"}
+
+		store := &database.MockDeploymentStore{}
+		store.On("DeletePreview", mock.Anything, "aura", "dev-fss", "navikt/foo", "pr-12").Return(&redeployed, nil).Once()
+		store.On("DeploymentResources", mock.Anything, "2").Return(resources[:1], nil).Once()
+		store.On("DeploymentResources", mock.Anything, "1").Return(resources, nil).Once()
+		store.On("WriteDeployment", mock.Anything, mock.Anything).Return(nil).Once()
+		store.On("WriteDeploymentResource", mock.Anything, mock.Anything).Return(nil).Twice()
+
+		dispatcher := &dispatchserver.MockDispatchServer{}
+		dispatcher.On("HandleDeploymentStatus", mock.Anything, mock.Anything).Return(nil).Once()
+		dispatcher.On("SendDeploymentRequest", mock.Anything, mock.MatchedBy(func(request *pb.DeploymentRequest) bool {
+			resources, err := k8sutils.ResourcesFromDeploymentRequest(request)
+			return err == nil &&
+				len(resources) == 2 &&
+				resources[0].GetKind() == "Application" &&
+				resources[1].GetKind() == "ConfigMap"
+		})).Return(nil).Once()
+
+		ds := New(dispatcher, store, nil, apiClients.Deployments())
+		_, err := ds.TeardownPreview(teamContext("aura"), &pb.TeardownPreviewRequest{
+			Cluster:    "dev-fss",
+			Repository: "navikt/foo",
+			Preview:    "pr-12",
+		})
+		assert.NoError(t, err)
+		store.AssertExpectations(t)
+		dispatcher.AssertExpectations(t)
+	})
+
+	t.Run("unknown preview environment", func(t *testing.T) {
+		store := &database.MockDeploymentStore{}
+		store.On("DeletePreview", mock.Anything, "aura", "dev-fss", "", "pr-12").Return(nil, database.ErrNotFound).Once()
+
+		ds := New(nil, store, nil, nil)
+		_, err := ds.TeardownPreview(teamContext("aura"), &pb.TeardownPreviewRequest{Cluster: "dev-fss", Preview: "pr-12"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("preview environment is tracked again if the database is unavailable", func(t *testing.T) {
+		store := &database.MockDeploymentStore{}
+		store.On("DeletePreview", mock.Anything, "aura", "dev-fss", "navikt/foo", "pr-12").Return(preview, nil).Once()
+		store.On("DeploymentResources", mock.Anything, "1").Return(nil, fmt.Errorf("connection refused")).Once()
+		store.On("WritePreview", mock.Anything, *preview).Return(nil).Once()
+
+		ds := New(nil, store, nil, nil)
+		_, err := ds.TeardownPreview(teamContext("aura"), &pb.TeardownPreviewRequest{Cluster: "dev-fss", Repository: "navikt/foo", Preview: "pr-12"})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		store.AssertExpectations(t)
+	})
+}
+
+func TestExpirePreviews(t *testing.T) {
+	store := &database.MockDeploymentStore{}
+	store.On("DeleteExpiredPreviews", mock.Anything, mock.Anything).Return([]*database.Preview{{
+		Team:         "aura",
+		Cluster:      "dev-fss",
+		Name:         "pr-12",
+		DeploymentID: "1",
+	}}, nil).Once()
+	store.On("DeploymentResources", mock.Anything, "1").Return([]database.DeploymentResource{}, nil).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ds := New(nil, store, nil, nil)
+	ds.ExpirePreviews(ctx, time.Hour)
+	store.AssertExpectations(t)
+}
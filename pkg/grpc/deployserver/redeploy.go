@@ -0,0 +1,80 @@
+package deployserver
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nais/deploy/pkg/hookd/database"
+	database_mapper "github.com/nais/deploy/pkg/hookd/database/mapper"
+	"github.com/nais/deploy/pkg/pb"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Redeploy makes a previous deployment again, using the resources and metadata stored when it was first made.
+// The new deployment gets its own ID and deadline, and is dispatched like any other deployment.
+// Its trigger URL links to the logs of the original deployment.
+func (ds *deployServer) Redeploy(ctx context.Context, request *pb.RedeployRequest) (*pb.DeploymentStatus, error) {
+	logger := log.WithField(pb.LogFieldDeploymentID, request.GetID())
+	logger.Infof("Received redeploy request")
+
+	original, err := ds.teamDeployment(ctx, request.GetID(), authenticatedTeam(ctx, request.GetTeam()))
+	if err != nil {
+		return nil, err
+	}
+	if original.Operation == pb.DeploymentOperation_undeploy.String() {
+		return nil, status.Errorf(codes.FailedPrecondition, "deployment %s deleted its resources, and can't be redeployed", original.ID)
+	}
+
+	manifest, err := ds.deploymentStore.DeploymentManifest(ctx, original.ID)
+	if err != nil && !database.IsErrNotFound(err) {
+		logger.Errorf("Get deployment manifest from database: %s", err)
+		return nil, ErrDatabaseUnavailable
+	}
+
+	var originalRequest *pb.DeploymentRequest
+	if manifest != nil {
+		originalRequest, err = database_mapper.ManifestRequest(*manifest)
+		if err != nil {
+			logger.Errorf("Decode stored deployment request: %s", err)
+			return nil, status.Errorf(codes.Internal, "decode stored request of deployment %s", original.ID)
+		}
+	}
+	if originalRequest == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "deployment %s can't be redeployed; its resources are not stored, they may have expired or been too large", original.ID)
+	}
+
+	now := time.Now()
+	deadline := request.GetDeadline()
+	if deadline == nil {
+		timeout := originalRequest.GetDeadline().AsTime().Sub(originalRequest.GetTime().AsTime())
+		deadline = pb.TimeAsTimestamp(now.Add(timeout))
+	}
+
+	redeploy := proto.Clone(originalRequest).(*pb.DeploymentRequest)
+	redeploy.Time = pb.TimeAsTimestamp(now)
+	redeploy.Deadline = deadline
+	redeploy.TraceParent = request.GetTraceParent()
+	redeploy.Resume = false
+	redeploy.TriggerUrl = ds.deploymentLogsURL(original.ID, original.Created, originalRequest.GetCluster())
+	redeploy.FreezeOverride = request.GetFreezeOverride()
+
+	logger.Infof("Redeploying deployment to cluster '%s'", redeploy.GetCluster())
+
+	return ds.deploy(ctx, redeploy)
+}
+
+// Link to the logs of a deployment, through the log proxy of hookd.
+func (ds *deployServer) deploymentLogsURL(deploymentID string, created time.Time, cluster string) string {
+	query := url.Values{}
+	query.Set("delivery_id", deploymentID)
+	query.Set("ts", strconv.FormatInt(created.Unix(), 10))
+	query.Set("v", "1")
+	query.Set("cluster", cluster)
+	return strings.TrimSuffix(ds.baseURL, "/") + "/logs?" + query.Encode()
+}
@@ -0,0 +1,108 @@
+package deployserver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nais/api/pkg/apiclient"
+	"github.com/nais/api/pkg/apiclient/protoapi"
+	"github.com/nais/deploy/pkg/grpc/dispatchserver"
+	"github.com/nais/deploy/pkg/hookd/database"
+	database_mapper "github.com/nais/deploy/pkg/hookd/database/mapper"
+	"github.com/nais/deploy/pkg/k8sutils"
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRedeploy(t *testing.T) {
+	created := time.Now().Add(-24 * time.Hour)
+	kube, err := pb.KubernetesFromJSONResources([]byte(`[{"apiVersion":"v1","kind":"Secret","metadata":{"name":"foo","namespace":"aura"},"stringData":{"password":"hunter2"}}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	originalRequest := &pb.DeploymentRequest{
+		ID:         "1",
+		Team:       "aura",
+		Cluster:    "dev-fss",
+		Time:       pb.TimeAsTimestamp(created),
+		Deadline:   pb.TimeAsTimestamp(created.Add(5 * time.Minute)),
+		Kubernetes: kube,
+		TriggerUrl: "https://github.com/navikt/foo/actions/runs/1",
+	}
+	resources, err := k8sutils.ResourcesFromDeploymentRequest(originalRequest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := database_mapper.DeploymentManifest(originalRequest, resources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := &database.Deployment{ID: "1", Team: "aura", Created: created}
+	logsURL := fmt.Sprintf("https://deploy.example.com/logs?cluster=dev-fss&delivery_id=1&ts=%d&v=1", created.Unix())
+
+	t.Run("previous deployment is dispatched as a new deployment", func(t *testing.T) {
+		apiClients, apiMocks := apiclient.NewMockClient(t)
+		apiMocks.Deployments.EXPECT().CreateDeployment(mock.Anything, mock.Anything).Return(&protoapi.CreateDeploymentResponse{}, nil)
+		apiMocks.Deployments.EXPECT().CreateDeploymentK8SResource(mock.Anything, mock.Anything).Return(&protoapi.CreateDeploymentK8SResourceResponse{}, nil)
+
+		store := &database.MockDeploymentStore{}
+		store.On("Deployment", mock.Anything, "1").Return(original, nil).Once()
+		store.On("DeploymentManifest", mock.Anything, "1").Return(&manifest, nil).Once()
+		store.On("WriteDeployment", mock.Anything, mock.Anything).Return(nil).Once()
+		store.On("WriteDeploymentResource", mock.Anything, mock.Anything).Return(nil).Once()
+
+		dispatcher := &dispatchserver.MockDispatchServer{}
+		dispatcher.On("HandleDeploymentStatus", mock.Anything, mock.Anything).Return(nil).Once()
+		dispatcher.On("SendDeploymentRequest", mock.Anything, mock.MatchedBy(func(request *pb.DeploymentRequest) bool {
+			resources, err := k8sutils.ResourcesFromDeploymentRequest(request)
+			return request.GetID() != "1" &&
+				request.GetCluster() == "dev-fss" &&
+				request.GetTriggerUrl() == logsURL &&
+				request.GetDeadline().AsTime().Sub(request.GetTime().AsTime()) == 5*time.Minute &&
+				time.Since(request.GetTime().AsTime()) < time.Minute &&
+				err == nil && resources[0].Object["stringData"].(map[string]any)["password"] == "hunter2"
+		})).Return(nil).Once()
+
+		ds := NewWithOptions(dispatcher, store, nil, apiClients.Deployments(), Options{BaseURL: "https://deploy.example.com/"})
+		st, err := ds.Redeploy(teamContext("aura"), &pb.RedeployRequest{ID: "1"})
+		assert.NoError(t, err)
+		assert.Equal(t, pb.DeploymentState_queued, st.GetState())
+		assert.NotEqual(t, "1", st.GetRequest().GetID())
+		dispatcher.AssertExpectations(t)
+		store.AssertExpectations(t)
+	})
+
+	t.Run("deployment of another team can't be redeployed", func(t *testing.T) {
+		store := &database.MockDeploymentStore{}
+		store.On("Deployment", mock.Anything, "1").Return(original, nil).Once()
+
+		ds := New(nil, store, nil, nil)
+		_, err := ds.Redeploy(teamContext("other"), &pb.RedeployRequest{ID: "1", Team: "aura"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("deployment without stored request can't be redeployed", func(t *testing.T) {
+		store := &database.MockDeploymentStore{}
+		store.On("Deployment", mock.Anything, "1").Return(original, nil).Once()
+		store.On("DeploymentManifest", mock.Anything, "1").Return(&database.DeploymentManifest{DeploymentID: "1", Data: manifest.Data}, nil).Once()
+
+		ds := New(nil, store, nil, nil)
+		_, err := ds.Redeploy(context.Background(), &pb.RedeployRequest{ID: "1", Team: "aura"})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("undeployment can't be redeployed", func(t *testing.T) {
+		store := &database.MockDeploymentStore{}
+		store.On("Deployment", mock.Anything, "1").Return(&database.Deployment{ID: "1", Team: "aura", Created: created, Operation: "undeploy"}, nil).Once()
+
+		ds := New(nil, store, nil, nil)
+		_, err := ds.Redeploy(context.Background(), &pb.RedeployRequest{ID: "1", Team: "aura"})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		store.AssertExpectations(t)
+	})
+}
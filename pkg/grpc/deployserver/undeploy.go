@@ -0,0 +1,43 @@
+package deployserver
+
+import (
+	"context"
+
+	"github.com/nais/deploy/pkg/k8sutils"
+	"github.com/nais/deploy/pkg/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Undeploy deletes the resources in a deployment request, as the team's service user.
+// Only the identity of each resource is kept, so that secrets in the resource files are neither stored nor sent to deployd.
+func (ds *deployServer) Undeploy(ctx context.Context, request *pb.DeploymentRequest) (*pb.DeploymentStatus, error) {
+	if len(request.GetPreview()) > 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "preview environments are removed with TeardownPreview")
+	}
+
+	resources, err := k8sutils.ResourcesFromDeploymentRequest(request)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid Kubernetes resources in request: %s", err)
+	}
+	if len(resources) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "no resources to delete")
+	}
+
+	identifiers := k8sutils.Identifiers(resources)
+	for i, id := range identifiers {
+		if len(id.Kind) == 0 || len(id.Name) == 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "resource %d: kind and name are required", i+1)
+		}
+	}
+
+	request.Kubernetes, err = k8sutils.KubernetesFromIdentifiers(identifiers)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid Kubernetes resources in request: %s", err)
+	}
+	request.Delete = true
+	request.DeploySet = ""
+	request.Prune = false
+
+	return ds.deploy(ctx, request)
+}
@@ -0,0 +1,87 @@
+package deployserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nais/api/pkg/apiclient"
+	"github.com/nais/api/pkg/apiclient/protoapi"
+	"github.com/nais/deploy/pkg/grpc/dispatchserver"
+	"github.com/nais/deploy/pkg/hookd/database"
+	"github.com/nais/deploy/pkg/k8sutils"
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUndeploy(t *testing.T) {
+	kube, err := pb.KubernetesFromJSONResources([]byte(`[{"apiVersion":"v1","kind":"Secret","metadata":{"name":"foo","namespace":"aura"},"stringData":{"password":"hunter2"}}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("resources are reduced to their identity and dispatched for deletion", func(t *testing.T) {
+		apiClients, apiMocks := apiclient.NewMockClient(t)
+		apiMocks.Deployments.EXPECT().CreateDeployment(mock.Anything, mock.Anything).Return(&protoapi.CreateDeploymentResponse{}, nil)
+		apiMocks.Deployments.EXPECT().CreateDeploymentK8SResource(mock.Anything, mock.Anything).Return(&protoapi.CreateDeploymentK8SResourceResponse{}, nil)
+
+		store := &database.MockDeploymentStore{}
+		store.On("WriteDeployment", mock.Anything, mock.MatchedBy(func(deployment database.Deployment) bool {
+			return deployment.Operation == "undeploy"
+		})).Return(nil).Once()
+		store.On("WriteDeploymentResource", mock.Anything, mock.MatchedBy(func(resource database.DeploymentResource) bool {
+			return resource.Deleted && resource.Kind == "Secret" && resource.Name == "foo"
+		})).Return(nil).Once()
+
+		dispatcher := &dispatchserver.MockDispatchServer{}
+		dispatcher.On("HandleDeploymentStatus", mock.Anything, mock.Anything).Return(nil).Once()
+		dispatcher.On("SendDeploymentRequest", mock.Anything, mock.MatchedBy(func(request *pb.DeploymentRequest) bool {
+			resources, err := k8sutils.ResourcesFromDeploymentRequest(request)
+			return err == nil &&
+				request.GetDelete() &&
+				!request.GetPrune() &&
+				len(resources) == 1 &&
+				resources[0].GetKind() == "Secret" &&
+				resources[0].GetName() == "foo" &&
+				resources[0].GetNamespace() == "aura" &&
+				resources[0].Object["stringData"] == nil
+		})).Return(nil).Once()
+
+		ds := New(dispatcher, store, nil, apiClients.Deployments())
+		st, err := ds.Undeploy(context.Background(), &pb.DeploymentRequest{
+			Team:       "aura",
+			Cluster:    "dev-fss",
+			Kubernetes: kube,
+			DeploySet:  "foo",
+			Prune:      true,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, pb.DeploymentState_queued, st.GetState())
+		dispatcher.AssertExpectations(t)
+		store.AssertExpectations(t)
+	})
+
+	t.Run("request without resources is rejected", func(t *testing.T) {
+		ds := New(nil, nil, nil, nil)
+		_, err := ds.Undeploy(context.Background(), &pb.DeploymentRequest{Team: "aura", Cluster: "dev-fss", Kubernetes: &pb.Kubernetes{}})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("resource without name is rejected", func(t *testing.T) {
+		kube, err := pb.KubernetesFromJSONResources([]byte(`[{"apiVersion":"v1","kind":"Secret","metadata":{"namespace":"aura"}}]`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ds := New(nil, nil, nil, nil)
+		_, err = ds.Undeploy(context.Background(), &pb.DeploymentRequest{Team: "aura", Cluster: "dev-fss", Kubernetes: kube})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("preview environments are not undeployed", func(t *testing.T) {
+		ds := New(nil, nil, nil, nil)
+		_, err := ds.Undeploy(context.Background(), &pb.DeploymentRequest{Team: "aura", Cluster: "dev-fss", Kubernetes: kube, Preview: "pr-1"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
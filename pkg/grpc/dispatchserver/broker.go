@@ -4,8 +4,11 @@ package dispatchserver
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/nais/api/pkg/apiclient/protoapi"
 	"github.com/nais/deploy/pkg/hookd/database"
 	database_mapper "github.com/nais/deploy/pkg/hookd/database/mapper"
@@ -18,14 +21,28 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-func (s *dispatchServer) SendDeploymentRequest(ctx context.Context, request *pb.DeploymentRequest) error {
-	s.onlineClustersLock.RLock()
-	c, online := s.onlineClustersMap[request.Cluster]
-	s.onlineClustersLock.RUnlock()
-	if !online {
-		return status.Errorf(codes.Unavailable, "cluster '%s' is offline", request.Cluster)
+var errClusterOffline = errors.New("cluster is not connected to this instance")
+
+// Send a deployment request to one of the deployd instances connected to this hookd instance for the request's cluster.
+// Returns errClusterOffline if there are none.
+func (s *dispatchServer) dispatch(ctx context.Context, request *pb.DeploymentRequest) error {
+	for {
+		conn := s.pickConnection(request.GetCluster(), request.GetTeam())
+		if conn == nil {
+			return errClusterOffline
+		}
+		err := conn.send(ctx, request)
+		if errors.Is(err, errConnectionClosed) {
+			continue
+		}
+		return err
 	}
+}
 
+// SendDeploymentRequest sends a deployment request to a deployd instance connected for the request's cluster.
+// If the cluster is not connected to this hookd instance, the request is queued in the database,
+// and the instance holding the cluster's connection is notified. Otherwise, it stays queued until the cluster connects.
+func (s *dispatchServer) SendDeploymentRequest(ctx context.Context, request *pb.DeploymentRequest) error {
 	ctx = telemetry.WithTraceParent(ctx, request.TraceParent)
 	s.traceSpansLock.Lock()
 	ctx, span := telemetry.Tracer().Start(ctx, "Deploy", otrace.WithSpanKind(otrace.SpanKindServer))
@@ -33,9 +50,18 @@ func (s *dispatchServer) SendDeploymentRequest(ctx context.Context, request *pb.
 	request.TraceParent = telemetry.TraceParentHeader(ctx)
 	s.traceSpansLock.Unlock()
 
-	wait := make(chan error, 1)
-	c <- &requestWithWait{request: request, wait: wait}
-	if err := <-wait; err != nil {
+	err := s.dispatch(ctx, request)
+	if errors.Is(err, errClusterOffline) {
+		err = s.queueDeploymentRequest(ctx, request)
+		if err != nil {
+			span.End()
+			s.traceSpansLock.Lock()
+			delete(s.traceSpans, request.ID)
+			s.traceSpansLock.Unlock()
+		}
+		return err
+	}
+	if err != nil {
 		span.End()
 		s.traceSpansLock.Lock()
 		delete(s.traceSpans, request.ID)
@@ -48,13 +74,128 @@ func (s *dispatchServer) SendDeploymentRequest(ctx context.Context, request *pb.
 	return nil
 }
 
-func (s *dispatchServer) HandleDeploymentStatus(ctx context.Context, st *pb.DeploymentStatus) error {
-	s.statusStreamsLock.RLock()
-	for _, ch := range s.statusStreams {
-		ch <- st
+func (s *dispatchServer) queueDeploymentRequest(ctx context.Context, request *pb.DeploymentRequest) error {
+	queued, err := database_mapper.QueuedDeploymentRequest(request)
+	if err != nil {
+		return status.Errorf(codes.Internal, "serialize deployment request: %s", err)
+	}
+
+	err = s.db.QueueDeploymentRequest(ctx, queued)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "cluster '%s' is offline, and the deployment request could not be queued: %s", request.Cluster, err)
+	}
+
+	logger := log.WithFields(request.LogFields())
+	logger.Infof("Cluster '%s' is not connected to this instance; deployment request queued", request.Cluster)
+
+	err = s.notifier.Notify(ctx, database.ChannelDeploymentQueue, request.Cluster)
+	if err != nil {
+		logger.Errorf("Notify other instances of queued deployment request: %s", err)
+	}
+
+	return nil
+}
+
+// SendCancelRequest asks the deployd instance running the deployment to cancel it.
+// The request must have its Cancel flag set; no status is written until deployd reports back.
+// Deployments still queued in the database are removed from the queue and cancelled immediately.
+// If the deployment is not running on a deployd instance connected to this hookd instance,
+// the request is forwarded to all hookd instances.
+func (s *dispatchServer) SendCancelRequest(ctx context.Context, request *pb.DeploymentRequest) error {
+	conn := s.runningConnection(request)
+	if conn != nil {
+		return sendCancel(ctx, conn, request)
+	}
+
+	err := s.db.DeleteQueuedDeploymentRequest(ctx, request.ID)
+	if err == nil {
+		return s.HandleDeploymentStatus(ctx, pb.NewCancelledStatus(request))
+	}
+	if !database.IsErrNotFound(err) {
+		log.WithFields(request.LogFields()).Errorf("Remove deployment request from queue: %s", err)
+	}
+
+	return s.publishCancel(ctx, request)
+}
+
+func sendCancel(ctx context.Context, conn *clusterConnection, request *pb.DeploymentRequest) error {
+	err := conn.send(ctx, request)
+	if err != nil {
+		if ctx.Err() != nil {
+			return status.Errorf(codes.DeadlineExceeded, "send cancel request: %s", err)
+		}
+		return fmt.Errorf("send cancel request: %w", err)
+	}
+
+	log.WithFields(request.LogFields()).Debugf("Cancel request sent to deployd on %s", conn)
+
+	return nil
+}
+
+// Send the unfinished deployment requests of a deployd instance that has disconnected to another instance.
+// If no other instance is connected to this hookd instance, they are queued until one connects.
+// The instance is first given some time to reconnect, as it keeps running its deployments if only the stream was lost.
+// Requests that have finished or have been taken over by another instance in the meantime are left alone.
+func (s *dispatchServer) redispatch(conn *clusterConnection) {
+	requests := conn.drain()
+	if len(requests) == 0 {
+		return
+	}
+
+	time.Sleep(s.redispatchDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	instances, err := s.db.DeploydInstances(ctx, conn.cluster, time.Now().Add(-instanceTimeout))
+	if err != nil {
+		log.Errorf("Find instances connected for cluster '%s'; not dispatching deployments of %s elsewhere: %s", conn.cluster, conn, err)
+		return
+	}
+	for _, instanceID := range instances {
+		if instanceID == conn.instanceID {
+			log.Infof("%s has reconnected; leaving its unfinished deployments to it", conn)
+			return
+		}
 	}
-	s.statusStreamsLock.RUnlock()
 
+	rows, err := s.db.DispatchedDeploymentRequests(ctx, conn.cluster)
+	if err != nil {
+		log.Errorf("Find unfinished deployments; not dispatching deployments of %s elsewhere: %s", conn, err)
+		return
+	}
+	unfinished := make(map[string]bool)
+	for _, row := range rows {
+		unfinished[row.DeploymentID] = row.InstanceID == conn.instanceID
+	}
+
+	for _, request := range requests {
+		if !unfinished[request.GetID()] {
+			continue
+		}
+		// The deployment may have been partially applied by the disconnected instance.
+		request.Resume = true
+		logger := log.WithFields(request.LogFields())
+		logger.Warnf("Deployment was not finished when %s disconnected; dispatching to another instance", conn)
+
+		err := s.dispatch(ctx, request)
+		if errors.Is(err, errClusterOffline) {
+			err = s.queueDeploymentRequest(ctx, request)
+			if err == nil {
+				// The queued request is dispatched again when an instance connects; don't resume it as well.
+				err = s.db.DeleteDispatchedDeploymentRequest(ctx, request.GetID())
+			}
+		}
+		if err != nil {
+			logger.Errorf("Re-dispatch deployment request: %s", err)
+		}
+	}
+}
+
+// HandleDeploymentStatus saves a deployment status, and sends it to status subscribers on every hookd instance.
+// The status is handled by this instance directly; notifications only reach the other instances,
+// and may be lost while their listener reconnects.
+func (s *dispatchServer) HandleDeploymentStatus(ctx context.Context, st *pb.DeploymentStatus) error {
 	dbStatus := database_mapper.DeploymentStatus(st)
 	err := s.db.WriteDeploymentStatus(ctx, dbStatus)
 	if err != nil {
@@ -68,25 +209,62 @@ func (s *dispatchServer) HandleDeploymentStatus(ctx context.Context, st *pb.Depl
 	logger := log.WithFields(st.LogFields())
 	logger.Debugf("Saved deployment status in database")
 
+	s.broadcastStatus(st)
+
+	err = s.publishStatus(ctx, st)
+	if err != nil {
+		logger.Errorf("Publish deployment status to other instances: %s", err)
+	}
+
+	if st.GetPruned() != nil {
+		err = s.writePrunedResource(ctx, st)
+		if err != nil {
+			logger.Errorf("Write pruned resource to database: %s", err)
+		}
+	}
+
 	err = s.writeDeploymentStatusToNaisApi(ctx, st)
 	if err != nil {
 		logger.WithError(err).Errorf("Write deployment status to Nais API")
 	}
 
 	if st.GetState().Finished() {
-		deployID := st.GetRequest().GetID()
-		s.traceSpansLock.Lock()
-		if span, ok := s.traceSpans[deployID]; ok {
-			span.End()
-			delete(s.traceSpans, deployID)
+		err = s.db.DeleteDispatchedDeploymentRequest(ctx, st.GetRequest().GetID())
+		if err != nil {
+			logger.Errorf("Remove finished deployment request from database: %s", err)
 		}
-		s.traceSpansLock.Unlock()
 		logger.Infof("Deployment finished")
 	}
 
 	return nil
 }
 
+// Record a resource deleted by deployd because it was removed from the deploy set, after the resources of the deployment.
+func (s *dispatchServer) writePrunedResource(ctx context.Context, st *pb.DeploymentStatus) error {
+	resources, err := s.db.DeploymentResources(ctx, st.GetRequest().GetID())
+	if err != nil {
+		return err
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return err
+	}
+
+	pruned := st.GetPruned()
+	return s.db.WriteDeploymentResource(ctx, database.DeploymentResource{
+		ID:           id.String(),
+		DeploymentID: st.GetRequest().GetID(),
+		Index:        len(resources),
+		Group:        pruned.GetGroup(),
+		Version:      pruned.GetVersion(),
+		Kind:         pruned.GetKind(),
+		Name:         pruned.GetName(),
+		Namespace:    pruned.GetNamespace(),
+		Deleted:      true,
+	})
+}
+
 func (s *dispatchServer) writeDeploymentStatusToNaisApi(ctx context.Context, status *pb.DeploymentStatus) error {
 	reqID := status.GetRequest().GetID()
 	msg := status.GetMessage()
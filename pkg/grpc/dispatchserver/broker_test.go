@@ -0,0 +1,137 @@
+package dispatchserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nais/api/pkg/apiclient"
+	"github.com/nais/api/pkg/apiclient/protoapi"
+	"github.com/nais/deploy/pkg/hookd/database"
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPrunedResources(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	deploymentStore := database.MockDeploymentStore{}
+	deploymentStore.On("WriteDeploymentStatus", mock.Anything, mock.Anything).Return(nil).Once()
+	deploymentStore.On("DeploymentResources", mock.Anything, "1").Return([]database.DeploymentResource{{Index: 0}, {Index: 1}}, nil).Once()
+	deploymentStore.On("WriteDeploymentResource", mock.Anything, mock.MatchedBy(func(resource database.DeploymentResource) bool {
+		return resource.DeploymentID == "1" &&
+			resource.Index == 2 &&
+			resource.Group == "kafka.nais.io" &&
+			resource.Kind == "Topic" &&
+			resource.Name == "removed" &&
+			resource.Namespace == "aura" &&
+			resource.Deleted
+	})).Return(nil).Once()
+
+	apiClients, apiMocks := apiclient.NewMockClient(t)
+	apiMocks.Deployments.EXPECT().CreateDeploymentStatus(mock.Anything, mock.Anything).Return(&protoapi.CreateDeploymentStatusResponse{}, nil)
+
+	ds, err := New(ctx, &deploymentStore, newMemoryNotifier(), apiClients.Deployments(), DispatchLeader)
+	assert.NoError(t, err)
+
+	st := pb.NewInProgressStatus(&pb.DeploymentRequest{ID: "1", Cluster: "dev"}, "Pruned Topic removed")
+	st.Pruned = &pb.KubernetesResource{Group: "kafka.nais.io", Version: "v1", Kind: "Topic", Name: "removed", Namespace: "aura"}
+
+	err = ds.HandleDeploymentStatus(ctx, st)
+	assert.NoError(t, err)
+	deploymentStore.AssertExpectations(t)
+}
+
+func TestPublishStatusTruncatesLargeMessages(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	notifier := newMemoryNotifier()
+	notifications, err := notifier.Listen(ctx, database.ChannelDeploymentStatus)
+	assert.NoError(t, err)
+
+	ds := &dispatchServer{id: "a", notifier: notifier}
+
+	for name, message := range map[string]string{
+		"plain text":      strings.Repeat("a", 20000),
+		"escaped quotes":  strings.Repeat(`"`, 6000),
+		"escaped control": strings.Repeat("\x01", 3000),
+	} {
+		t.Run(name, func(t *testing.T) {
+			st := pb.NewFailureStatus(&pb.DeploymentRequest{ID: "1", Cluster: "dev"}, errors.New(message))
+
+			err := ds.publishStatus(ctx, st)
+			assert.NoError(t, err)
+
+			notification := <-notifications
+			assert.Less(t, len(notification.Payload), database.MaxNotificationPayload)
+
+			origin, published, err := decodeStatusNotification(notification.Payload)
+			assert.NoError(t, err)
+			assert.Equal(t, "a", origin)
+			assert.True(t, strings.HasSuffix(published.GetMessage(), "..."))
+			assert.True(t, strings.HasPrefix(message, strings.TrimSuffix(published.GetMessage(), "...")))
+			assert.Greater(t, len(published.GetMessage()), 1000)
+		})
+	}
+}
+
+// lossyNotifier drops every notification, like a listener that is reconnecting.
+type lossyNotifier struct{}
+
+func (lossyNotifier) Notify(ctx context.Context, channel, payload string) error {
+	return nil
+}
+
+func (lossyNotifier) Listen(ctx context.Context, channels ...string) (<-chan database.Notification, error) {
+	return make(chan database.Notification), nil
+}
+
+func TestHandleDeploymentStatusLocally(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	deploymentStore := database.MockDeploymentStore{}
+	deploymentStore.On("WriteDeploymentStatus", mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("DeleteDispatchedDeploymentRequest", mock.Anything, "1").Return(nil)
+
+	apiClients, apiMocks := apiclient.NewMockClient(t)
+	apiMocks.Deployments.EXPECT().CreateDeploymentStatus(mock.Anything, mock.Anything).Return(&protoapi.CreateDeploymentStatusResponse{}, nil)
+
+	for name, notifier := range map[string]database.Notifier{
+		"notifications are lost":      lossyNotifier{},
+		"notifications are delivered": newMemoryNotifier(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			ds, err := New(ctx, &deploymentStore, notifier, apiClients.Deployments(), DispatchLeader)
+			assert.NoError(t, err)
+			server := ds.(*dispatchServer)
+
+			statuses := make(chan *pb.DeploymentStatus, 4)
+			go ds.StreamStatus(ctx, statuses)
+			time.Sleep(100 * time.Millisecond)
+
+			request := &pb.DeploymentRequest{ID: "1", Team: "aura", Cluster: "dev"}
+			conn := newClusterConnection(&pb.GetDeploymentOpts{Cluster: "dev"})
+			assert.NoError(t, server.connect(conn))
+			defer server.disconnect(conn)
+			conn.track(request)
+
+			err = ds.HandleDeploymentStatus(ctx, pb.NewSuccessStatus(request))
+			assert.NoError(t, err)
+			assert.False(t, conn.running("1"), "finished deployment is still in flight")
+
+			// Subscribers get the status once, and not again when this instance's own notification arrives.
+			assert.Equal(t, pb.DeploymentState_success, (<-statuses).GetState())
+			select {
+			case st := <-statuses:
+				t.Errorf("unexpected duplicate status %s", st.GetState())
+			case <-time.After(100 * time.Millisecond):
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package dispatchserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/nais/deploy/pkg/pb"
+)
+
+// DispatchMode decides which deployd instance receives a deployment request
+// when several instances are connected for the same cluster.
+//
+// deployd only serializes deployments of the same resources that it runs itself. In every mode, a request from a team
+// that has unfinished deployments on one of the instances is therefore sent to that instance, and the mode only decides
+// where a team's deployments go when it has none running.
+type DispatchMode string
+
+const (
+	// All requests go to the instance that has been connected the longest. The other instances are on standby.
+	DispatchLeader DispatchMode = "leader"
+	// Teams are spread evenly across instances.
+	DispatchRoundRobin DispatchMode = "round-robin"
+	// Teams go to the instance with the fewest unfinished deployments.
+	DispatchLeastInFlight DispatchMode = "least-in-flight"
+)
+
+var errConnectionClosed = errors.New("deployd instance disconnected")
+
+func ParseDispatchMode(mode string) (DispatchMode, error) {
+	switch DispatchMode(mode) {
+	case DispatchLeader, DispatchRoundRobin, DispatchLeastInFlight:
+		return DispatchMode(mode), nil
+	default:
+		return "", fmt.Errorf("unknown dispatch mode '%s'; valid values are %s, %s and %s", mode, DispatchLeader, DispatchRoundRobin, DispatchLeastInFlight)
+	}
+}
+
+// clusterConnection is the deployment stream of a deployd instance connected to this hookd instance.
+type clusterConnection struct {
+	cluster    string
+	instanceID string
+	requests   chan *requestWithWait
+	// Signals that deployment requests for this cluster have been queued in the database.
+	flush chan struct{}
+	// Closed when the stream ends.
+	done chan struct{}
+
+	inFlightLock sync.Mutex
+	// Deployment requests sent to this instance that have not yet reported a final status.
+	inFlight map[string]*pb.DeploymentRequest
+}
+
+func newClusterConnection(opts *pb.GetDeploymentOpts) *clusterConnection {
+	return &clusterConnection{
+		cluster:    opts.GetCluster(),
+		instanceID: opts.GetInstanceID(),
+		requests:   make(chan *requestWithWait),
+		flush:      make(chan struct{}, 1),
+		done:       make(chan struct{}),
+		inFlight:   make(map[string]*pb.DeploymentRequest),
+	}
+}
+
+func (c *clusterConnection) String() string {
+	if len(c.instanceID) == 0 {
+		return fmt.Sprintf("cluster '%s'", c.cluster)
+	}
+	return fmt.Sprintf("cluster '%s' (instance '%s')", c.cluster, c.instanceID)
+}
+
+// Send a request on the stream and wait until it has been sent.
+// Returns errConnectionClosed if the stream ends before the request could be picked up.
+func (c *clusterConnection) send(ctx context.Context, request *pb.DeploymentRequest) error {
+	wait := make(chan error, 1)
+	select {
+	case c.requests <- &requestWithWait{request: request, wait: wait}:
+	case <-c.done:
+		return errConnectionClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return <-wait
+}
+
+// Remember a deployment request sent on this stream, so that it can be dispatched elsewhere if the stream ends early.
+func (c *clusterConnection) track(request *pb.DeploymentRequest) {
+	if request.GetCancel() || request.GetPlan() {
+		return
+	}
+	c.inFlightLock.Lock()
+	c.inFlight[request.GetID()] = request
+	c.inFlightLock.Unlock()
+}
+
+func (c *clusterConnection) release(id string) {
+	c.inFlightLock.Lock()
+	delete(c.inFlight, id)
+	c.inFlightLock.Unlock()
+}
+
+func (c *clusterConnection) running(id string) bool {
+	c.inFlightLock.Lock()
+	defer c.inFlightLock.Unlock()
+	_, ok := c.inFlight[id]
+	return ok
+}
+
+// Whether this instance has unfinished deployments for a team.
+func (c *clusterConnection) runningTeam(team string) bool {
+	c.inFlightLock.Lock()
+	defer c.inFlightLock.Unlock()
+	for _, request := range c.inFlight {
+		if request.GetTeam() == team {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *clusterConnection) inFlightCount() int {
+	c.inFlightLock.Lock()
+	defer c.inFlightLock.Unlock()
+	return len(c.inFlight)
+}
+
+// Forget all unfinished deployment requests, oldest first.
+func (c *clusterConnection) drain() []*pb.DeploymentRequest {
+	c.inFlightLock.Lock()
+	defer c.inFlightLock.Unlock()
+	requests := make([]*pb.DeploymentRequest, 0, len(c.inFlight))
+	for _, request := range c.inFlight {
+		requests = append(requests, request)
+	}
+	c.inFlight = make(map[string]*pb.DeploymentRequest)
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].GetTime().AsTime().Before(requests[j].GetTime().AsTime())
+	})
+	return requests
+}
+
+// Register a deployd stream. Instance IDs must be unique within a cluster.
+func (s *dispatchServer) connect(conn *clusterConnection) error {
+	s.onlineClustersLock.Lock()
+	defer s.onlineClustersLock.Unlock()
+
+	for _, other := range s.onlineClustersMap[conn.cluster] {
+		if other.instanceID == conn.instanceID {
+			return fmt.Errorf("%s already connected", conn)
+		}
+	}
+	s.onlineClustersMap[conn.cluster] = append(s.onlineClustersMap[conn.cluster], conn)
+
+	return nil
+}
+
+func (s *dispatchServer) disconnect(conn *clusterConnection) {
+	s.onlineClustersLock.Lock()
+	defer s.onlineClustersLock.Unlock()
+
+	conns := s.onlineClustersMap[conn.cluster]
+	for i := range conns {
+		if conns[i] == conn {
+			conns = append(conns[:i:i], conns[i+1:]...)
+			break
+		}
+	}
+	if len(conns) == 0 {
+		delete(s.onlineClustersMap, conn.cluster)
+	} else {
+		s.onlineClustersMap[conn.cluster] = conns
+	}
+	close(conn.done)
+}
+
+// Returns all deployd streams for a cluster connected to this hookd instance, oldest first.
+func (s *dispatchServer) connections(cluster string) []*clusterConnection {
+	s.onlineClustersLock.RLock()
+	defer s.onlineClustersLock.RUnlock()
+	return append([]*clusterConnection(nil), s.onlineClustersMap[cluster]...)
+}
+
+// Pick the deployd stream that should receive the next deployment request for a team in a cluster.
+// Requests go to the instance already running deployments for the team, if any, and otherwise according to the dispatch mode.
+// Returns nil if the cluster is not connected to this hookd instance.
+func (s *dispatchServer) pickConnection(cluster, team string) *clusterConnection {
+	s.onlineClustersLock.Lock()
+	defer s.onlineClustersLock.Unlock()
+
+	conns := s.onlineClustersMap[cluster]
+	if len(conns) == 0 {
+		return nil
+	}
+
+	if conn := teamConnection(conns, team); len(team) > 0 && conn != nil {
+		return conn
+	}
+
+	switch s.dispatchMode {
+	case DispatchRoundRobin:
+		next := s.roundRobin[cluster] % len(conns)
+		s.roundRobin[cluster] = next + 1
+		return conns[next]
+
+	case DispatchLeastInFlight:
+		pick := conns[0]
+		count := pick.inFlightCount()
+		for _, conn := range conns[1:] {
+			if c := conn.inFlightCount(); c < count {
+				pick, count = conn, c
+			}
+		}
+		return pick
+
+	default:
+		return conns[0]
+	}
+}
+
+// Returns the deployd stream running deployments for a team, if any.
+func teamConnection(conns []*clusterConnection, team string) *clusterConnection {
+	for _, conn := range conns {
+		if conn.runningTeam(team) {
+			return conn
+		}
+	}
+	return nil
+}
+
+// Whether this deployd stream should send deployment requests queued in the database.
+// In leader mode, only the leader does; otherwise, any stream can.
+func (s *dispatchServer) flushes(conn *clusterConnection) bool {
+	if s.dispatchMode != DispatchLeader {
+		return true
+	}
+	conns := s.connections(conn.cluster)
+	return len(conns) > 0 && conns[0] == conn
+}
+
+// Returns the deployd stream on this hookd instance that is running a deployment, if any.
+func (s *dispatchServer) runningConnection(request *pb.DeploymentRequest) *clusterConnection {
+	for _, conn := range s.connections(request.GetCluster()) {
+		if conn.running(request.GetID()) {
+			return conn
+		}
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/google/uuid"
 	"github.com/nais/api/pkg/apiclient/protoapi"
 	"github.com/nais/deploy/pkg/hookd/database"
 	database_mapper "github.com/nais/deploy/pkg/hookd/database/mapper"
@@ -20,22 +21,48 @@ import (
 	"github.com/nais/deploy/pkg/pb"
 )
 
+const (
+	// How often to check the database for queued deployment requests, in case a notification was lost.
+	queuePollInterval = 10 * time.Second
+
+	// Close connections that have not received a deployment request for this long.
+	idleTimeout = 30 * time.Minute
+
+	// Deployd instances that have not been seen by any hookd instance for this long are considered gone,
+	// and their unfinished deployments may be resumed by other instances.
+	instanceTimeout = 3 * queuePollInterval
+
+	// How long a disconnected deployd instance has to reconnect before its unfinished deployments are sent elsewhere.
+	redispatchDelay = 2 * queuePollInterval
+)
+
 type DispatchServer interface {
 	pb.DispatchServer
 	SendDeploymentRequest(ctx context.Context, deployment *pb.DeploymentRequest) error
+	SendCancelRequest(ctx context.Context, request *pb.DeploymentRequest) error
+	SendPlanRequest(ctx context.Context, request *pb.DeploymentRequest) (*pb.DeploymentPlan, error)
 	HandleDeploymentStatus(ctx context.Context, status *pb.DeploymentStatus) error
 	StreamStatus(context.Context, chan<- *pb.DeploymentStatus)
+	ExpireQueue(ctx context.Context, interval time.Duration)
 }
 
 type dispatchServer struct {
 	pb.UnimplementedDispatchServer
+	// Identifies this hookd instance in notifications.
+	id                 string
 	onlineClustersLock sync.RWMutex
-	onlineClustersMap  map[string]chan<- *requestWithWait
+	onlineClustersMap  map[string][]*clusterConnection
+	roundRobin         map[string]int
+	dispatchMode       DispatchMode
+	redispatchDelay    time.Duration
 	statusStreamsLock  sync.RWMutex
 	statusStreams      map[context.Context]chan<- *pb.DeploymentStatus
 	traceSpans         map[string]trace.Span
 	traceSpansLock     sync.RWMutex
+	plansLock          sync.Mutex
+	plans              map[string]chan *pb.DeploymentPlan
 	db                 database.DeploymentStore
+	notifier           database.Notifier
 	apiClient          protoapi.DeploymentsClient
 }
 
@@ -46,16 +73,37 @@ type requestWithWait struct {
 	wait    chan error
 }
 
-func New(db database.DeploymentStore, apiClient protoapi.DeploymentsClient) DispatchServer {
+// New returns a dispatch server that coordinates with other hookd instances sharing the same database.
+// Notifications are received until the context is cancelled.
+// The dispatch mode decides how requests are distributed when several deployd instances serve the same cluster.
+func New(ctx context.Context, db database.DeploymentStore, notifier database.Notifier, apiClient protoapi.DeploymentsClient, dispatchMode DispatchMode) (DispatchServer, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
 	server := &dispatchServer{
-		onlineClustersMap: make(map[string]chan<- *requestWithWait),
+		id:                id.String(),
+		onlineClustersMap: make(map[string][]*clusterConnection),
+		roundRobin:        make(map[string]int),
+		dispatchMode:      dispatchMode,
+		redispatchDelay:   redispatchDelay,
 		statusStreams:     make(map[context.Context]chan<- *pb.DeploymentStatus),
 		traceSpans:        make(map[string]trace.Span),
+		plans:             make(map[string]chan *pb.DeploymentPlan),
 		db:                db,
+		notifier:          notifier,
 		apiClient:         apiClient,
 	}
 
-	return server
+	notifications, err := notifier.Listen(ctx, database.ChannelDeploymentStatus, database.ChannelDeploymentQueue, database.ChannelDeploymentCancel)
+	if err != nil {
+		return nil, fmt.Errorf("listen for notifications from other hookd instances: %w", err)
+	}
+
+	go server.handleNotifications(notifications)
+
+	return server, nil
 }
 
 func (s *dispatchServer) onlineClusters() []string {
@@ -75,13 +123,85 @@ func (s *dispatchServer) reportOnlineClusters() {
 	log.Infof("Online clusters: %s", strings.Join(clusters, ", "))
 }
 
-func (s *dispatchServer) invalidateHistoric(ctx context.Context, cluster string, timestamp time.Time) error {
-	deploys, err := s.db.HistoricDeployments(ctx, cluster, timestamp)
+// Hand deployments that were unfinished when deployd started back to deployd, so that it can resume their rollout.
+// Each dispatched deployment request records the deployd instance it was sent to. Requests sent to this instance
+// are resumed, as are requests sent to instances that are no longer connected to any hookd instance.
+// Requests sent to other live instances are left to them.
+// Deployments that cannot be resumed, because their request is unknown or their deadline has passed, are marked as inactive.
+func (s *dispatchServer) resumeHistoric(ctx context.Context, conn *clusterConnection, stream pb.Dispatch_DeploymentsServer, timestamp time.Time) error {
+	deploys, err := s.db.HistoricDeployments(ctx, conn.cluster, timestamp)
 	if err != nil {
 		return err
 	}
+	if len(deploys) == 0 {
+		return nil
+	}
 
+	historic := make(map[string]*database.Deployment)
 	for _, deploy := range deploys {
+		historic[deploy.ID] = deploy
+	}
+
+	dispatched, err := s.db.DispatchedDeploymentRequests(ctx, conn.cluster)
+	if err != nil {
+		return err
+	}
+
+	instances, err := s.db.DeploydInstances(ctx, conn.cluster, time.Now().Add(-instanceTimeout))
+	if err != nil {
+		return err
+	}
+
+	live := make(map[string]bool)
+	for _, instanceID := range instances {
+		live[instanceID] = instanceID != conn.instanceID
+	}
+
+	for _, row := range dispatched {
+		if historic[row.DeploymentID] == nil {
+			continue
+		}
+		if live[row.InstanceID] {
+			// Still running on another deployd instance.
+			delete(historic, row.DeploymentID)
+			continue
+		}
+		if time.Now().After(row.Deadline) {
+			continue
+		}
+
+		request, err := database_mapper.PbDispatchedRequest(row)
+		if err != nil {
+			log.Errorf("Discarding unfinished deployment request %s: %s", row.DeploymentID, err)
+			continue
+		}
+
+		// Make sure no other deployd instance takes over the same request.
+		err = s.db.ClaimDispatchedDeploymentRequest(ctx, row.DeploymentID, row.InstanceID, conn.instanceID)
+		if database.IsErrNotFound(err) {
+			delete(historic, row.DeploymentID)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("claim unfinished deployment: %w", err)
+		}
+
+		request.Resume = true
+		err = s.HandleDeploymentStatus(ctx, pb.NewInProgressStatus(request, "deployd was restarted; resuming deployment"))
+		if err != nil {
+			return err
+		}
+
+		err = s.send(ctx, conn, stream, request)
+		if err != nil {
+			return fmt.Errorf("resume deployment: %w", err)
+		}
+
+		log.WithFields(request.LogFields()).Infof("Unfinished deployment handed back to deployd")
+		delete(historic, row.DeploymentID)
+	}
+
+	for _, deploy := range historic {
 		req := database_mapper.PbRequest(*deploy)
 		err = s.HandleDeploymentStatus(ctx, pb.NewInactiveStatus(req))
 		if err != nil {
@@ -92,50 +212,266 @@ func (s *dispatchServer) invalidateHistoric(ctx context.Context, cluster string,
 	return nil
 }
 
-func (s *dispatchServer) Deployments(opts *pb.GetDeploymentOpts, stream pb.Dispatch_DeploymentsServer) error {
-	c := make(chan *requestWithWait)
-	s.onlineClustersLock.RLock()
-	_, clusterAlreadyConnected := s.onlineClustersMap[opts.Cluster]
-	s.onlineClustersLock.RUnlock()
-	if clusterAlreadyConnected {
-		log.Warnf("Rejected connection from cluster '%s': already connected", opts.Cluster)
-		return fmt.Errorf("cluster already connected: %s", opts.Cluster)
+// Send a deployment request on a deployd stream. Deployment requests are kept in the database until they
+// have finished, so that they can be resumed if deployd restarts, or dispatched elsewhere if the stream ends early.
+func (s *dispatchServer) send(ctx context.Context, conn *clusterConnection, stream pb.Dispatch_DeploymentsServer, request *pb.DeploymentRequest) error {
+	if !request.GetCancel() && !request.GetPlan() {
+		dispatched, err := database_mapper.DispatchedDeploymentRequest(request)
+		if err == nil {
+			dispatched.InstanceID = conn.instanceID
+			err = s.db.WriteDispatchedDeploymentRequest(ctx, dispatched)
+		}
+		if err != nil {
+			log.WithFields(request.LogFields()).Errorf("Save dispatched deployment request; it cannot be resumed if deployd restarts: %s", err)
+		}
+	}
+
+	// Track before sending, so that a final status arriving right away finds the request.
+	conn.track(request)
+
+	err := stream.Send(request)
+	if err != nil {
+		conn.release(request.GetID())
+		return err
 	}
 
-	s.onlineClustersLock.Lock()
-	s.onlineClustersMap[opts.Cluster] = c
-	log.Infof("Connection opened from cluster '%s'", opts.Cluster)
-	s.onlineClustersLock.Unlock()
+	return nil
+}
+
+func (s *dispatchServer) Deployments(opts *pb.GetDeploymentOpts, stream pb.Dispatch_DeploymentsServer) error {
+	conn := newClusterConnection(opts)
+
+	err := s.connect(conn)
+	if err != nil {
+		log.Warnf("Rejected connection from %s: %s", conn, err)
+		return err
+	}
+	log.Infof("Connection opened from %s", conn)
 	s.reportOnlineClusters()
 
 	defer func() {
-		s.onlineClustersLock.Lock()
-		delete(s.onlineClustersMap, opts.Cluster)
-		s.onlineClustersLock.Unlock()
+		s.disconnect(conn)
 		s.reportOnlineClusters()
+		s.forgetInstance(conn)
+		go s.redispatch(conn)
 	}()
 
-	// invalidate older deployments
-	err := s.invalidateHistoric(stream.Context(), opts.GetCluster(), opts.GetStartupTime().AsTime())
+	err = s.db.WriteDeploydInstance(stream.Context(), conn.cluster, conn.instanceID)
 	if err != nil {
 		return status.Error(codes.Unavailable, err.Error())
 	}
 
+	err = s.resumeHistoric(stream.Context(), conn, stream, opts.GetStartupTime().AsTime())
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	// send deployments queued while the cluster was offline
+	if s.flushes(conn) {
+		err = s.flushQueue(stream.Context(), conn, stream)
+		if err != nil {
+			return status.Error(codes.Unavailable, err.Error())
+		}
+	}
+
+	poll := time.NewTicker(queuePollInterval)
+	defer poll.Stop()
+	idle := time.NewTimer(idleTimeout)
+	defer idle.Stop()
+
 	for {
 		select {
 		case <-stream.Context().Done():
-			log.Warnf("Connection from cluster '%s' closed", opts.Cluster)
+			log.Warnf("Connection from %s closed", conn)
 			return nil
-		case req := <-c:
-			err := stream.Send(req.request)
-			req.wait <- err
-		case <-time.After(30 * time.Minute):
-			log.Warnf("Connection from cluster '%s' timed out", opts.Cluster)
+		case req := <-conn.requests:
+			req.wait <- s.send(stream.Context(), conn, stream, req.request)
+			idle.Reset(idleTimeout)
+		case <-conn.flush:
+			err = s.flushQueue(stream.Context(), conn, stream)
+			if err != nil {
+				return status.Error(codes.Unavailable, err.Error())
+			}
+		case <-poll.C:
+			err = s.db.WriteDeploydInstance(stream.Context(), conn.cluster, conn.instanceID)
+			if err != nil {
+				log.Errorf("Record %s as connected: %s", conn, err)
+			}
+			if !s.flushes(conn) {
+				continue
+			}
+			err = s.flushQueue(stream.Context(), conn, stream)
+			if err != nil {
+				return status.Error(codes.Unavailable, err.Error())
+			}
+		case <-idle.C:
+			log.Warnf("Connection from %s timed out", conn)
 			return fmt.Errorf("timeout")
 		}
 	}
 }
 
+// Remove a disconnected deployd instance from the database, so that its unfinished deployments
+// can be resumed by the next instance connecting for the cluster.
+func (s *dispatchServer) forgetInstance(conn *clusterConnection) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := s.db.DeleteDeploydInstance(ctx, conn.cluster, conn.instanceID)
+	if err != nil {
+		log.Errorf("Remove %s from connected instances: %s", conn, err)
+	}
+}
+
+// Send all deployment requests queued for a cluster to a deployd instance, oldest first.
+// Requests that have passed their deadline while waiting are failed with an error status instead.
+// Each request stays on the queue until it has been recorded as dispatched, so that no request is lost if hookd stops mid-flush.
+func (s *dispatchServer) flushQueue(ctx context.Context, conn *clusterConnection, stream pb.Dispatch_DeploymentsServer) error {
+	cluster := conn.cluster
+	queued, err := s.db.QueuedDeploymentRequests(ctx, cluster)
+	if err != nil {
+		return fmt.Errorf("get queued deployment requests: %w", err)
+	}
+
+	for i := range queued {
+		request, err := database_mapper.PbQueuedRequest(queued[i])
+		if err != nil {
+			log.Errorf("Discarding queued deployment request %s: %s", queued[i].DeploymentID, err)
+			err = s.db.DeleteQueuedDeploymentRequest(ctx, queued[i].DeploymentID)
+			if err != nil && !database.IsErrNotFound(err) {
+				log.Errorf("Remove deployment request from queue: %s", err)
+			}
+			continue
+		}
+
+		if time.Now().After(queued[i].Deadline) {
+			s.expireQueued(ctx, request)
+			continue
+		}
+
+		// Leave requests for teams with deployments running on another instance to that instance.
+		if other := teamConnection(s.connections(cluster), request.GetTeam()); other != nil && other != conn {
+			select {
+			case other.flush <- struct{}{}:
+			default:
+				// flush already pending
+			}
+			continue
+		}
+
+		err = s.sendQueued(ctx, conn, stream, request)
+		if database.IsErrNotFound(err) {
+			// Dispatched or cancelled by another hookd instance.
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("send queued deployment request: %w", err)
+		}
+
+		log.WithFields(request.LogFields()).Infof("Queued deployment request sent to deployd")
+	}
+
+	return nil
+}
+
+// Send a queued deployment request on a deployd stream. The request is removed from the queue in the same
+// transaction as it is recorded as dispatched. Returns database.ErrNotFound if the request is no longer queued.
+func (s *dispatchServer) sendQueued(ctx context.Context, conn *clusterConnection, stream pb.Dispatch_DeploymentsServer, request *pb.DeploymentRequest) error {
+	dispatched, err := database_mapper.DispatchedDeploymentRequest(request)
+	if err != nil {
+		return err
+	}
+
+	dispatched.InstanceID = conn.instanceID
+	err = s.db.DispatchQueuedDeploymentRequest(ctx, dispatched)
+	if err != nil {
+		return err
+	}
+
+	conn.track(request)
+
+	err = stream.Send(request)
+	if err != nil {
+		conn.release(request.GetID())
+		s.requeue(request)
+		return err
+	}
+
+	return nil
+}
+
+// Put a deployment request back on the queue after it could not be sent.
+// The connection is most likely gone at this point, so a fresh context is used.
+func (s *dispatchServer) requeue(request *pb.DeploymentRequest) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	logger := log.WithFields(request.LogFields())
+
+	queued, err := database_mapper.QueuedDeploymentRequest(request)
+	if err == nil {
+		err = s.db.QueueDeploymentRequest(ctx, queued)
+	}
+	if err != nil {
+		logger.Errorf("Re-queue deployment request: %s", err)
+		return
+	}
+
+	err = s.db.DeleteDispatchedDeploymentRequest(ctx, request.GetID())
+	if err != nil {
+		logger.Errorf("Remove re-queued deployment request from dispatched requests: %s", err)
+	}
+}
+
+// Remove a queued deployment request that has passed its deadline, and fail it with an error status.
+// Requests removed by another hookd instance in the meantime are left alone.
+func (s *dispatchServer) expireQueued(ctx context.Context, request *pb.DeploymentRequest) {
+	logger := log.WithFields(request.LogFields())
+
+	err := s.db.DeleteQueuedDeploymentRequest(ctx, request.GetID())
+	if database.IsErrNotFound(err) {
+		return
+	}
+	if err != nil {
+		logger.Errorf("Remove expired deployment request from queue: %s", err)
+		return
+	}
+
+	err = s.HandleDeploymentStatus(ctx, pb.NewErrorStatus(request, fmt.Errorf("deadline passed while waiting for cluster '%s' to come online", request.GetCluster())))
+	if err != nil {
+		logger.Errorf("Expire queued deployment request: %s", err)
+	}
+}
+
+// ExpireQueue fails queued deployment requests that have passed their deadline, and repeats every interval until the context is cancelled.
+// Requests for clusters that never come back online would otherwise stay queued forever.
+func (s *dispatchServer) ExpireQueue(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		queued, err := s.db.ExpiredQueuedDeploymentRequests(ctx, time.Now())
+		if err != nil {
+			log.Errorf("Find expired queued deployment requests: %s", err)
+		}
+
+		for i := range queued {
+			request, err := database_mapper.PbQueuedRequest(queued[i])
+			if err != nil {
+				log.Errorf("Discarding queued deployment request %s: %s", queued[i].DeploymentID, err)
+				continue
+			}
+			s.expireQueued(ctx, request)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 func (s *dispatchServer) ReportStatus(ctx context.Context, status *pb.DeploymentStatus) (*pb.ReportStatusOpts, error) {
 	return &pb.ReportStatusOpts{}, s.HandleDeploymentStatus(ctx, status)
 }
@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"net"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/nais/api/pkg/apiclient"
 	presharedkey_interceptor "github.com/nais/deploy/pkg/grpc/interceptor/presharedkey"
 	"github.com/nais/deploy/pkg/hookd/database"
+	database_mapper "github.com/nais/deploy/pkg/hookd/database/mapper"
 	"github.com/nais/deploy/pkg/pb"
 	"github.com/nais/deploy/pkg/telemetry"
 	"github.com/stretchr/testify/mock"
@@ -31,6 +33,108 @@ const (
 	WrongPassword   = "wrong"
 )
 
+// memoryNotifier delivers notifications to listeners in the same process,
+// standing in for a database shared by several hookd instances.
+type memoryNotifier struct {
+	lock      sync.Mutex
+	listeners []memoryListener
+}
+
+type memoryListener struct {
+	channels      map[string]bool
+	notifications chan database.Notification
+}
+
+func newMemoryNotifier() *memoryNotifier {
+	return &memoryNotifier{}
+}
+
+func (n *memoryNotifier) Notify(ctx context.Context, channel, payload string) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for _, listener := range n.listeners {
+		if listener.channels[channel] {
+			listener.notifications <- database.Notification{Channel: channel, Payload: payload}
+		}
+	}
+	return nil
+}
+
+func (n *memoryNotifier) Listen(ctx context.Context, channels ...string) (<-chan database.Notification, error) {
+	listener := memoryListener{
+		channels:      make(map[string]bool),
+		notifications: make(chan database.Notification, 64),
+	}
+	for _, channel := range channels {
+		listener.channels[channel] = true
+	}
+	n.lock.Lock()
+	n.listeners = append(n.listeners, listener)
+	n.lock.Unlock()
+	return listener.notifications, nil
+}
+
+// memoryQueue is a deployment queue shared by the hookd instances in a test, standing in for the database table.
+type memoryQueue struct {
+	lock     sync.Mutex
+	requests []database.QueuedDeploymentRequest
+}
+
+func (q *memoryQueue) len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return len(q.requests)
+}
+
+// Remove a request from the queue, returning database.ErrNotFound if it is not queued.
+func (q *memoryQueue) remove(deploymentID string) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	for i, request := range q.requests {
+		if request.DeploymentID == deploymentID {
+			q.requests = append(q.requests[:i], q.requests[i+1:]...)
+			return nil
+		}
+	}
+	return database.ErrNotFound
+}
+
+func (q *memoryQueue) mock(store *database.MockDeploymentStore) {
+	store.On("QueueDeploymentRequest", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		q.lock.Lock()
+		defer q.lock.Unlock()
+		q.requests = append(q.requests, args.Get(1).(database.QueuedDeploymentRequest))
+	}).Return(nil)
+	store.On("QueuedDeploymentRequests", mock.Anything, mock.Anything).Return(func(ctx context.Context, cluster string) []database.QueuedDeploymentRequest {
+		q.lock.Lock()
+		defer q.lock.Unlock()
+		requests := make([]database.QueuedDeploymentRequest, 0)
+		for _, request := range q.requests {
+			if request.Cluster == cluster {
+				requests = append(requests, request)
+			}
+		}
+		return requests
+	}, nil)
+	store.On("ExpiredQueuedDeploymentRequests", mock.Anything, mock.Anything).Return(func(ctx context.Context, before time.Time) []database.QueuedDeploymentRequest {
+		q.lock.Lock()
+		defer q.lock.Unlock()
+		requests := make([]database.QueuedDeploymentRequest, 0)
+		for _, request := range q.requests {
+			if request.Deadline.Before(before) {
+				requests = append(requests, request)
+			}
+		}
+		return requests
+	}, nil)
+	store.On("DispatchQueuedDeploymentRequest", mock.Anything, mock.Anything).Return(func(ctx context.Context, request database.DispatchedDeploymentRequest) error {
+		return q.remove(request.DeploymentID)
+	})
+	store.On("DeleteQueuedDeploymentRequest", mock.Anything, mock.Anything).Return(func(ctx context.Context, deploymentID string) error {
+		return q.remove(deploymentID)
+	})
+}
+
 func TestInterceptors(t *testing.T) {
 	ctx := context.Background()
 	_, _ = telemetry.New(ctx, "test", "")
@@ -41,14 +145,23 @@ func TestInterceptors(t *testing.T) {
 		ID: "mock",
 	}
 	deploymentStore.On("HistoricDeployments", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	deploymentStore.On("WriteDeploydInstance", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("DeleteDeploydInstance", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("WriteDispatchedDeploymentRequest", mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("DeleteDispatchedDeploymentRequest", mock.Anything, mock.Anything).Return(nil)
 	deploymentStore.On("WriteDeploymentStatus", mock.Anything, mock.Anything).Return(nil)
 	deploymentStore.On("Deployment", mock.Anything, mock.Anything).Return(mockDeployment, nil)
+	deploymentStore.On("QueuedDeploymentRequests", mock.Anything, mock.Anything).Return([]database.QueuedDeploymentRequest{}, nil)
+	deploymentStore.On("QueueDeploymentRequest", mock.Anything, mock.Anything).Return(nil)
 
 	mockApiClients, mockApiServer := apiclient.NewMockClient(t)
 
 	mockApiServer.Deployments.EXPECT().CreateDeploymentStatus(mock.Anything, mock.Anything).Return(nil, nil)
 
-	ds := New(&deploymentStore, mockApiClients.Deployments())
+	ds, err := New(ctx, &deploymentStore, newMemoryNotifier(), mockApiClients.Deployments(), DispatchLeastInFlight)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	presharedkeyInterceptor := &presharedkey_interceptor.ServerInterceptor{
 		Keys: []string{CorrectPassword},
@@ -149,3 +262,502 @@ func TestInterceptors(t *testing.T) {
 		}
 	})
 }
+
+func TestDeploymentQueue(t *testing.T) {
+	ctx := context.Background()
+	_, _ = telemetry.New(ctx, "test", "")
+
+	queue := &memoryQueue{}
+	statuses := make(chan *pb.DeploymentStatus, 16)
+
+	deploymentStore := database.MockDeploymentStore{}
+	deploymentStore.On("HistoricDeployments", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	deploymentStore.On("WriteDeploydInstance", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("DeleteDeploydInstance", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("WriteDispatchedDeploymentRequest", mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("DeleteDispatchedDeploymentRequest", mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("WriteDeploymentStatus", mock.Anything, mock.Anything).Return(nil)
+	queue.mock(&deploymentStore)
+
+	// Statuses reach subscribers before they are written to the Nais API.
+	mockApiClients, mockApiServer := apiclient.NewMockClient(t)
+	mockApiServer.Deployments.EXPECT().CreateDeploymentStatus(mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+
+	ds, err := New(ctx, &deploymentStore, newMemoryNotifier(), mockApiClients.Deployments(), DispatchLeastInFlight)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	pb.RegisterDispatchServer(srv, ds)
+	go srv.Serve(b)
+	defer srv.Stop()
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go ds.StreamStatus(streamCtx, statuses)
+
+	// Send requests to an offline cluster; one of them expires before the cluster connects.
+	err = ds.SendDeploymentRequest(ctx, &pb.DeploymentRequest{
+		ID:       "pending",
+		Cluster:  "offline",
+		Deadline: pb.TimeAsTimestamp(time.Now().Add(time.Minute)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ds.SendDeploymentRequest(ctx, &pb.DeploymentRequest{
+		ID:       "expired",
+		Cluster:  "offline",
+		Deadline: pb.TimeAsTimestamp(time.Now().Add(-time.Minute)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if queue.len() != 2 {
+		t.Fatalf("expected 2 queued requests, got %d", queue.len())
+	}
+
+	conn, _ := grpc.DialContext(ctx, "bufnet", grpc.WithContextDialer(bufDialer(b)), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	client := pb.NewDispatchClient(conn)
+	deploymentsClient, err := client.Deployments(ctx, &pb.GetDeploymentOpts{Cluster: "offline"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := deploymentsClient.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.GetID() != "pending" {
+		t.Errorf("expected queued request to be sent when cluster connects, got %q", r.GetID())
+	}
+
+	select {
+	case st := <-statuses:
+		if st.GetRequest().GetID() != "expired" || st.GetState() != pb.DeploymentState_error {
+			t.Errorf("expected error status for expired request, got %s for %q", st.GetState(), st.GetRequest().GetID())
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("expected error status for expired request")
+	}
+
+	if queue.len() != 0 {
+		t.Errorf("expected queue to be empty after it was flushed, got %d requests", queue.len())
+	}
+}
+
+func TestExpireQueue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, _ = telemetry.New(ctx, "test", "")
+
+	queue := &memoryQueue{}
+	statuses := make(chan *pb.DeploymentStatus, 16)
+
+	deploymentStore := database.MockDeploymentStore{}
+	deploymentStore.On("WriteDeploymentStatus", mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("DeleteDispatchedDeploymentRequest", mock.Anything, mock.Anything).Return(nil)
+	queue.mock(&deploymentStore)
+
+	mockApiClients, mockApiServer := apiclient.NewMockClient(t)
+	mockApiServer.Deployments.EXPECT().CreateDeploymentStatus(mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+
+	ds, err := New(ctx, &deploymentStore, newMemoryNotifier(), mockApiClients.Deployments(), DispatchLeastInFlight)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go ds.StreamStatus(ctx, statuses)
+	time.Sleep(100 * time.Millisecond)
+
+	// Requests for a cluster that never comes online are expired without waiting for it to connect.
+	for _, request := range []*pb.DeploymentRequest{
+		{ID: "pending", Cluster: "gone", Deadline: pb.TimeAsTimestamp(time.Now().Add(time.Minute))},
+		{ID: "expired", Cluster: "gone", Deadline: pb.TimeAsTimestamp(time.Now().Add(-time.Minute))},
+	} {
+		err = ds.SendDeploymentRequest(ctx, request)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	go ds.ExpireQueue(ctx, time.Hour)
+
+	select {
+	case st := <-statuses:
+		if st.GetRequest().GetID() != "expired" || st.GetState() != pb.DeploymentState_error {
+			t.Errorf("expected error status for expired request, got %s for %q", st.GetState(), st.GetRequest().GetID())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected error status for expired request")
+	}
+
+	if queue.len() != 1 {
+		t.Errorf("expected only the pending request to stay queued, got %d requests", queue.len())
+	}
+}
+
+func TestMultipleInstances(t *testing.T) {
+	ctx := context.Background()
+	_, _ = telemetry.New(ctx, "test", "")
+
+	// Both instances share the same database.
+	queue := &memoryQueue{}
+	notifier := newMemoryNotifier()
+
+	deploymentStore := database.MockDeploymentStore{}
+	deploymentStore.On("HistoricDeployments", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	deploymentStore.On("WriteDeploydInstance", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("DeleteDeploydInstance", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("WriteDispatchedDeploymentRequest", mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("DeleteDispatchedDeploymentRequest", mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("WriteDeploymentStatus", mock.Anything, mock.Anything).Return(nil)
+	queue.mock(&deploymentStore)
+
+	mockApiClients, mockApiServer := apiclient.NewMockClient(t)
+	mockApiServer.Deployments.EXPECT().CreateDeploymentStatus(mock.Anything, mock.Anything).Return(nil, nil)
+
+	instanceA, err := New(ctx, &deploymentStore, notifier, mockApiClients.Deployments(), DispatchLeastInFlight)
+	if err != nil {
+		t.Fatal(err)
+	}
+	instanceB, err := New(ctx, &deploymentStore, notifier, mockApiClients.Deployments(), DispatchLeastInFlight)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// deployd connects to instance A.
+	b := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	pb.RegisterDispatchServer(srv, instanceA)
+	go srv.Serve(b)
+	defer srv.Stop()
+
+	conn, _ := grpc.DialContext(ctx, "bufnet", grpc.WithContextDialer(bufDialer(b)), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	deploymentsClient, err := pb.NewDispatchClient(conn).Deployments(ctx, &pb.GetDeploymentOpts{Cluster: "shared"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(1 * time.Second)
+
+	request := &pb.DeploymentRequest{
+		ID:       "1",
+		Team:     "aura",
+		Cluster:  "shared",
+		Deadline: pb.TimeAsTimestamp(time.Now().Add(time.Minute)),
+	}
+
+	t.Run("deployment request sent to another instance is dispatched by the instance connected to the cluster", func(t *testing.T) {
+		err := instanceB.SendDeploymentRequest(ctx, request)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := deploymentsClient.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.GetID() != "1" {
+			t.Errorf("expected deployment request 1, got %q", r.GetID())
+		}
+	})
+
+	t.Run("deployment status reported to one instance is streamed from all instances", func(t *testing.T) {
+		streamCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		statuses := make(chan *pb.DeploymentStatus, 16)
+		go instanceB.StreamStatus(streamCtx, statuses)
+		time.Sleep(100 * time.Millisecond)
+
+		err := instanceA.HandleDeploymentStatus(ctx, pb.NewInProgressStatus(request, "working"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case st := <-statuses:
+			if st.GetRequest().GetID() != "1" || st.GetMessage() != "working" {
+				t.Errorf("unexpected status %q for deployment %q", st.GetMessage(), st.GetRequest().GetID())
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("deployment status was not streamed from other instance")
+		}
+	})
+
+	t.Run("cancel request sent to another instance is dispatched by the instance connected to the cluster", func(t *testing.T) {
+		err := instanceB.SendCancelRequest(ctx, &pb.DeploymentRequest{
+			ID:      "1",
+			Team:    "aura",
+			Cluster: "shared",
+			Cancel:  true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := deploymentsClient.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.GetID() != "1" || !r.GetCancel() {
+			t.Errorf("expected cancel request for deployment 1, got %v", r)
+		}
+	})
+}
+
+func TestMultipleDeploydInstances(t *testing.T) {
+	ctx := context.Background()
+	_, _ = telemetry.New(ctx, "test", "")
+
+	deploymentStore := database.MockDeploymentStore{}
+	deploymentStore.On("HistoricDeployments", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	deploymentStore.On("WriteDeploydInstance", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("DeleteDeploydInstance", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("WriteDispatchedDeploymentRequest", mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("DeleteDispatchedDeploymentRequest", mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("QueuedDeploymentRequests", mock.Anything, mock.Anything).Return([]database.QueuedDeploymentRequest{}, nil)
+	deploymentStore.On("QueueDeploymentRequest", mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("DeploydInstances", mock.Anything, "test", mock.Anything).Return([]string{"b"}, nil)
+	// Deployment 4 has finished, but its final status did not reach this hookd instance.
+	deploymentStore.On("DispatchedDeploymentRequests", mock.Anything, "test").Return([]database.DispatchedDeploymentRequest{
+		{DeploymentID: "1", Cluster: "test", InstanceID: "a"},
+	}, nil)
+
+	mockApiClients, _ := apiclient.NewMockClient(t)
+
+	ds, err := New(ctx, &deploymentStore, newMemoryNotifier(), mockApiClients.Deployments(), DispatchRoundRobin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds.(*dispatchServer).redispatchDelay = 0
+
+	b := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	pb.RegisterDispatchServer(srv, ds)
+	go srv.Serve(b)
+	defer srv.Stop()
+
+	conn, _ := grpc.DialContext(ctx, "bufnet", grpc.WithContextDialer(bufDialer(b)), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	client := pb.NewDispatchClient(conn)
+
+	ctxA, cancelA := context.WithCancel(ctx)
+	defer cancelA()
+	instanceA, err := client.Deployments(ctxA, &pb.GetDeploymentOpts{Cluster: "test", InstanceID: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	instanceB, err := client.Deployments(ctx, &pb.GetDeploymentOpts{Cluster: "test", InstanceID: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	t.Run("instance IDs must be unique within a cluster", func(t *testing.T) {
+		duplicate, err := client.Deployments(ctx, &pb.GetDeploymentOpts{Cluster: "test", InstanceID: "a"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = duplicate.Recv()
+		if err == nil {
+			t.Error("expected duplicate instance to be rejected")
+		}
+	})
+
+	t.Run("deployment requests are distributed among instances", func(t *testing.T) {
+		for _, request := range []*pb.DeploymentRequest{
+			{ID: "1", Team: "aura", Cluster: "test"},
+			{ID: "2", Team: "nais", Cluster: "test"},
+		} {
+			err := ds.SendDeploymentRequest(ctx, request)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		r, err := instanceA.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.GetID() != "1" {
+			t.Errorf("expected instance a to receive deployment request 1, got %q", r.GetID())
+		}
+
+		r, err = instanceB.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.GetID() != "2" {
+			t.Errorf("expected instance b to receive deployment request 2, got %q", r.GetID())
+		}
+	})
+
+	t.Run("deployment requests for a team go to the instance running its unfinished deployments", func(t *testing.T) {
+		// Round-robin would pick instance a next, but deployment 2 for the same team is still running on b.
+		err := ds.SendDeploymentRequest(ctx, &pb.DeploymentRequest{ID: "3", Team: "nais", Cluster: "test"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := instanceB.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.GetID() != "3" {
+			t.Errorf("expected instance b to receive deployment request 3, got %q", r.GetID())
+		}
+	})
+
+	t.Run("unfinished deployments are dispatched to another instance on disconnect", func(t *testing.T) {
+		err := ds.SendDeploymentRequest(ctx, &pb.DeploymentRequest{ID: "4", Team: "aura", Cluster: "test"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := instanceA.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.GetID() != "4" {
+			t.Errorf("expected instance a to receive deployment request 4, got %q", r.GetID())
+		}
+
+		cancelA()
+
+		r, err = instanceB.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.GetID() != "1" || !r.GetResume() {
+			t.Errorf("expected instance b to resume deployment request 1, got %q", r.GetID())
+		}
+
+		received := make(chan *pb.DeploymentRequest, 1)
+		go func() {
+			r, err := instanceB.Recv()
+			if err == nil {
+				received <- r
+			}
+		}()
+		select {
+		case r := <-received:
+			t.Errorf("finished deployment %q was dispatched again", r.GetID())
+		case <-time.After(200 * time.Millisecond):
+		}
+	})
+}
+
+func TestRedispatchAfterReconnect(t *testing.T) {
+	deploymentStore := database.MockDeploymentStore{}
+	deploymentStore.On("DeploydInstances", mock.Anything, "test", mock.Anything).Return([]string{"a"}, nil).Once()
+
+	ds := &dispatchServer{db: &deploymentStore}
+	conn := newClusterConnection(&pb.GetDeploymentOpts{Cluster: "test", InstanceID: "a"})
+	conn.track(&pb.DeploymentRequest{ID: "1", Team: "aura", Cluster: "test"})
+
+	// The instance is connected again, so it is still running the deployment, and nothing is dispatched or queued.
+	ds.redispatch(conn)
+	deploymentStore.AssertExpectations(t)
+}
+
+func TestResumeDeployments(t *testing.T) {
+	ctx := context.Background()
+	_, _ = telemetry.New(ctx, "test", "")
+
+	startupTime := time.Now().UTC()
+	cluster := "test"
+	deadline := pb.TimeAsTimestamp(startupTime.Add(time.Minute))
+
+	// Deployment "unfinished" was sent to this instance before it restarted, "orphaned" to an instance that is gone,
+	// and "running" to another instance that is still connected to some hookd instance.
+	dispatched := make([]database.DispatchedDeploymentRequest, 0)
+	for id, instanceID := range map[string]string{"unfinished": "a", "orphaned": "gone", "running": "b"} {
+		row, err := database_mapper.DispatchedDeploymentRequest(&pb.DeploymentRequest{ID: id, Cluster: cluster, Deadline: deadline})
+		if err != nil {
+			t.Fatal(err)
+		}
+		row.InstanceID = instanceID
+		dispatched = append(dispatched, row)
+	}
+
+	deploymentStore := database.MockDeploymentStore{}
+	deploymentStore.On("HistoricDeployments", mock.Anything, cluster, startupTime).Return([]*database.Deployment{
+		{ID: "unfinished", Cluster: &cluster},
+		{ID: "orphaned", Cluster: &cluster},
+		{ID: "running", Cluster: &cluster},
+		{ID: "unknown", Cluster: &cluster},
+	}, nil)
+	deploymentStore.On("DispatchedDeploymentRequests", mock.Anything, cluster).Return(dispatched, nil)
+	deploymentStore.On("DeploydInstances", mock.Anything, cluster, mock.Anything).Return([]string{"a", "b"}, nil)
+	deploymentStore.On("WriteDeploydInstance", mock.Anything, cluster, "a").Return(nil)
+	deploymentStore.On("DeleteDeploydInstance", mock.Anything, cluster, "a").Return(nil)
+	deploymentStore.On("ClaimDispatchedDeploymentRequest", mock.Anything, "unfinished", "a", "a").Return(nil).Once()
+	deploymentStore.On("ClaimDispatchedDeploymentRequest", mock.Anything, "orphaned", "gone", "a").Return(nil).Once()
+	deploymentStore.On("QueueDeploymentRequest", mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("WriteDispatchedDeploymentRequest", mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("DeleteDispatchedDeploymentRequest", mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("QueuedDeploymentRequests", mock.Anything, mock.Anything).Return([]database.QueuedDeploymentRequest{}, nil)
+	deploymentStore.On("WriteDeploymentStatus", mock.Anything, mock.MatchedBy(func(st database.DeploymentStatus) bool {
+		return (st.DeploymentID == "unfinished" || st.DeploymentID == "orphaned") && st.Status == pb.DeploymentState_in_progress.String()
+	})).Return(nil).Twice()
+	invalidated := make(chan struct{})
+	deploymentStore.On("WriteDeploymentStatus", mock.Anything, mock.MatchedBy(func(st database.DeploymentStatus) bool {
+		return st.DeploymentID == "unknown" && st.Status == pb.DeploymentState_inactive.String()
+	})).Run(func(args mock.Arguments) {
+		close(invalidated)
+	}).Return(nil).Once()
+
+	mockApiClients, mockApiServer := apiclient.NewMockClient(t)
+	mockApiServer.Deployments.EXPECT().CreateDeploymentStatus(mock.Anything, mock.Anything).Return(nil, nil)
+
+	ds, err := New(ctx, &deploymentStore, newMemoryNotifier(), mockApiClients.Deployments(), DispatchLeastInFlight)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	pb.RegisterDispatchServer(srv, ds)
+	go srv.Serve(b)
+	defer srv.Stop()
+
+	conn, _ := grpc.DialContext(ctx, "bufnet", grpc.WithContextDialer(bufDialer(b)), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	deploymentsClient, err := pb.NewDispatchClient(conn).Deployments(ctx, &pb.GetDeploymentOpts{
+		Cluster:     cluster,
+		StartupTime: pb.TimeAsTimestamp(startupTime),
+		InstanceID:  "a",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resumed := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		r, err := deploymentsClient.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !r.GetResume() {
+			t.Errorf("expected deployment %q to be resumed", r.GetID())
+		}
+		if !r.GetDeadline().AsTime().Equal(deadline.AsTime()) {
+			t.Errorf("expected resumed deployment to keep its deadline")
+		}
+		resumed[r.GetID()] = true
+	}
+	if !resumed["unfinished"] || !resumed["orphaned"] {
+		t.Errorf("expected unfinished and orphaned deployments to be resumed, got %v", resumed)
+	}
+
+	select {
+	case <-invalidated:
+	case <-time.After(5 * time.Second):
+		t.Error("expected deployment without a stored request to be marked as inactive")
+	}
+
+	deploymentStore.AssertNotCalled(t, "ClaimDispatchedDeploymentRequest", mock.Anything, "running", mock.Anything, mock.Anything)
+	deploymentStore.AssertNotCalled(t, "WriteDeploymentStatus", mock.Anything, mock.MatchedBy(func(st database.DeploymentStatus) bool {
+		return st.DeploymentID == "running"
+	}))
+}
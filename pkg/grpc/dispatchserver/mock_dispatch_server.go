@@ -4,6 +4,7 @@ package dispatchserver
 
 import (
 	context "context"
+	time "time"
 
 	pb "github.com/nais/deploy/pkg/pb"
 	mock "github.com/stretchr/testify/mock"
@@ -29,6 +30,11 @@ func (_m *MockDispatchServer) Deployments(_a0 *pb.GetDeploymentOpts, _a1 pb.Disp
 	return r0
 }
 
+// ExpireQueue provides a mock function with given fields: ctx, interval
+func (_m *MockDispatchServer) ExpireQueue(ctx context.Context, interval time.Duration) {
+	_m.Called(ctx, interval)
+}
+
 // HandleDeploymentStatus provides a mock function with given fields: ctx, status
 func (_m *MockDispatchServer) HandleDeploymentStatus(ctx context.Context, status *pb.DeploymentStatus) error {
 	ret := _m.Called(ctx, status)
@@ -69,6 +75,20 @@ func (_m *MockDispatchServer) ReportStatus(_a0 context.Context, _a1 *pb.Deployme
 	return r0, r1
 }
 
+// SendCancelRequest provides a mock function with given fields: ctx, request
+func (_m *MockDispatchServer) SendCancelRequest(ctx context.Context, request *pb.DeploymentRequest) error {
+	ret := _m.Called(ctx, request)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *pb.DeploymentRequest) error); ok {
+		r0 = rf(ctx, request)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SendDeploymentRequest provides a mock function with given fields: ctx, deployment
 func (_m *MockDispatchServer) SendDeploymentRequest(ctx context.Context, deployment *pb.DeploymentRequest) error {
 	ret := _m.Called(ctx, deployment)
@@ -83,6 +103,32 @@ func (_m *MockDispatchServer) SendDeploymentRequest(ctx context.Context, deploym
 	return r0
 }
 
+// SendPlanRequest provides a mock function with given fields: ctx, request
+func (_m *MockDispatchServer) SendPlanRequest(ctx context.Context, request *pb.DeploymentRequest) (*pb.DeploymentPlan, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *pb.DeploymentPlan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *pb.DeploymentRequest) (*pb.DeploymentPlan, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *pb.DeploymentRequest) *pb.DeploymentPlan); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*pb.DeploymentPlan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *pb.DeploymentRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // StreamStatus provides a mock function with given fields: _a0, _a1
 func (_m *MockDispatchServer) StreamStatus(_a0 context.Context, _a1 chan<- *pb.DeploymentStatus) {
 	_m.Called(_a0, _a1)
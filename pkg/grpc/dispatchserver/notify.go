@@ -0,0 +1,191 @@
+package dispatchserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nais/deploy/pkg/hookd/database"
+	"github.com/nais/deploy/pkg/pb"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// How long to wait for deployd to accept a cancel request forwarded by another hookd instance.
+const forwardCancelTimeout = 30 * time.Second
+
+// Act on notifications from all hookd instances, including this one.
+func (s *dispatchServer) handleNotifications(notifications <-chan database.Notification) {
+	for notification := range notifications {
+		switch notification.Channel {
+		case database.ChannelDeploymentStatus:
+			origin, st, err := decodeStatusNotification(notification.Payload)
+			if err != nil {
+				log.Errorf("Decode deployment status notification: %s", err)
+				continue
+			}
+			if origin == s.id {
+				// Already handled when it was published.
+				continue
+			}
+			s.broadcastStatus(st)
+
+		case database.ChannelDeploymentQueue:
+			conn := s.pickConnection(notification.Payload, "")
+			if conn == nil {
+				continue
+			}
+			select {
+			case conn.flush <- struct{}{}:
+			default:
+				// flush already pending
+			}
+
+		case database.ChannelDeploymentCancel:
+			request := &pb.DeploymentRequest{}
+			err := protojson.Unmarshal([]byte(notification.Payload), request)
+			if err != nil {
+				log.Errorf("Decode cancel request notification: %s", err)
+				continue
+			}
+			// Send to the instance running the deployment, or to every instance if it is not known.
+			conns := s.connections(request.GetCluster())
+			if conn := s.runningConnection(request); conn != nil {
+				conns = []*clusterConnection{conn}
+			}
+			for _, conn := range conns {
+				go func() {
+					ctx, cancel := context.WithTimeout(context.Background(), forwardCancelTimeout)
+					defer cancel()
+					err := sendCancel(ctx, conn, request)
+					if err != nil {
+						log.WithFields(request.LogFields()).Errorf("Forward cancel request: %s", err)
+					}
+				}()
+			}
+		}
+	}
+}
+
+// Send a deployment status to this instance's status subscribers.
+// If the deployment has finished, its trace is ended, and it is no longer considered in flight on any deployd instance.
+func (s *dispatchServer) broadcastStatus(st *pb.DeploymentStatus) {
+	s.statusStreamsLock.RLock()
+	for _, ch := range s.statusStreams {
+		ch <- st
+	}
+	s.statusStreamsLock.RUnlock()
+
+	if st.GetState().Finished() {
+		deployID := st.GetRequest().GetID()
+		for _, conn := range s.connections(st.GetRequest().GetCluster()) {
+			conn.release(deployID)
+		}
+		s.traceSpansLock.Lock()
+		if span, ok := s.traceSpans[deployID]; ok {
+			span.End()
+			delete(s.traceSpans, deployID)
+		}
+		s.traceSpansLock.Unlock()
+	}
+}
+
+// Send a deployment status to the other hookd instances.
+// The payload is prefixed with the ID of this instance, so that it can skip its own notifications.
+// Kubernetes resources are left out of the attached request, as notification payloads are limited in size.
+func (s *dispatchServer) publishStatus(ctx context.Context, st *pb.DeploymentStatus) error {
+	st = proto.Clone(st).(*pb.DeploymentStatus)
+	if st.GetRequest() != nil {
+		st.Request.Kubernetes = nil
+	}
+
+	payload, err := protojson.Marshal(st)
+	if err != nil {
+		return err
+	}
+
+	prefix := s.id + " "
+	limit := database.MaxNotificationPayload - len(prefix)
+	if len(payload) >= limit {
+		payload, err = truncateStatus(st, payload, limit)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.notifier.Notify(ctx, database.ChannelDeploymentStatus, prefix+string(payload))
+}
+
+// Split a deployment status notification into the ID of the instance that published it, and the status.
+func decodeStatusNotification(payload string) (string, *pb.DeploymentStatus, error) {
+	origin, encoded, found := strings.Cut(payload, " ")
+	if !found {
+		return "", nil, fmt.Errorf("missing origin")
+	}
+
+	st := &pb.DeploymentStatus{}
+	err := protojson.Unmarshal([]byte(encoded), st)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return origin, st, nil
+}
+
+// Shorten the message of a deployment status until its encoded form is shorter than limit.
+// Characters that must be escaped make the encoded message longer than the message itself,
+// so the message is cut in proportion to its encoded size, and the payload measured again until it fits.
+func truncateStatus(st *pb.DeploymentStatus, payload []byte, limit int) ([]byte, error) {
+	message := st.Message
+
+	st.Message = "..."
+	base, err := protojson.Marshal(st)
+	if err != nil {
+		return nil, err
+	}
+
+	budget := limit - len(base) - 1
+	if budget < 0 {
+		return nil, fmt.Errorf("deployment status is too large to publish (%d bytes)", len(payload))
+	}
+
+	for len(payload) >= limit {
+		encoded := len(payload) - len(base)
+		keep := len(message) * budget / encoded
+		if keep >= len(message) {
+			keep = len(message) - 1
+		}
+		message = strings.ToValidUTF8(message[:keep], "")
+		st.Message = message + "..."
+		payload, err = protojson.Marshal(st)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return payload, nil
+}
+
+// Ask every hookd instance to forward a cancel request to the cluster, if it is connected to that instance.
+func (s *dispatchServer) publishCancel(ctx context.Context, request *pb.DeploymentRequest) error {
+	payload, err := protojson.Marshal(&pb.DeploymentRequest{
+		ID:      request.GetID(),
+		Team:    request.GetTeam(),
+		Cluster: request.GetCluster(),
+		Cancel:  true,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.notifier.Notify(ctx, database.ChannelDeploymentCancel, string(payload))
+	if err != nil {
+		return fmt.Errorf("forward cancel request to other instances: %w", err)
+	}
+
+	log.WithFields(request.LogFields()).Debugf("Cancel request forwarded to other instances")
+
+	return nil
+}
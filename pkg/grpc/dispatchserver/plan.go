@@ -0,0 +1,65 @@
+package dispatchserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nais/deploy/pkg/pb"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SendPlanRequest asks a deployd instance what a deployment request would change, and waits until it answers.
+// The request must have its Plan flag set. Plans are neither stored nor queued, so the cluster
+// must be connected to this hookd instance; otherwise, the client is asked to try again.
+func (s *dispatchServer) SendPlanRequest(ctx context.Context, request *pb.DeploymentRequest) (*pb.DeploymentPlan, error) {
+	result := make(chan *pb.DeploymentPlan, 1)
+
+	s.plansLock.Lock()
+	s.plans[request.GetID()] = result
+	s.plansLock.Unlock()
+
+	defer func() {
+		s.plansLock.Lock()
+		delete(s.plans, request.GetID())
+		s.plansLock.Unlock()
+	}()
+
+	err := s.dispatch(ctx, request)
+	if errors.Is(err, errClusterOffline) {
+		return nil, status.Errorf(codes.Unavailable, "cluster '%s' is not connected to this instance; try again later", request.GetCluster())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("send plan request: %w", err)
+	}
+
+	log.WithFields(request.LogFields()).Debugf("Plan request sent to deployd")
+
+	select {
+	case plan := <-result:
+		return plan, nil
+	case <-ctx.Done():
+		return nil, status.Errorf(codes.DeadlineExceeded, "cluster '%s' did not answer the plan request in time", request.GetCluster())
+	}
+}
+
+// ReportPlan hands the answer to a plan request to the client waiting for it.
+func (s *dispatchServer) ReportPlan(ctx context.Context, plan *pb.DeploymentPlan) (*pb.ReportStatusOpts, error) {
+	s.plansLock.Lock()
+	result, ok := s.plans[plan.GetID()]
+	s.plansLock.Unlock()
+
+	if !ok {
+		return nil, status.Errorf(codes.FailedPrecondition, "plan %s is not being waited for; the client may have given up", plan.GetID())
+	}
+
+	// The channel is buffered, and only one answer is expected.
+	select {
+	case result <- plan:
+	default:
+	}
+
+	return &pb.ReportStatusOpts{}, nil
+}
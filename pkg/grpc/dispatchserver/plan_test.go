@@ -0,0 +1,90 @@
+package dispatchserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nais/api/pkg/apiclient"
+	"github.com/nais/deploy/pkg/hookd/database"
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestPlanRequests(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Plans are never written to the database; any such call fails the test.
+	deploymentStore := database.MockDeploymentStore{}
+	deploymentStore.On("HistoricDeployments", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	deploymentStore.On("WriteDeploydInstance", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("DeleteDeploydInstance", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deploymentStore.On("QueuedDeploymentRequests", mock.Anything, mock.Anything).Return(nil, nil)
+
+	mockApiClients, _ := apiclient.NewMockClient(t)
+
+	ds, err := New(ctx, &deploymentStore, newMemoryNotifier(), mockApiClients.Deployments(), DispatchLeader)
+	assert.NoError(t, err)
+
+	request := &pb.DeploymentRequest{
+		ID:       "plan",
+		Cluster:  "dev",
+		Deadline: pb.TimeAsTimestamp(time.Now().Add(time.Minute)),
+		Plan:     true,
+	}
+
+	t.Run("cluster not connected", func(t *testing.T) {
+		_, err := ds.SendPlanRequest(ctx, request)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+
+	t.Run("answer without a waiting client", func(t *testing.T) {
+		_, err := ds.ReportPlan(ctx, &pb.DeploymentPlan{ID: "unknown"})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	b := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	pb.RegisterDispatchServer(srv, ds)
+	go srv.Serve(b)
+	defer srv.Stop()
+
+	conn, err := grpc.DialContext(ctx, "bufnet", grpc.WithContextDialer(bufDialer(b)), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	client := pb.NewDispatchClient(conn)
+	deploymentsClient, err := client.Deployments(ctx, &pb.GetDeploymentOpts{Cluster: "dev"})
+	assert.NoError(t, err)
+
+	// Stand in for deployd.
+	go func() {
+		req, err := deploymentsClient.Recv()
+		if err != nil {
+			return
+		}
+		_, _ = client.ReportPlan(ctx, &pb.DeploymentPlan{
+			ID:      req.GetID(),
+			Cluster: req.GetCluster(),
+			Resources: []*pb.ResourceDiff{
+				{Resource: &pb.KubernetesResource{Version: "v1", Kind: "ConfigMap", Name: "foo"}, Change: pb.ResourceChange_added},
+			},
+		})
+	}()
+
+	t.Run("plan is answered by deployd", func(t *testing.T) {
+		var plan *pb.DeploymentPlan
+		assert.Eventually(t, func() bool {
+			plan, err = ds.SendPlanRequest(ctx, request)
+			return status.Code(err) != codes.Unavailable
+		}, 5*time.Second, 10*time.Millisecond, "cluster never connected")
+		assert.NoError(t, err)
+		assert.Equal(t, "plan", plan.GetID())
+		assert.Len(t, plan.GetResources(), 1)
+	})
+}
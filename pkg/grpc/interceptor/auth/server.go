@@ -51,9 +51,10 @@ func NewServerInterceptor(apiKeyStore database.ApiKeyStore, tokenValidator Token
 }
 
 func (s *ServerInterceptor) UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
-	_, ok := req.(*pb.DeploymentRequest)
-	if !ok {
-		return nil, status.Errorf(codes.InvalidArgument, "requests to this endpoint must be DeploymentRequest")
+	switch req.(type) {
+	case *pb.DeploymentRequest, *pb.ListDeploymentsRequest, *pb.GetDeploymentRequest, *pb.DiffDeploymentsRequest, *pb.RedeployRequest, *pb.TeardownPreviewRequest, *pb.ApprovalRequest:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported request type %T", req)
 	}
 
 	md, ok := metadata.FromIncomingContext(ctx)
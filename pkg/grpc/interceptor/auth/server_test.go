@@ -117,6 +117,15 @@ func TestServerInterceptorJWT(t *testing.T) {
 		}
 	})
 
+	t.Run("requests about previous deployments, preview environments and approvals", func(t *testing.T) {
+		for _, req := range []interface{}{&pb.ListDeploymentsRequest{}, &pb.GetDeploymentRequest{}, &pb.DiffDeploymentsRequest{}, &pb.RedeployRequest{}, &pb.TeardownPreviewRequest{}, &pb.ApprovalRequest{}} {
+			_, err := i.UnaryServerInterceptor(ctx, req, nil, handler)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+
 	t.Run("invalid jwt", func(t *testing.T) {
 		ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{
 			"jwt":  []string{"invalid"},
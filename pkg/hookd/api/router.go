@@ -10,7 +10,10 @@ import (
 	chi_middleware "github.com/go-chi/chi/middleware"
 	gh "github.com/google/go-github/v41/github"
 	api_v1_apikey "github.com/nais/deploy/pkg/hookd/api/v1/apikey"
+	api_v1_approval "github.com/nais/deploy/pkg/hookd/api/v1/approval"
+	api_v1_freeze "github.com/nais/deploy/pkg/hookd/api/v1/freeze"
 	api_v1_provision "github.com/nais/deploy/pkg/hookd/api/v1/provision"
+	"github.com/nais/deploy/pkg/hookd/approval"
 	"github.com/nais/deploy/pkg/hookd/database"
 	"github.com/nais/deploy/pkg/hookd/logproxy"
 	"github.com/nais/deploy/pkg/hookd/middleware"
@@ -24,8 +27,11 @@ type Middleware func(http.Handler) http.Handler
 
 type Config struct {
 	ApiKeyStore           database.ApiKeyStore
+	ApprovalStore         database.ApprovalStore
+	Approvals             *approval.Gate
 	BaseURL               string
 	DispatchServer        dispatchserver.DispatchServer
+	FreezeStore           database.FreezeStore
 	InstallationClient    *gh.Client
 	MetricsPath           string
 	PSKValidator          func(http.Handler) http.Handler
@@ -42,6 +48,15 @@ func New(cfg Config) chi.Router {
 		APIKeyStorage: cfg.ApiKeyStore,
 	}
 
+	approvalHandler := &api_v1_approval.Handler{
+		ApprovalStore: cfg.ApprovalStore,
+		Approvals:     cfg.Approvals,
+	}
+
+	freezeHandler := &api_v1_freeze.Handler{
+		FreezeStore: cfg.FreezeStore,
+	}
+
 	provisionHandler := &api_v1_provision.Handler{
 		APIKeyStorage: cfg.ApiKeyStore,
 		SecretKey:     cfg.ProvisionKey,
@@ -99,6 +114,12 @@ func New(cfg Config) chi.Router {
 				r.Use(cfg.PSKValidator)
 				r.Get("/apikey/{team}", apiKeyHandler.GetTeamApiKey)
 				r.Post("/apikey/{team}", apiKeyHandler.RotateTeamApiKey)
+				r.Get("/freeze", freezeHandler.ListFreezes)
+				r.Post("/freeze", freezeHandler.CreateFreeze)
+				r.Delete("/freeze/{id}", freezeHandler.DeleteFreeze)
+				r.Get("/approval", approvalHandler.ListPendingApprovals)
+				r.Post("/approval/{id}/approve", approvalHandler.Approve)
+				r.Post("/approval/{id}/reject", approvalHandler.Reject)
 			})
 		}
 	})
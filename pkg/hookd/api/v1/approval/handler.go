@@ -0,0 +1,111 @@
+package api_v1_approval
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/nais/deploy/pkg/hookd/approval"
+	"github.com/nais/deploy/pkg/hookd/database"
+	"github.com/nais/deploy/pkg/hookd/freeze"
+	"github.com/nais/deploy/pkg/hookd/middleware"
+	log "github.com/sirupsen/logrus"
+)
+
+type Handler struct {
+	ApprovalStore database.ApprovalStore
+	// Nil if no cluster requires approval.
+	Approvals *approval.Gate
+}
+
+// Request approves or rejects a deployment.
+type Request struct {
+	// Who approves or rejects the deployment. Deployments can't be approved by whoever requested them.
+	Approver string `json:"approver"`
+	Comment  string `json:"comment"`
+}
+
+// Response is the status of the deployment after the decision.
+type Response struct {
+	State   string `json:"state"`
+	Message string `json:"message"`
+}
+
+type errorResponse struct {
+	Message string `json:"message"`
+}
+
+func renderError(w http.ResponseWriter, code int, format string, args ...any) {
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(errorResponse{Message: fmt.Sprintf(format, args...)})
+}
+
+// ListPendingApprovals returns deployments waiting for approval, oldest first.
+func (h *Handler) ListPendingApprovals(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(middleware.RequestLogFields(r))
+
+	approvals, err := h.ApprovalStore.PendingDeploymentApprovals(r.Context())
+	if err != nil {
+		logger.Errorf("unable to list pending approvals: %s", err)
+		renderError(w, http.StatusBadGateway, "unable to list pending approvals")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(approvals)
+}
+
+// Approve dispatches a deployment that is waiting for approval.
+func (h *Handler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, true)
+}
+
+// Reject stops a deployment that is waiting for approval from being dispatched.
+func (h *Handler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, false)
+}
+
+func (h *Handler) decide(w http.ResponseWriter, r *http.Request, approve bool) {
+	logger := log.WithFields(middleware.RequestLogFields(r))
+
+	request := &Request{}
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, "unable to decode request: %s", err)
+		return
+	}
+
+	var frozen *freeze.FrozenError
+
+	// The console has already checked that the approver may act for the approving team.
+	st, err := h.Approvals.Decide(r.Context(), approval.Decision{
+		DeploymentID: chi.URLParam(r, "id"),
+		Approve:      approve,
+		Approver:     request.Approver,
+		Comment:      request.Comment,
+	})
+	switch {
+	case err == nil:
+	case errors.Is(err, approval.ErrApproverRequired):
+		renderError(w, http.StatusBadRequest, "%s", err)
+		return
+	case errors.Is(err, approval.ErrNotPending), errors.As(err, &frozen):
+		renderError(w, http.StatusConflict, "%s", err)
+		return
+	case errors.Is(err, approval.ErrSelfApproval), errors.Is(err, approval.ErrNotApprover):
+		renderError(w, http.StatusForbidden, "%s", err)
+		return
+	default:
+		logger.Errorf("unable to decide deployment approval: %s", err)
+		renderError(w, http.StatusBadGateway, "unable to decide deployment approval")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(Response{
+		State:   st.GetState().String(),
+		Message: st.GetMessage(),
+	})
+}
@@ -0,0 +1,96 @@
+package api_v1_approval_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nais/deploy/pkg/grpc/dispatchserver"
+	"github.com/nais/deploy/pkg/hookd/api"
+	"github.com/nais/deploy/pkg/hookd/approval"
+	"github.com/nais/deploy/pkg/hookd/database"
+	database_mapper "github.com/nais/deploy/pkg/hookd/database/mapper"
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newHandler(store database.ApprovalStore, dispatcher dispatchserver.DispatchServer) http.Handler {
+	return api.New(api.Config{
+		ApprovalStore: store,
+		Approvals:     approval.NewGate(store, dispatcher, map[string]string{"prod-gcp": ""}),
+		MetricsPath:   "/metrics",
+		PSKValidator: func(h http.Handler) http.Handler {
+			return h
+		},
+	})
+}
+
+func TestApprovalHandler(t *testing.T) {
+	pending, err := database_mapper.DeploymentApproval(&pb.DeploymentRequest{ID: "1", Team: "aura", Cluster: "prod-gcp", DeployerUsername: "alice"}, "aura")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("list pending approvals", func(t *testing.T) {
+		store := database.NewMockApprovalStore(t)
+		store.On("PendingDeploymentApprovals", mock.Anything).Return([]*database.DeploymentApproval{&pending}, nil).Once()
+
+		request := httptest.NewRequest(http.MethodGet, "/internal/api/v1/console/approval", nil)
+		recorder := httptest.NewRecorder()
+		newHandler(store, nil).ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"deploymentID":"1"`)
+		assert.Contains(t, recorder.Body.String(), `"requestedBy":"alice"`)
+		assert.NotContains(t, recorder.Body.String(), `"request"`)
+	})
+
+	t.Run("reject deployment", func(t *testing.T) {
+		rejected := pending
+		rejected.State = database.ApprovalRejected
+
+		store := database.NewMockApprovalStore(t)
+		store.On("DeploymentApproval", mock.Anything, "1").Return(&pending, nil).Once()
+		store.On("DecideDeploymentApproval", mock.Anything, "1", database.ApprovalRejected, "bob", "not today", mock.Anything).Return(&rejected, nil).Once()
+
+		dispatcher := &dispatchserver.MockDispatchServer{}
+		dispatcher.On("HandleDeploymentStatus", mock.Anything, mock.Anything).Return(nil).Once()
+
+		body := `{"approver":"bob","comment":"not today"}`
+		request := httptest.NewRequest(http.MethodPost, "/internal/api/v1/console/approval/1/reject", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		newHandler(store, dispatcher).ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"state":"failure","message":"deployment was rejected by bob (not today)"}`, recorder.Body.String())
+	})
+
+	t.Run("deployer can't approve", func(t *testing.T) {
+		store := database.NewMockApprovalStore(t)
+		store.On("DeploymentApproval", mock.Anything, "1").Return(&pending, nil).Once()
+
+		body := `{"approver":"alice"}`
+		request := httptest.NewRequest(http.MethodPost, "/internal/api/v1/console/approval/1/approve", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		newHandler(store, nil).ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+
+	t.Run("unknown deployment", func(t *testing.T) {
+		store := database.NewMockApprovalStore(t)
+		store.On("DeploymentApproval", mock.Anything, "2").Return(nil, database.ErrNotFound).Once()
+
+		body := `{"approver":"bob"}`
+		request := httptest.NewRequest(http.MethodPost, "/internal/api/v1/console/approval/2/approve", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		newHandler(store, nil).ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+	})
+}
@@ -0,0 +1,130 @@
+package api_v1_freeze
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/nais/deploy/pkg/hookd/database"
+	"github.com/nais/deploy/pkg/hookd/freeze"
+	"github.com/nais/deploy/pkg/hookd/middleware"
+	log "github.com/sirupsen/logrus"
+)
+
+type Handler struct {
+	FreezeStore database.FreezeStore
+}
+
+// Request creates a freeze. See database.Freeze for how the fields are used.
+type Request struct {
+	Team     string     `json:"team"`
+	Cluster  string     `json:"cluster"`
+	Reason   string     `json:"reason"`
+	Start    *time.Time `json:"start"`
+	End      *time.Time `json:"end"`
+	Schedule string     `json:"schedule"`
+	Duration string     `json:"duration"`
+	Timezone string     `json:"timezone"`
+	Strict   bool       `json:"strict"`
+}
+
+type errorResponse struct {
+	Message string `json:"message"`
+}
+
+func renderError(w http.ResponseWriter, code int, format string, args ...any) {
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(errorResponse{Message: fmt.Sprintf(format, args...)})
+}
+
+// ListFreezes returns all freezes, newest first.
+func (h *Handler) ListFreezes(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(middleware.RequestLogFields(r))
+
+	freezes, err := h.FreezeStore.Freezes(r.Context())
+	if err != nil {
+		logger.Errorf("unable to list freezes: %s", err)
+		renderError(w, http.StatusBadGateway, "unable to list freezes")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(freezes)
+}
+
+// CreateFreeze stores a new freeze, and returns it.
+func (h *Handler) CreateFreeze(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(middleware.RequestLogFields(r))
+
+	request := &Request{}
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, "unable to decode request: %s", err)
+		return
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		logger.Errorf("unable to generate freeze ID: %s", err)
+		renderError(w, http.StatusInternalServerError, "unable to generate freeze ID")
+		return
+	}
+
+	f := database.Freeze{
+		ID:       id.String(),
+		Team:     request.Team,
+		Cluster:  request.Cluster,
+		Reason:   request.Reason,
+		Start:    request.Start,
+		End:      request.End,
+		Schedule: request.Schedule,
+		Duration: request.Duration,
+		Timezone: request.Timezone,
+		Strict:   request.Strict,
+		Created:  time.Now(),
+	}
+	if len(f.Timezone) == 0 {
+		f.Timezone = "UTC"
+	}
+
+	err = freeze.Validate(f)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, "invalid freeze: %s", err)
+		return
+	}
+
+	err = h.FreezeStore.WriteFreeze(r.Context(), f)
+	if err != nil {
+		logger.Errorf("unable to store freeze: %s", err)
+		renderError(w, http.StatusBadGateway, "unable to store freeze")
+		return
+	}
+
+	logger.Infof("Created deployment freeze %s for team '%s' and cluster '%s': %s", f.ID, f.Team, f.Cluster, f.Reason)
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(f)
+}
+
+// DeleteFreeze removes a freeze, so that it no longer blocks deployments.
+func (h *Handler) DeleteFreeze(w http.ResponseWriter, r *http.Request) {
+	logger := log.WithFields(middleware.RequestLogFields(r))
+
+	id := chi.URLParam(r, "id")
+	err := h.FreezeStore.DeleteFreeze(r.Context(), id)
+	if database.IsErrNotFound(err) {
+		renderError(w, http.StatusNotFound, "freeze %s not found", id)
+		return
+	} else if err != nil {
+		logger.Errorf("unable to delete freeze: %s", err)
+		renderError(w, http.StatusBadGateway, "unable to delete freeze")
+		return
+	}
+
+	logger.Infof("Deleted deployment freeze %s", id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
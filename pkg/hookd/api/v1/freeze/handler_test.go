@@ -0,0 +1,88 @@
+package api_v1_freeze_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nais/deploy/pkg/hookd/api"
+	"github.com/nais/deploy/pkg/hookd/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newHandler(store database.FreezeStore) http.Handler {
+	return api.New(api.Config{
+		FreezeStore: store,
+		MetricsPath: "/metrics",
+		PSKValidator: func(h http.Handler) http.Handler {
+			return h
+		},
+	})
+}
+
+func TestFreezeHandler(t *testing.T) {
+	t.Run("list freezes", func(t *testing.T) {
+		store := database.NewMockFreezeStore(t)
+		store.On("Freezes", mock.Anything).Return([]*database.Freeze{
+			{ID: "1", Cluster: "prod-gcp", Reason: "Christmas", Timezone: "UTC", Created: time.Date(2026, time.December, 1, 0, 0, 0, 0, time.UTC)},
+		}, nil).Once()
+
+		request := httptest.NewRequest(http.MethodGet, "/internal/api/v1/console/freeze", nil)
+		recorder := httptest.NewRecorder()
+		newHandler(store).ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `[{"id":"1","team":"","cluster":"prod-gcp","reason":"Christmas","start":null,"end":null,"schedule":"","duration":"","timezone":"UTC","strict":false,"created":"2026-12-01T00:00:00Z"}]`, recorder.Body.String())
+	})
+
+	t.Run("create scheduled freeze", func(t *testing.T) {
+		store := database.NewMockFreezeStore(t)
+		store.On("WriteFreeze", mock.Anything, mock.MatchedBy(func(f database.Freeze) bool {
+			return len(f.ID) > 0 &&
+				f.Cluster == "prod-gcp" &&
+				f.Schedule == "0 16 * * 5" &&
+				f.Duration == "64h" &&
+				f.Timezone == "UTC" &&
+				!f.Created.IsZero()
+		})).Return(nil).Once()
+
+		body := `{"cluster":"prod-gcp","reason":"weekend","schedule":"0 16 * * 5","duration":"64h"}`
+		request := httptest.NewRequest(http.MethodPost, "/internal/api/v1/console/freeze", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		newHandler(store).ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"reason":"weekend"`)
+	})
+
+	t.Run("invalid freeze is rejected", func(t *testing.T) {
+		store := database.NewMockFreezeStore(t)
+
+		body := `{"cluster":"prod-gcp","reason":"weekend","schedule":"0 16 * * 5"}`
+		request := httptest.NewRequest(http.MethodPost, "/internal/api/v1/console/freeze", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		newHandler(store).ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "duration")
+	})
+
+	t.Run("delete freeze", func(t *testing.T) {
+		store := database.NewMockFreezeStore(t)
+		store.On("DeleteFreeze", mock.Anything, "1").Return(nil).Once()
+		store.On("DeleteFreeze", mock.Anything, "2").Return(database.ErrNotFound).Once()
+
+		recorder := httptest.NewRecorder()
+		newHandler(store).ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/internal/api/v1/console/freeze/1", nil))
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+
+		recorder = httptest.NewRecorder()
+		newHandler(store).ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/internal/api/v1/console/freeze/2", nil))
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
@@ -0,0 +1,260 @@
+package approval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nais/deploy/pkg/grpc/dispatchserver"
+	"github.com/nais/deploy/pkg/hookd/database"
+	database_mapper "github.com/nais/deploy/pkg/hookd/database/mapper"
+	"github.com/nais/deploy/pkg/hookd/freeze"
+	"github.com/nais/deploy/pkg/pb"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	ErrNotPending       = errors.New("deployment is not waiting for approval")
+	ErrApproverRequired = errors.New("the name of the approver is required")
+	ErrNotApprover      = errors.New("deployment must be approved by a member of team")
+	ErrSelfApproval     = errors.New("deployments can't be approved by whoever requested them")
+)
+
+// Gate holds back deployments to clusters where deployments must be approved, and dispatches them once approved.
+// A nil gate requires no approvals.
+type Gate struct {
+	store      database.ApprovalStore
+	dispatcher dispatchserver.DispatchServer
+	freezes    database.FreezeStore
+	// Team that approves deployments to each cluster. An empty team means the team making the deployment.
+	approvers map[string]string
+}
+
+func NewGate(store database.ApprovalStore, dispatcher dispatchserver.DispatchServer, approvers map[string]string) *Gate {
+	return &Gate{
+		store:      store,
+		dispatcher: dispatcher,
+		approvers:  approvers,
+	}
+}
+
+// WithFreezes makes the gate refuse to approve deployments while a freeze applies to them.
+// Deployments may have been held back since before the freeze started.
+func (g *Gate) WithFreezes(store database.FreezeStore) *Gate {
+	g.freezes = store
+	return g
+}
+
+// Approvers returns the team that must approve a deployment request, if the request needs approval.
+func (g *Gate) Approvers(request *pb.DeploymentRequest) (string, bool) {
+	if g == nil {
+		return "", false
+	}
+	team, required := g.approvers[request.GetCluster()]
+	if !required {
+		return "", false
+	}
+	if len(team) == 0 {
+		team = request.GetTeam()
+	}
+	return team, true
+}
+
+// Hold stores a deployment request until it has been approved, and returns its pending status.
+// The deployment must already be stored.
+func (g *Gate) Hold(ctx context.Context, request *pb.DeploymentRequest, approvers string) (*pb.DeploymentStatus, error) {
+	approval, err := database_mapper.DeploymentApproval(request, approvers)
+	if err != nil {
+		return nil, fmt.Errorf("serialize deployment request: %w", err)
+	}
+
+	err = g.store.WriteDeploymentApproval(ctx, approval)
+	if err != nil {
+		return nil, err
+	}
+
+	return pb.NewPendingStatus(request, "Deployment is waiting for approval by a member of team '%s'. It expires if not approved by %s.", approvers, approval.Deadline.UTC().Format(time.RFC3339)), nil
+}
+
+// Decision approves or rejects a pending deployment.
+type Decision struct {
+	DeploymentID string
+	Approve      bool
+	// Team the approver has authenticated as. Empty if the caller may act for any team, such as the console.
+	Team string
+	// Who approves or rejects the deployment, such as a GitHub username. Only the console authenticates this.
+	Approver string
+	Comment  string
+}
+
+// Decide approves or rejects a pending deployment, and returns its new status.
+// Approved deployments are dispatched to deployd. Only one decision is made for each deployment.
+// Deployments can't be approved while a freeze applies to them, as checked with the freeze override of the request;
+// a *freeze.FrozenError is returned, and the deployment stays pending.
+func (g *Gate) Decide(ctx context.Context, decision Decision) (*pb.DeploymentStatus, error) {
+	if g == nil {
+		return nil, ErrNotPending
+	}
+
+	decision.Approver = strings.TrimSpace(decision.Approver)
+	if len(decision.Approver) == 0 {
+		return nil, ErrApproverRequired
+	}
+
+	approval, err := g.store.DeploymentApproval(ctx, decision.DeploymentID)
+	if database.IsErrNotFound(err) {
+		return nil, ErrNotPending
+	} else if err != nil {
+		return nil, err
+	}
+
+	if approval.State != database.ApprovalPending {
+		return nil, fmt.Errorf("%w; it has been %s", ErrNotPending, approval.State)
+	}
+	// The approver's name is not authenticated, only their team. Anyone holding the deploying team's key
+	// could approve under another name, so the deploying team can't approve its own deployments.
+	if len(decision.Team) > 0 && decision.Team == approval.Team {
+		return nil, ErrSelfApproval
+	}
+	if len(decision.Team) > 0 && decision.Team != approval.Approvers {
+		return nil, fmt.Errorf("%w '%s'", ErrNotApprover, approval.Approvers)
+	}
+	if len(approval.RequestedBy) > 0 && strings.EqualFold(decision.Approver, approval.RequestedBy) {
+		return nil, ErrSelfApproval
+	}
+
+	if decision.Approve && g.freezes != nil {
+		request, err := database_mapper.PbApprovalRequest(*approval)
+		if err != nil {
+			return nil, fmt.Errorf("deserialize deployment request: %w", err)
+		}
+		err = freeze.Check(ctx, g.freezes, request, time.Now(), log.WithFields(request.LogFields()))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	state := database.ApprovalRejected
+	if decision.Approve {
+		state = database.ApprovalApproved
+	}
+
+	approval, err = g.store.DecideDeploymentApproval(ctx, decision.DeploymentID, state, decision.Approver, decision.Comment, time.Now())
+	if database.IsErrNotFound(err) {
+		// Someone else made a decision first, or the deadline has passed.
+		return nil, ErrNotPending
+	} else if err != nil {
+		return nil, err
+	}
+
+	request, err := database_mapper.PbApprovalRequest(*approval)
+	if err != nil {
+		return nil, fmt.Errorf("deserialize deployment request: %w", err)
+	}
+
+	logger := log.WithFields(request.LogFields()).WithField("approver", decision.Approver)
+
+	var st *pb.DeploymentStatus
+	if decision.Approve {
+		logger.Infof("Deployment approved")
+		err = g.dispatcher.SendDeploymentRequest(ctx, request)
+		if err != nil {
+			logger.Errorf("Dispatch approved deployment: %s", err)
+			st = pb.NewErrorStatus(request, fmt.Errorf("deployment was approved by %s, but could not be dispatched: %w", decision.Approver, err))
+		} else {
+			st = pb.NewQueuedStatus(request)
+			st.Message = fmt.Sprintf("Deployment was approved by %s%s, and has been put on the queue.", decision.Approver, describeComment(decision.Comment))
+		}
+	} else {
+		logger.Infof("Deployment rejected")
+		st = pb.NewFailureStatus(request, fmt.Errorf("deployment was rejected by %s%s", decision.Approver, describeComment(decision.Comment)))
+	}
+
+	err = g.dispatcher.HandleDeploymentStatus(ctx, st)
+	if err != nil {
+		logger.Errorf("Unable to store deployment status in database: %s", err)
+	}
+
+	return st, nil
+}
+
+// Cancel stops waiting for approval of a deployment, and returns its final status.
+func (g *Gate) Cancel(ctx context.Context, deploymentID, team string) (*pb.DeploymentStatus, error) {
+	if g == nil {
+		return nil, ErrNotPending
+	}
+
+	approval, err := g.store.DecideDeploymentApproval(ctx, deploymentID, database.ApprovalCancelled, team, "", time.Now())
+	if database.IsErrNotFound(err) {
+		return nil, ErrNotPending
+	} else if err != nil {
+		return nil, err
+	}
+
+	request, err := database_mapper.PbApprovalRequest(*approval)
+	if err != nil {
+		return nil, fmt.Errorf("deserialize deployment request: %w", err)
+	}
+
+	st := pb.NewCancelledStatus(request)
+	err = g.dispatcher.HandleDeploymentStatus(ctx, st)
+	if err != nil {
+		log.WithFields(request.LogFields()).Errorf("Unable to store deployment status in database: %s", err)
+	}
+
+	return st, nil
+}
+
+// ExpireApprovals fails deployments that were not approved before their deadline, and repeats every interval until the context is cancelled.
+func (g *Gate) ExpireApprovals(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		approvals, err := g.store.ExpireDeploymentApprovals(ctx, time.Now())
+		if err != nil {
+			log.Errorf("Find expired deployment approvals: %s", err)
+		}
+
+		for _, approval := range approvals {
+			g.expire(ctx, *approval)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (g *Gate) expire(ctx context.Context, approval database.DeploymentApproval) {
+	logger := log.WithFields(log.Fields{
+		pb.LogFieldCorrelationID: approval.DeploymentID,
+		pb.LogFieldTeam:          approval.Team,
+		pb.LogFieldCluster:       approval.Cluster,
+	})
+
+	request, err := database_mapper.PbApprovalRequest(approval)
+	if err != nil {
+		logger.Errorf("Discarding expired deployment approval: %s", err)
+		return
+	}
+
+	logger.Infof("Deployment was not approved before its deadline")
+	st := pb.NewFailureStatus(request, fmt.Errorf("deployment was not approved by a member of team '%s' before its deadline", approval.Approvers))
+	err = g.dispatcher.HandleDeploymentStatus(ctx, st)
+	if err != nil {
+		logger.Errorf("Unable to store deployment status in database: %s", err)
+	}
+}
+
+func describeComment(comment string) string {
+	comment = strings.TrimSpace(comment)
+	if len(comment) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", comment)
+}
@@ -0,0 +1,220 @@
+package approval_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nais/deploy/pkg/grpc/dispatchserver"
+	"github.com/nais/deploy/pkg/hookd/approval"
+	"github.com/nais/deploy/pkg/hookd/database"
+	database_mapper "github.com/nais/deploy/pkg/hookd/database/mapper"
+	"github.com/nais/deploy/pkg/hookd/freeze"
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func pendingApproval(t *testing.T) *database.DeploymentApproval {
+	request := &pb.DeploymentRequest{
+		ID:               "1",
+		Team:             "aura",
+		Cluster:          "prod-gcp",
+		DeployerUsername: "alice",
+		Deadline:         pb.TimeAsTimestamp(time.Now().Add(time.Hour)),
+	}
+	pending, err := database_mapper.DeploymentApproval(request, "approvers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &pending
+}
+
+func decided(approval *database.DeploymentApproval, state string) *database.DeploymentApproval {
+	decided := *approval
+	decided.State = state
+	return &decided
+}
+
+func TestApprovers(t *testing.T) {
+	gate := approval.NewGate(nil, nil, map[string]string{"prod-gcp": "", "prod-fss": "approvers"})
+
+	team, required := gate.Approvers(&pb.DeploymentRequest{Team: "aura", Cluster: "prod-gcp"})
+	assert.True(t, required)
+	assert.Equal(t, "aura", team)
+
+	team, required = gate.Approvers(&pb.DeploymentRequest{Team: "aura", Cluster: "prod-fss"})
+	assert.True(t, required)
+	assert.Equal(t, "approvers", team)
+
+	_, required = gate.Approvers(&pb.DeploymentRequest{Team: "aura", Cluster: "dev-gcp"})
+	assert.False(t, required)
+
+	var none *approval.Gate
+	_, required = none.Approvers(&pb.DeploymentRequest{Team: "aura", Cluster: "prod-gcp"})
+	assert.False(t, required)
+}
+
+func TestHold(t *testing.T) {
+	store := database.NewMockApprovalStore(t)
+	store.On("WriteDeploymentApproval", mock.Anything, mock.MatchedBy(func(a database.DeploymentApproval) bool {
+		return a.DeploymentID == "1" && a.Approvers == "aura" && a.RequestedBy == "alice" && a.State == database.ApprovalPending
+	})).Return(nil).Once()
+
+	gate := approval.NewGate(store, nil, map[string]string{"prod-gcp": ""})
+	st, err := gate.Hold(context.Background(), &pb.DeploymentRequest{ID: "1", Team: "aura", Cluster: "prod-gcp", DeployerUsername: "alice"}, "aura")
+	assert.NoError(t, err)
+	assert.Equal(t, pb.DeploymentState_pending, st.GetState())
+	assert.Contains(t, st.GetMessage(), "waiting for approval by a member of team 'aura'")
+}
+
+func TestDecide(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("approved deployments are dispatched", func(t *testing.T) {
+		pending := pendingApproval(t)
+		store := database.NewMockApprovalStore(t)
+		store.On("DeploymentApproval", mock.Anything, "1").Return(pending, nil).Once()
+		store.On("DecideDeploymentApproval", mock.Anything, "1", database.ApprovalApproved, "bob", "looks good", mock.Anything).Return(decided(pending, database.ApprovalApproved), nil).Once()
+
+		dispatcher := &dispatchserver.MockDispatchServer{}
+		dispatcher.On("SendDeploymentRequest", mock.Anything, mock.MatchedBy(func(request *pb.DeploymentRequest) bool {
+			return request.GetID() == "1" && request.GetCluster() == "prod-gcp"
+		})).Return(nil).Once()
+		dispatcher.On("HandleDeploymentStatus", mock.Anything, mock.MatchedBy(func(st *pb.DeploymentStatus) bool {
+			return st.GetState() == pb.DeploymentState_queued
+		})).Return(nil).Once()
+
+		gate := approval.NewGate(store, dispatcher, nil)
+		st, err := gate.Decide(ctx, approval.Decision{DeploymentID: "1", Approve: true, Team: "approvers", Approver: "bob", Comment: "looks good"})
+		assert.NoError(t, err)
+		assert.Equal(t, pb.DeploymentState_queued, st.GetState())
+		assert.Equal(t, "Deployment was approved by bob (looks good), and has been put on the queue.", st.GetMessage())
+		dispatcher.AssertExpectations(t)
+	})
+
+	t.Run("rejected deployments fail without being dispatched", func(t *testing.T) {
+		pending := pendingApproval(t)
+		store := database.NewMockApprovalStore(t)
+		store.On("DeploymentApproval", mock.Anything, "1").Return(pending, nil).Once()
+		store.On("DecideDeploymentApproval", mock.Anything, "1", database.ApprovalRejected, "bob", "", mock.Anything).Return(decided(pending, database.ApprovalRejected), nil).Once()
+
+		dispatcher := &dispatchserver.MockDispatchServer{}
+		dispatcher.On("HandleDeploymentStatus", mock.Anything, mock.Anything).Return(nil).Once()
+
+		gate := approval.NewGate(store, dispatcher, nil)
+		st, err := gate.Decide(ctx, approval.Decision{DeploymentID: "1", Approver: "bob"})
+		assert.NoError(t, err)
+		assert.Equal(t, pb.DeploymentState_failure, st.GetState())
+		assert.Equal(t, "deployment was rejected by bob", st.GetMessage())
+		dispatcher.AssertExpectations(t)
+	})
+
+	for _, test := range []struct {
+		name     string
+		approval *database.DeploymentApproval
+		decision approval.Decision
+		err      error
+	}{
+		{"approver is required", nil, approval.Decision{DeploymentID: "1", Approve: true, Team: "aura"}, approval.ErrApproverRequired},
+		{"deployer can't approve", pendingApproval(t), approval.Decision{DeploymentID: "1", Approve: true, Team: "approvers", Approver: "Alice"}, approval.ErrSelfApproval},
+		{"deploying team can't approve under another name", pendingApproval(t), approval.Decision{DeploymentID: "1", Approve: true, Team: "aura", Approver: "bob"}, approval.ErrSelfApproval},
+		{"approver must be in the approving team", pendingApproval(t), approval.Decision{DeploymentID: "1", Approve: true, Team: "other", Approver: "bob"}, approval.ErrNotApprover},
+		{"deployment has already been decided", decided(pendingApproval(t), database.ApprovalRejected), approval.Decision{DeploymentID: "1", Approve: true, Approver: "bob"}, approval.ErrNotPending},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			store := database.NewMockApprovalStore(t)
+			if test.approval != nil {
+				store.On("DeploymentApproval", mock.Anything, "1").Return(test.approval, nil).Once()
+			}
+
+			gate := approval.NewGate(store, nil, nil)
+			_, err := gate.Decide(ctx, test.decision)
+			assert.ErrorIs(t, err, test.err)
+		})
+	}
+
+	t.Run("only one decision is made", func(t *testing.T) {
+		store := database.NewMockApprovalStore(t)
+		store.On("DeploymentApproval", mock.Anything, "1").Return(pendingApproval(t), nil).Once()
+		store.On("DecideDeploymentApproval", mock.Anything, "1", database.ApprovalApproved, "bob", "", mock.Anything).Return(nil, database.ErrNotFound).Once()
+
+		gate := approval.NewGate(store, nil, nil)
+		_, err := gate.Decide(ctx, approval.Decision{DeploymentID: "1", Approve: true, Approver: "bob"})
+		assert.ErrorIs(t, err, approval.ErrNotPending)
+	})
+}
+
+func TestDecideDuringFreeze(t *testing.T) {
+	ctx := context.Background()
+
+	overridden := func(t *testing.T, override string) *database.DeploymentApproval {
+		request := &pb.DeploymentRequest{ID: "1", Team: "aura", Cluster: "prod-gcp", FreezeOverride: override}
+		pending, err := database_mapper.DeploymentApproval(request, "approvers")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &pending
+	}
+
+	for _, test := range []struct {
+		name     string
+		freeze   database.Freeze
+		override string
+	}{
+		{"freeze started after the deployment was submitted", database.Freeze{ID: "1", Reason: "incident"}, ""},
+		{"strict freeze can't be overridden", database.Freeze{ID: "1", Reason: "incident", Strict: true}, "hotfix"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			store := database.NewMockApprovalStore(t)
+			store.On("DeploymentApproval", mock.Anything, "1").Return(overridden(t, test.override), nil).Once()
+			freezes := database.NewMockFreezeStore(t)
+			freezes.On("ApplicableFreezes", mock.Anything, "aura", "prod-gcp", mock.Anything).Return([]*database.Freeze{&test.freeze}, nil).Once()
+
+			// The deployment stays pending, and is neither decided nor dispatched.
+			gate := approval.NewGate(store, nil, nil).WithFreezes(freezes)
+			_, err := gate.Decide(ctx, approval.Decision{DeploymentID: "1", Approve: true, Team: "approvers", Approver: "bob"})
+			var frozen *freeze.FrozenError
+			assert.ErrorAs(t, err, &frozen)
+			assert.Contains(t, err.Error(), "are frozen until further notice: incident (freeze 1)")
+		})
+	}
+
+	t.Run("freeze override given with the deployment applies to its approval", func(t *testing.T) {
+		pending := overridden(t, "hotfix")
+		store := database.NewMockApprovalStore(t)
+		store.On("DeploymentApproval", mock.Anything, "1").Return(pending, nil).Once()
+		store.On("DecideDeploymentApproval", mock.Anything, "1", database.ApprovalApproved, "bob", "", mock.Anything).Return(decided(pending, database.ApprovalApproved), nil).Once()
+		freezes := database.NewMockFreezeStore(t)
+		freezes.On("ApplicableFreezes", mock.Anything, "aura", "prod-gcp", mock.Anything).Return([]*database.Freeze{{ID: "1", Reason: "incident"}}, nil).Once()
+
+		dispatcher := &dispatchserver.MockDispatchServer{}
+		dispatcher.On("SendDeploymentRequest", mock.Anything, mock.Anything).Return(nil).Once()
+		dispatcher.On("HandleDeploymentStatus", mock.Anything, mock.Anything).Return(nil).Once()
+
+		gate := approval.NewGate(store, dispatcher, nil).WithFreezes(freezes)
+		st, err := gate.Decide(ctx, approval.Decision{DeploymentID: "1", Approve: true, Team: "approvers", Approver: "bob"})
+		assert.NoError(t, err)
+		assert.Equal(t, pb.DeploymentState_queued, st.GetState())
+		dispatcher.AssertExpectations(t)
+	})
+}
+
+func TestExpireApprovals(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	expired := decided(pendingApproval(t), database.ApprovalExpired)
+	store := database.NewMockApprovalStore(t)
+	store.On("ExpireDeploymentApprovals", mock.Anything, mock.Anything).Return([]*database.DeploymentApproval{expired}, nil).Once()
+
+	dispatcher := &dispatchserver.MockDispatchServer{}
+	dispatcher.On("HandleDeploymentStatus", mock.Anything, mock.MatchedBy(func(st *pb.DeploymentStatus) bool {
+		return st.GetRequest().GetID() == "1" && st.GetState() == pb.DeploymentState_failure
+	})).Run(func(mock.Arguments) {
+		cancel()
+	}).Return(nil).Once()
+
+	approval.NewGate(store, dispatcher, nil).ExpireApprovals(ctx, time.Hour)
+	dispatcher.AssertExpectations(t)
+}
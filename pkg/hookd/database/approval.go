@@ -0,0 +1,167 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// States of a deployment approval.
+const (
+	ApprovalPending   = "pending"
+	ApprovalApproved  = "approved"
+	ApprovalRejected  = "rejected"
+	ApprovalExpired   = "expired"
+	ApprovalCancelled = "cancelled"
+)
+
+// DeploymentApproval is a deployment request that is held back until someone approves it.
+// The request itself is stored as a serialized protobuf message.
+type DeploymentApproval struct {
+	DeploymentID string `json:"deploymentID"`
+	Team         string `json:"team"`
+	Cluster      string `json:"cluster"`
+	// Team whose members can approve or reject the deployment.
+	Approvers string `json:"approvers"`
+	// Username of whoever made the deployment request, who can't approve it.
+	RequestedBy string     `json:"requestedBy"`
+	Request     []byte     `json:"-"`
+	Deadline    time.Time  `json:"deadline"`
+	Created     time.Time  `json:"created"`
+	State       string     `json:"state"`
+	DecidedBy   string     `json:"decidedBy"`
+	Decided     *time.Time `json:"decided"`
+	Comment     string     `json:"comment"`
+}
+
+type ApprovalStore interface {
+	DeploymentApproval(ctx context.Context, deploymentID string) (*DeploymentApproval, error)
+	PendingDeploymentApprovals(ctx context.Context) ([]*DeploymentApproval, error)
+	WriteDeploymentApproval(ctx context.Context, approval DeploymentApproval) error
+	DecideDeploymentApproval(ctx context.Context, deploymentID, state, decidedBy, comment string, decided time.Time) (*DeploymentApproval, error)
+	ExpireDeploymentApprovals(ctx context.Context, before time.Time) ([]*DeploymentApproval, error)
+}
+
+var _ ApprovalStore = &Database{}
+
+const selectApprovalFields = `deployment_id, team, cluster, approvers, requested_by, request, deadline, created, state, decided_by, decided, comment`
+
+func (db *Database) DeploymentApproval(ctx context.Context, deploymentID string) (*DeploymentApproval, error) {
+	query := `SELECT ` + selectApprovalFields + ` FROM deployment_approval WHERE deployment_id = $1;`
+	rows, err := db.timedQuery(ctx, query, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	approvals, err := scanApprovals(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(approvals) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return approvals[0], nil
+}
+
+// PendingDeploymentApprovals returns all deployments waiting for approval, oldest first.
+func (db *Database) PendingDeploymentApprovals(ctx context.Context) ([]*DeploymentApproval, error) {
+	query := `SELECT ` + selectApprovalFields + ` FROM deployment_approval WHERE state = 'pending' ORDER BY created ASC;`
+	rows, err := db.timedQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanApprovals(rows)
+}
+
+func (db *Database) WriteDeploymentApproval(ctx context.Context, approval DeploymentApproval) error {
+	query := `
+INSERT INTO deployment_approval (deployment_id, team, cluster, approvers, requested_by, request, deadline, created, state)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+`
+	_, err := db.conn.Exec(ctx, query,
+		approval.DeploymentID,
+		approval.Team,
+		approval.Cluster,
+		approval.Approvers,
+		approval.RequestedBy,
+		approval.Request,
+		approval.Deadline,
+		approval.Created,
+		approval.State,
+	)
+
+	return err
+}
+
+// DecideDeploymentApproval moves a pending approval to the given state, and returns it.
+// Only one caller can decide an approval, and only before its deadline. ErrNotFound is returned otherwise.
+func (db *Database) DecideDeploymentApproval(ctx context.Context, deploymentID, state, decidedBy, comment string, decided time.Time) (*DeploymentApproval, error) {
+	query := `
+UPDATE deployment_approval
+SET state = $2, decided_by = $3, comment = $4, decided = $5
+WHERE deployment_id = $1 AND state = 'pending' AND deadline > $5
+RETURNING ` + selectApprovalFields + `;
+`
+	rows, err := db.timedQuery(ctx, query, deploymentID, state, decidedBy, comment, decided)
+	if err != nil {
+		return nil, err
+	}
+
+	approvals, err := scanApprovals(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(approvals) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return approvals[0], nil
+}
+
+// ExpireDeploymentApprovals marks pending approvals with a deadline before the given time as expired, and returns them.
+func (db *Database) ExpireDeploymentApprovals(ctx context.Context, before time.Time) ([]*DeploymentApproval, error) {
+	query := `
+UPDATE deployment_approval
+SET state = 'expired', decided = $1
+WHERE state = 'pending' AND deadline <= $1
+RETURNING ` + selectApprovalFields + `;
+`
+	rows, err := db.timedQuery(ctx, query, before)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanApprovals(rows)
+}
+
+func scanApprovals(rows pgx.Rows) ([]*DeploymentApproval, error) {
+	defer rows.Close()
+
+	approvals := make([]*DeploymentApproval, 0)
+	for rows.Next() {
+		approval := &DeploymentApproval{}
+		err := rows.Scan(
+			&approval.DeploymentID,
+			&approval.Team,
+			&approval.Cluster,
+			&approval.Approvers,
+			&approval.RequestedBy,
+			&approval.Request,
+			&approval.Deadline,
+			&approval.Created,
+			&approval.State,
+			&approval.DecidedBy,
+			&approval.Decided,
+			&approval.Comment,
+		)
+		if err != nil {
+			return nil, err
+		}
+		approvals = append(approvals, approval)
+	}
+
+	return approvals, rows.Err()
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// WriteDeploydInstance records that a deployd instance is connected for a cluster.
+func (db *Database) WriteDeploydInstance(ctx context.Context, cluster, instanceID string) error {
+	query := `
+INSERT INTO deployd_instance (cluster, instance_id, last_seen)
+VALUES ($1, $2, NOW())
+ON CONFLICT (cluster, instance_id) DO UPDATE SET last_seen = EXCLUDED.last_seen;
+`
+	_, err := db.conn.Exec(ctx, query, cluster, instanceID)
+	return err
+}
+
+// DeleteDeploydInstance forgets a deployd instance once it has disconnected.
+func (db *Database) DeleteDeploydInstance(ctx context.Context, cluster, instanceID string) error {
+	query := `DELETE FROM deployd_instance WHERE cluster = $1 AND instance_id = $2;`
+	_, err := db.conn.Exec(ctx, query, cluster, instanceID)
+	return err
+}
+
+// DeploydInstances returns the IDs of deployd instances for a cluster that have been seen after the given time.
+func (db *Database) DeploydInstances(ctx context.Context, cluster string, since time.Time) ([]string, error) {
+	query := `SELECT instance_id FROM deployd_instance WHERE cluster = $1 AND last_seen > $2;`
+	rows, err := db.timedQuery(ctx, query, cluster, since)
+	if err != nil {
+		return nil, err
+	}
+
+	instances := make([]string, 0)
+
+	defer rows.Close()
+	for rows.Next() {
+		var instanceID string
+		err := rows.Scan(&instanceID)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, instanceID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return instances, nil
+}
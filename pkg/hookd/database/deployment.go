@@ -16,6 +16,7 @@ type Deployment struct {
 	GitHubRepository *string   `json:"githubRepository"`
 	Cluster          *string   `json:"cluster"`
 	State            *string   `json:"state"`
+	Operation        string    `json:"operation"`
 }
 
 type DeploymentStatus struct {
@@ -24,6 +25,7 @@ type DeploymentStatus struct {
 	Status       string    `json:"status"`
 	Message      string    `json:"message"`
 	Created      time.Time `json:"created"`
+	Cancelled    bool      `json:"cancelled"`
 }
 
 type DeploymentResource struct {
@@ -35,10 +37,32 @@ type DeploymentResource struct {
 	Kind         string `json:"kind"`
 	Name         string `json:"name"`
 	Namespace    string `json:"namespace"`
+	// Set if the resource was deleted by the deployment, e.g. because it was pruned from the deploy set.
+	Deleted bool `json:"deleted"`
+}
+
+// DeploymentFilter selects deployments for ListDeployments. Empty fields match everything.
+type DeploymentFilter struct {
+	Teams      []string
+	Clusters   []string
+	Repository string
+	States     []string
+	Since      *time.Time
+	Until      *time.Time
+	// Only return deployments that come after this one in the listing order.
+	After *DeploymentCursor
+	Limit int
+}
+
+// DeploymentCursor is the position of a deployment in the listing order, newest first.
+type DeploymentCursor struct {
+	Created time.Time
+	ID      string
 }
 
 type DeploymentStore interface {
 	Deployments(ctx context.Context, teams, clusters, ignoreTeams []string, limit int) ([]*Deployment, error)
+	ListDeployments(ctx context.Context, filter DeploymentFilter) ([]*Deployment, error)
 	Deployment(ctx context.Context, id string) (*Deployment, error)
 	HistoricDeployments(ctx context.Context, cluster string, timestamp time.Time) ([]*Deployment, error)
 	WriteDeployment(ctx context.Context, deployment Deployment) error
@@ -46,6 +70,25 @@ type DeploymentStore interface {
 	WriteDeploymentStatus(ctx context.Context, status DeploymentStatus) error
 	DeploymentResources(ctx context.Context, deploymentID string) ([]DeploymentResource, error)
 	WriteDeploymentResource(ctx context.Context, resource DeploymentResource) error
+	QueueDeploymentRequest(ctx context.Context, request QueuedDeploymentRequest) error
+	QueuedDeploymentRequests(ctx context.Context, cluster string) ([]QueuedDeploymentRequest, error)
+	ExpiredQueuedDeploymentRequests(ctx context.Context, before time.Time) ([]QueuedDeploymentRequest, error)
+	DispatchQueuedDeploymentRequest(ctx context.Context, request DispatchedDeploymentRequest) error
+	DeleteQueuedDeploymentRequest(ctx context.Context, deploymentID string) error
+	WriteDispatchedDeploymentRequest(ctx context.Context, request DispatchedDeploymentRequest) error
+	DispatchedDeploymentRequests(ctx context.Context, cluster string) ([]DispatchedDeploymentRequest, error)
+	ClaimDispatchedDeploymentRequest(ctx context.Context, deploymentID, previousInstanceID, instanceID string) error
+	DeleteDispatchedDeploymentRequest(ctx context.Context, deploymentID string) error
+	WriteDeploydInstance(ctx context.Context, cluster, instanceID string) error
+	DeleteDeploydInstance(ctx context.Context, cluster, instanceID string) error
+	DeploydInstances(ctx context.Context, cluster string, since time.Time) ([]string, error)
+	WriteDeploymentManifest(ctx context.Context, manifest DeploymentManifest) error
+	DeploymentManifest(ctx context.Context, deploymentID string) (*DeploymentManifest, error)
+	DeleteDeploymentManifests(ctx context.Context, before time.Time) (int64, error)
+	PreviousDeployment(ctx context.Context, deploymentID string) (*Deployment, error)
+	WritePreview(ctx context.Context, preview Preview) error
+	DeletePreview(ctx context.Context, team, cluster, repository, name string) (*Preview, error)
+	DeleteExpiredPreviews(ctx context.Context, before time.Time) ([]*Preview, error)
 }
 
 var _ DeploymentStore = &Database{}
@@ -60,6 +103,8 @@ func scanDeployment(rows pgx.Rows) (*Deployment, error) {
 		&deployment.GitHubID,
 		&deployment.GitHubRepository,
 		&deployment.Cluster,
+		&deployment.State,
+		&deployment.Operation,
 	)
 
 	return deployment, err
@@ -67,9 +112,10 @@ func scanDeployment(rows pgx.Rows) (*Deployment, error) {
 
 func (db *Database) HistoricDeployments(ctx context.Context, cluster string, timestamp time.Time) ([]*Deployment, error) {
 	query := `
-SELECT id, team, created, github_id, github_repository, cluster
+SELECT id, team, created, github_id, github_repository, cluster, state, operation
 FROM deployment
-WHERE (cluster = $1 AND created < $2 AND (state = 'in_progress' OR state = 'queued'));
+WHERE (cluster = $1 AND created < $2 AND (state = 'in_progress' OR state = 'queued'))
+AND NOT EXISTS (SELECT 1 FROM deployment_queue WHERE deployment_queue.deployment_id = deployment.id);
 `
 	rows, err := db.timedQuery(ctx, query, cluster, timestamp)
 	if err != nil {
@@ -92,7 +138,7 @@ WHERE (cluster = $1 AND created < $2 AND (state = 'in_progress' OR state = 'queu
 
 func (db *Database) Deployments(ctx context.Context, teams, clusters, ignoreTeams []string, limit int) ([]*Deployment, error) {
 	query := `
-SELECT id, team, created, github_id, github_repository, cluster
+SELECT id, team, created, github_id, github_repository, cluster, state, operation
 FROM deployment
 WHERE (ARRAY_LENGTH($1::VARCHAR[], 1) IS NULL OR team = ANY($1))
 AND (ARRAY_LENGTH($2::VARCHAR[], 1) IS NULL OR cluster = ANY($2))
@@ -119,8 +165,58 @@ LIMIT $4;
 	return deployments, nil
 }
 
+func (db *Database) ListDeployments(ctx context.Context, filter DeploymentFilter) ([]*Deployment, error) {
+	query := `
+SELECT id, team, created, github_id, github_repository, cluster, state, operation
+FROM deployment
+WHERE (ARRAY_LENGTH($1::VARCHAR[], 1) IS NULL OR team = ANY($1))
+AND (ARRAY_LENGTH($2::VARCHAR[], 1) IS NULL OR cluster = ANY($2))
+AND ($3 = '' OR github_repository = $3)
+AND (ARRAY_LENGTH($4::VARCHAR[], 1) IS NULL OR state = ANY($4))
+AND ($5::TIMESTAMP WITH TIME ZONE IS NULL OR created >= $5)
+AND ($6::TIMESTAMP WITH TIME ZONE IS NULL OR created < $6)
+AND ($7::TIMESTAMP WITH TIME ZONE IS NULL OR (created, id) < ($7, $8))
+ORDER BY created DESC, id DESC
+LIMIT $9;
+`
+	var afterCreated *time.Time
+	var afterID string
+	if filter.After != nil {
+		afterCreated = &filter.After.Created
+		afterID = filter.After.ID
+	}
+
+	rows, err := db.timedQuery(ctx, query,
+		pq.Array(filter.Teams),
+		pq.Array(filter.Clusters),
+		filter.Repository,
+		pq.Array(filter.States),
+		filter.Since,
+		filter.Until,
+		afterCreated,
+		afterID,
+		filter.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	deployments := make([]*Deployment, 0)
+	defer rows.Close()
+	for rows.Next() {
+		deployment, err := scanDeployment(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		deployments = append(deployments, deployment)
+	}
+
+	return deployments, nil
+}
+
 func (db *Database) Deployment(ctx context.Context, id string) (*Deployment, error) {
-	query := `SELECT id, team, created, github_id, github_repository, cluster FROM deployment WHERE id = $1;`
+	query := `SELECT id, team, created, github_id, github_repository, cluster, state, operation FROM deployment WHERE id = $1;`
 	rows, err := db.timedQuery(ctx, query, id)
 	if err != nil {
 		return nil, err
@@ -141,8 +237,8 @@ func (db *Database) Deployment(ctx context.Context, id string) (*Deployment, err
 
 func (db *Database) WriteDeployment(ctx context.Context, deployment Deployment) error {
 	query := `
-INSERT INTO deployment (id, team, created, github_id, github_repository, cluster)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO deployment (id, team, created, github_id, github_repository, cluster, operation)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 ON CONFLICT (id) DO UPDATE
 SET github_id = EXCLUDED.github_id, github_repository = EXCLUDED.github_repository;
 `
@@ -153,13 +249,14 @@ SET github_id = EXCLUDED.github_id, github_repository = EXCLUDED.github_reposito
 		deployment.GitHubID,
 		deployment.GitHubRepository,
 		deployment.Cluster,
+		deployment.Operation,
 	)
 
 	return err
 }
 
 func (db *Database) DeploymentStatus(ctx context.Context, deploymentID string) ([]DeploymentStatus, error) {
-	query := `SELECT id, deployment_id, status, message, created FROM deployment_status WHERE deployment_id = $1 ORDER BY created DESC;`
+	query := `SELECT id, deployment_id, status, message, created, cancelled FROM deployment_status WHERE deployment_id = $1 ORDER BY created DESC;`
 	rows, err := db.timedQuery(ctx, query, deploymentID)
 	if err != nil {
 		return nil, err
@@ -178,6 +275,7 @@ func (db *Database) DeploymentStatus(ctx context.Context, deploymentID string) (
 			&status.Status,
 			&status.Message,
 			&status.Created,
+			&status.Cancelled,
 		)
 		if err != nil {
 			return nil, err
@@ -197,8 +295,8 @@ func (db *Database) WriteDeploymentStatus(ctx context.Context, status Deployment
 	var query string
 
 	query = `
-INSERT INTO deployment_status (id, deployment_id, status, message, created)
-VALUES ($1, $2, $3, $4, $5);
+INSERT INTO deployment_status (id, deployment_id, status, message, created, cancelled)
+VALUES ($1, $2, $3, $4, $5, $6);
 `
 	_, err := db.conn.Exec(ctx, query,
 		status.ID,
@@ -206,6 +304,7 @@ VALUES ($1, $2, $3, $4, $5);
 		status.Status,
 		status.Message,
 		status.Created,
+		status.Cancelled,
 	)
 	if err != nil {
 		return err
@@ -221,7 +320,7 @@ VALUES ($1, $2, $3, $4, $5);
 }
 
 func (db *Database) DeploymentResources(ctx context.Context, deploymentID string) ([]DeploymentResource, error) {
-	query := `SELECT id, deployment_id, index, "group", version, kind, name, namespace FROM deployment_resource WHERE deployment_id = $1 ORDER BY index ASC;`
+	query := `SELECT id, deployment_id, index, "group", version, kind, name, namespace, deleted FROM deployment_resource WHERE deployment_id = $1 ORDER BY index ASC;`
 	rows, err := db.timedQuery(ctx, query, deploymentID)
 	if err != nil {
 		return nil, err
@@ -243,6 +342,7 @@ func (db *Database) DeploymentResources(ctx context.Context, deploymentID string
 			&resource.Kind,
 			&resource.Name,
 			&resource.Namespace,
+			&resource.Deleted,
 		)
 		if err != nil {
 			return nil, err
@@ -256,8 +356,8 @@ func (db *Database) DeploymentResources(ctx context.Context, deploymentID string
 
 func (db *Database) WriteDeploymentResource(ctx context.Context, resource DeploymentResource) error {
 	query := `
-INSERT INTO deployment_resource (id, deployment_id, index, "group", version, kind, name, namespace)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+INSERT INTO deployment_resource (id, deployment_id, index, "group", version, kind, name, namespace, deleted)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
 `
 	_, err := db.conn.Exec(ctx, query,
 		resource.ID,
@@ -268,6 +368,7 @@ VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
 		resource.Kind,
 		resource.Name,
 		resource.Namespace,
+		resource.Deleted,
 	)
 
 	return err
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nais/deploy/pkg/crypto"
+)
+
+// DeploymentManifest holds the Kubernetes resources of a deployment, with secrets redacted,
+// stored as a gzip compressed JSON array.
+type DeploymentManifest struct {
+	DeploymentID string `json:"deploymentID"`
+	Data         []byte `json:"data"`
+	// The original deployment request as a serialized protobuf message, used to redeploy.
+	// Encrypted at rest. Nil for manifests stored before requests were kept.
+	Request []byte    `json:"-"`
+	Created time.Time `json:"created"`
+}
+
+func (db *Database) WriteDeploymentManifest(ctx context.Context, manifest DeploymentManifest) error {
+	var request []byte
+	if manifest.Request != nil {
+		var err error
+		request, err = crypto.Encrypt(manifest.Request, db.encryptionKey)
+		if err != nil {
+			return fmt.Errorf("encrypt deployment request: %s", err)
+		}
+	}
+
+	query := `
+INSERT INTO deployment_manifest (deployment_id, data, request, created)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (deployment_id) DO NOTHING;
+`
+	_, err := db.conn.Exec(ctx, query,
+		manifest.DeploymentID,
+		manifest.Data,
+		request,
+		manifest.Created,
+	)
+
+	return err
+}
+
+func (db *Database) DeploymentManifest(ctx context.Context, deploymentID string) (*DeploymentManifest, error) {
+	query := `SELECT deployment_id, data, request, created FROM deployment_manifest WHERE deployment_id = $1;`
+	rows, err := db.timedQuery(ctx, query, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	if rows.Next() {
+		manifest := &DeploymentManifest{}
+		var request []byte
+		err := rows.Scan(
+			&manifest.DeploymentID,
+			&manifest.Data,
+			&request,
+			&manifest.Created,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if request != nil {
+			manifest.Request, err = crypto.Decrypt(request, db.encryptionKey)
+			if err != nil {
+				return nil, fmt.Errorf("decrypt deployment request: %s", err)
+			}
+		}
+
+		return manifest, nil
+	}
+
+	return nil, ErrNotFound
+}
+
+// DeleteDeploymentManifests removes manifests created before the given time, and returns how many were removed.
+func (db *Database) DeleteDeploymentManifests(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM deployment_manifest WHERE created < $1;`
+	tag, err := db.conn.Exec(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// PreviousDeployment returns the latest deployment made before the given one by the same team to the same cluster,
+// that has a stored manifest and has at least one resource in common with the given deployment.
+func (db *Database) PreviousDeployment(ctx context.Context, deploymentID string) (*Deployment, error) {
+	query := `
+SELECT d.id, d.team, d.created, d.github_id, d.github_repository, d.cluster, d.state, d.operation
+FROM deployment d, deployment current
+WHERE current.id = $1
+AND d.team = current.team
+AND d.cluster = current.cluster
+AND d.created < current.created
+AND EXISTS (SELECT 1 FROM deployment_manifest m WHERE m.deployment_id = d.id)
+AND EXISTS (
+    SELECT 1
+    FROM deployment_resource r, deployment_resource cr
+    WHERE r.deployment_id = d.id
+    AND cr.deployment_id = current.id
+    AND NOT r.deleted
+    AND NOT cr.deleted
+    AND r."group" = cr."group"
+    AND r.kind = cr.kind
+    AND r.namespace = cr.namespace
+    AND r.name = cr.name
+)
+ORDER BY d.created DESC
+LIMIT 1;
+`
+	rows, err := db.timedQuery(ctx, query, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	if rows.Next() {
+		return scanDeployment(rows)
+	}
+
+	return nil, ErrNotFound
+}
@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// QueuedDeploymentRequest is a deployment request waiting for its cluster to come online.
+// The request itself is stored as a serialized protobuf message.
+type QueuedDeploymentRequest struct {
+	DeploymentID string    `json:"deploymentID"`
+	Cluster      string    `json:"cluster"`
+	Request      []byte    `json:"request"`
+	Deadline     time.Time `json:"deadline"`
+	Created      time.Time `json:"created"`
+}
+
+func (db *Database) QueueDeploymentRequest(ctx context.Context, request QueuedDeploymentRequest) error {
+	query := `
+INSERT INTO deployment_queue (deployment_id, cluster, request, deadline, created)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (deployment_id) DO NOTHING;
+`
+	_, err := db.conn.Exec(ctx, query,
+		request.DeploymentID,
+		request.Cluster,
+		request.Request,
+		request.Deadline,
+		request.Created,
+	)
+
+	return err
+}
+
+// QueuedDeploymentRequests returns all queued requests for a cluster, oldest first.
+// Requests are left on the queue until they are dispatched with DispatchQueuedDeploymentRequest or deleted.
+func (db *Database) QueuedDeploymentRequests(ctx context.Context, cluster string) ([]QueuedDeploymentRequest, error) {
+	query := `
+SELECT deployment_id, cluster, request, deadline, created
+FROM deployment_queue
+WHERE cluster = $1
+ORDER BY created ASC;
+`
+	return db.queryQueuedDeploymentRequests(ctx, query, cluster)
+}
+
+// ExpiredQueuedDeploymentRequests returns queued requests for any cluster whose deadline passed before the given time.
+func (db *Database) ExpiredQueuedDeploymentRequests(ctx context.Context, before time.Time) ([]QueuedDeploymentRequest, error) {
+	query := `
+SELECT deployment_id, cluster, request, deadline, created
+FROM deployment_queue
+WHERE deadline < $1
+ORDER BY created ASC;
+`
+	return db.queryQueuedDeploymentRequests(ctx, query, before)
+}
+
+func (db *Database) queryQueuedDeploymentRequests(ctx context.Context, query string, args ...interface{}) ([]QueuedDeploymentRequest, error) {
+	rows, err := db.timedQuery(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := make([]QueuedDeploymentRequest, 0)
+
+	defer rows.Close()
+	for rows.Next() {
+		request := QueuedDeploymentRequest{}
+
+		err := rows.Scan(
+			&request.DeploymentID,
+			&request.Cluster,
+			&request.Request,
+			&request.Deadline,
+			&request.Created,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		requests = append(requests, request)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+// DispatchQueuedDeploymentRequest moves a request from the queue to the dispatched requests in a single transaction,
+// so that a request is never lost if hookd stops while sending it.
+// Returns ErrNotFound if the request is no longer queued, e.g. because another hookd instance has dispatched it.
+func (db *Database) DispatchQueuedDeploymentRequest(ctx context.Context, request DispatchedDeploymentRequest) error {
+	var query string
+
+	tx, err := db.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to start transaction: %s", err)
+	}
+
+	query = `DELETE FROM deployment_queue WHERE deployment_id = $1;`
+	tag, err := tx.Exec(ctx, query, request.DeploymentID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		tx.Rollback(ctx)
+		return ErrNotFound
+	}
+
+	query = `
+INSERT INTO deployment_request (deployment_id, cluster, request, deadline, created, instance_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (deployment_id) DO UPDATE SET instance_id = EXCLUDED.instance_id;
+`
+	_, err = tx.Exec(ctx, query,
+		request.DeploymentID,
+		request.Cluster,
+		request.Request,
+		request.Deadline,
+		request.Created,
+		request.InstanceID,
+	)
+	if err != nil {
+		tx.Rollback(ctx)
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// DeleteQueuedDeploymentRequest removes a single request from the queue.
+// Returns ErrNotFound if the request is not queued.
+func (db *Database) DeleteQueuedDeploymentRequest(ctx context.Context, deploymentID string) error {
+	query := `DELETE FROM deployment_queue WHERE deployment_id = $1;`
+	tag, err := db.conn.Exec(ctx, query, deploymentID)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
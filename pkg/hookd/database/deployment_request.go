@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// DispatchedDeploymentRequest is a deployment request that has been sent to deployd, but has not yet finished.
+// The request itself is stored as a serialized protobuf message.
+type DispatchedDeploymentRequest struct {
+	DeploymentID string    `json:"deploymentID"`
+	Cluster      string    `json:"cluster"`
+	Request      []byte    `json:"request"`
+	Deadline     time.Time `json:"deadline"`
+	Created      time.Time `json:"created"`
+	// The deployd instance the request was sent to.
+	InstanceID string `json:"instanceID"`
+}
+
+func (db *Database) WriteDispatchedDeploymentRequest(ctx context.Context, request DispatchedDeploymentRequest) error {
+	query := `
+INSERT INTO deployment_request (deployment_id, cluster, request, deadline, created, instance_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (deployment_id) DO UPDATE SET instance_id = EXCLUDED.instance_id;
+`
+	_, err := db.conn.Exec(ctx, query,
+		request.DeploymentID,
+		request.Cluster,
+		request.Request,
+		request.Deadline,
+		request.Created,
+		request.InstanceID,
+	)
+
+	return err
+}
+
+// DispatchedDeploymentRequests returns all unfinished requests sent to a cluster, oldest first.
+func (db *Database) DispatchedDeploymentRequests(ctx context.Context, cluster string) ([]DispatchedDeploymentRequest, error) {
+	query := `
+SELECT deployment_id, cluster, request, deadline, created, instance_id
+FROM deployment_request
+WHERE cluster = $1
+ORDER BY created ASC;
+`
+	rows, err := db.timedQuery(ctx, query, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := make([]DispatchedDeploymentRequest, 0)
+
+	defer rows.Close()
+	for rows.Next() {
+		request := DispatchedDeploymentRequest{}
+
+		err := rows.Scan(
+			&request.DeploymentID,
+			&request.Cluster,
+			&request.Request,
+			&request.Deadline,
+			&request.Created,
+			&request.InstanceID,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		requests = append(requests, request)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+// ClaimDispatchedDeploymentRequest hands an unfinished request over from one deployd instance to another.
+// Returns ErrNotFound if the request is no longer assigned to the previous instance, e.g. because another instance claimed it first.
+func (db *Database) ClaimDispatchedDeploymentRequest(ctx context.Context, deploymentID, previousInstanceID, instanceID string) error {
+	query := `UPDATE deployment_request SET instance_id = $3 WHERE deployment_id = $1 AND instance_id = $2;`
+	tag, err := db.conn.Exec(ctx, query, deploymentID, previousInstanceID, instanceID)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteDispatchedDeploymentRequest forgets a request once its deployment has finished.
+func (db *Database) DeleteDispatchedDeploymentRequest(ctx context.Context, deploymentID string) error {
+	query := `DELETE FROM deployment_request WHERE deployment_id = $1;`
+	_, err := db.conn.Exec(ctx, query, deploymentID)
+	return err
+}
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Freeze blocks deployments while it applies. An empty team or cluster matches all teams or clusters.
+// A freeze applies between Start and End, where nil means unbounded. If it has a cron schedule,
+// it only applies for Duration after each time matching the schedule, in the freeze's time zone.
+type Freeze struct {
+	ID       string     `json:"id"`
+	Team     string     `json:"team"`
+	Cluster  string     `json:"cluster"`
+	Reason   string     `json:"reason"`
+	Start    *time.Time `json:"start"`
+	End      *time.Time `json:"end"`
+	Schedule string     `json:"schedule"`
+	Duration string     `json:"duration"`
+	Timezone string     `json:"timezone"`
+	// Strict freezes can't be overridden.
+	Strict  bool      `json:"strict"`
+	Created time.Time `json:"created"`
+}
+
+type FreezeStore interface {
+	Freezes(ctx context.Context) ([]*Freeze, error)
+	ApplicableFreezes(ctx context.Context, team, cluster string, at time.Time) ([]*Freeze, error)
+	WriteFreeze(ctx context.Context, freeze Freeze) error
+	DeleteFreeze(ctx context.Context, id string) error
+}
+
+var _ FreezeStore = &Database{}
+
+const selectFreezeFields = `id, team, cluster, reason, start, "end", schedule, duration, timezone, strict, created`
+
+// Freezes returns all freezes, including those that no longer apply.
+func (db *Database) Freezes(ctx context.Context) ([]*Freeze, error) {
+	query := `SELECT ` + selectFreezeFields + ` FROM freeze ORDER BY created DESC;`
+	rows, err := db.timedQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanFreezes(rows)
+}
+
+// ApplicableFreezes returns freezes matching the team and cluster, that have started and not yet ended at the given time.
+// Schedules are not taken into account.
+func (db *Database) ApplicableFreezes(ctx context.Context, team, cluster string, at time.Time) ([]*Freeze, error) {
+	query := `
+SELECT ` + selectFreezeFields + `
+FROM freeze
+WHERE (team = '' OR team = $1)
+AND (cluster = '' OR cluster = $2)
+AND (start IS NULL OR start <= $3)
+AND ("end" IS NULL OR "end" > $3)
+ORDER BY created;
+`
+	rows, err := db.timedQuery(ctx, query, team, cluster, at)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanFreezes(rows)
+}
+
+func (db *Database) WriteFreeze(ctx context.Context, freeze Freeze) error {
+	query := `
+INSERT INTO freeze (id, team, cluster, reason, start, "end", schedule, duration, timezone, strict, created)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+`
+	_, err := db.conn.Exec(ctx, query,
+		freeze.ID,
+		freeze.Team,
+		freeze.Cluster,
+		freeze.Reason,
+		freeze.Start,
+		freeze.End,
+		freeze.Schedule,
+		freeze.Duration,
+		freeze.Timezone,
+		freeze.Strict,
+		freeze.Created,
+	)
+
+	return err
+}
+
+func (db *Database) DeleteFreeze(ctx context.Context, id string) error {
+	query := `DELETE FROM freeze WHERE id = $1;`
+	tag, err := db.conn.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func scanFreezes(rows pgx.Rows) ([]*Freeze, error) {
+	defer rows.Close()
+
+	freezes := make([]*Freeze, 0)
+	for rows.Next() {
+		freeze := &Freeze{}
+		err := rows.Scan(
+			&freeze.ID,
+			&freeze.Team,
+			&freeze.Cluster,
+			&freeze.Reason,
+			&freeze.Start,
+			&freeze.End,
+			&freeze.Schedule,
+			&freeze.Duration,
+			&freeze.Timezone,
+			&freeze.Strict,
+			&freeze.Created,
+		)
+		if err != nil {
+			return nil, err
+		}
+		freezes = append(freezes, freeze)
+	}
+
+	return freezes, rows.Err()
+}
@@ -0,0 +1,36 @@
+package database_mapper
+
+import (
+	"time"
+
+	"github.com/nais/deploy/pkg/hookd/database"
+	"github.com/nais/deploy/pkg/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+func DeploymentApproval(request *pb.DeploymentRequest, approvers string) (database.DeploymentApproval, error) {
+	data, err := proto.Marshal(request)
+	if err != nil {
+		return database.DeploymentApproval{}, err
+	}
+	return database.DeploymentApproval{
+		DeploymentID: request.GetID(),
+		Team:         request.GetTeam(),
+		Cluster:      request.GetCluster(),
+		Approvers:    approvers,
+		RequestedBy:  request.GetDeployerUsername(),
+		Request:      data,
+		Deadline:     pb.TimestampAsTime(request.GetDeadline()),
+		Created:      time.Now(),
+		State:        database.ApprovalPending,
+	}, nil
+}
+
+func PbApprovalRequest(approval database.DeploymentApproval) (*pb.DeploymentRequest, error) {
+	pbRequest := &pb.DeploymentRequest{}
+	err := proto.Unmarshal(approval.Request, pbRequest)
+	if err != nil {
+		return nil, err
+	}
+	return pbRequest, nil
+}
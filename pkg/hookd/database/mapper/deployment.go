@@ -0,0 +1,40 @@
+package database_mapper
+
+import (
+	"github.com/nais/deploy/pkg/hookd/database"
+	"github.com/nais/deploy/pkg/pb"
+)
+
+func PbDeployment(deploy database.Deployment) *pb.Deployment {
+	deployment := &pb.Deployment{
+		ID:      deploy.ID,
+		Team:    deploy.Team,
+		Created: pb.TimeAsTimestamp(deploy.Created),
+	}
+	if deploy.Cluster != nil {
+		deployment.Cluster = *deploy.Cluster
+	}
+	if deploy.GitHubRepository != nil {
+		deployment.Repository = *deploy.GitHubRepository
+	}
+	if deploy.State != nil {
+		if state, ok := pb.DeploymentState_value[*deploy.State]; ok {
+			deployment.State = pb.DeploymentState(state).Enum()
+		}
+	}
+	if operation, ok := pb.DeploymentOperation_value[deploy.Operation]; ok {
+		deployment.Operation = pb.DeploymentOperation(operation)
+	}
+	return deployment
+}
+
+func PbKubernetesResource(resource database.DeploymentResource) *pb.KubernetesResource {
+	return &pb.KubernetesResource{
+		Group:     resource.Group,
+		Version:   resource.Version,
+		Kind:      resource.Kind,
+		Name:      resource.Name,
+		Namespace: resource.Namespace,
+		Deleted:   resource.Deleted,
+	}
+}
@@ -0,0 +1,106 @@
+package database_mapper
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/nais/deploy/pkg/hookd/database"
+	"github.com/nais/deploy/pkg/k8sutils"
+	"github.com/nais/deploy/pkg/pb"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DeploymentManifest redacts secrets from the resources of a deployment, and compresses them for storage.
+// The original request is kept as well, so that the deployment can be made again.
+func DeploymentManifest(request *pb.DeploymentRequest, resources []unstructured.Unstructured) (database.DeploymentManifest, error) {
+	redacted := make([]map[string]any, len(resources))
+	for i := range resources {
+		redacted[i] = k8sutils.Redact(resources[i]).Object
+	}
+
+	data, err := json.Marshal(redacted)
+	if err != nil {
+		return database.DeploymentManifest{}, err
+	}
+	data, err = compress(data)
+	if err != nil {
+		return database.DeploymentManifest{}, err
+	}
+
+	serialized, err := proto.Marshal(request)
+	if err != nil {
+		return database.DeploymentManifest{}, err
+	}
+	serialized, err = compress(serialized)
+	if err != nil {
+		return database.DeploymentManifest{}, err
+	}
+
+	return database.DeploymentManifest{
+		DeploymentID: request.GetID(),
+		Data:         data,
+		Request:      serialized,
+		Created:      time.Now(),
+	}, nil
+}
+
+func ManifestResources(manifest database.DeploymentManifest) ([]unstructured.Unstructured, error) {
+	data, err := decompress(manifest.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make([]json.RawMessage, 0)
+	err = json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return k8sutils.ResourcesFromJSON(raw)
+}
+
+// ManifestRequest returns the original deployment request of a manifest, or nil if it was not stored.
+func ManifestRequest(manifest database.DeploymentManifest) (*pb.DeploymentRequest, error) {
+	if manifest.Request == nil {
+		return nil, nil
+	}
+
+	data, err := decompress(manifest.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &pb.DeploymentRequest{}
+	err = proto.Unmarshal(data, request)
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func compress(data []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	writer := gzip.NewWriter(buf)
+	_, err := writer.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompress(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
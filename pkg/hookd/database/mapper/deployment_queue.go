@@ -0,0 +1,32 @@
+package database_mapper
+
+import (
+	"time"
+
+	"github.com/nais/deploy/pkg/hookd/database"
+	"github.com/nais/deploy/pkg/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+func QueuedDeploymentRequest(request *pb.DeploymentRequest) (database.QueuedDeploymentRequest, error) {
+	data, err := proto.Marshal(request)
+	if err != nil {
+		return database.QueuedDeploymentRequest{}, err
+	}
+	return database.QueuedDeploymentRequest{
+		DeploymentID: request.GetID(),
+		Cluster:      request.GetCluster(),
+		Request:      data,
+		Deadline:     pb.TimestampAsTime(request.GetDeadline()),
+		Created:      time.Now(),
+	}, nil
+}
+
+func PbQueuedRequest(request database.QueuedDeploymentRequest) (*pb.DeploymentRequest, error) {
+	pbRequest := &pb.DeploymentRequest{}
+	err := proto.Unmarshal(request.Request, pbRequest)
+	if err != nil {
+		return nil, err
+	}
+	return pbRequest, nil
+}
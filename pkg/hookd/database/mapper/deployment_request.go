@@ -0,0 +1,32 @@
+package database_mapper
+
+import (
+	"time"
+
+	"github.com/nais/deploy/pkg/hookd/database"
+	"github.com/nais/deploy/pkg/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+func DispatchedDeploymentRequest(request *pb.DeploymentRequest) (database.DispatchedDeploymentRequest, error) {
+	data, err := proto.Marshal(request)
+	if err != nil {
+		return database.DispatchedDeploymentRequest{}, err
+	}
+	return database.DispatchedDeploymentRequest{
+		DeploymentID: request.GetID(),
+		Cluster:      request.GetCluster(),
+		Request:      data,
+		Deadline:     pb.TimestampAsTime(request.GetDeadline()),
+		Created:      time.Now(),
+	}, nil
+}
+
+func PbDispatchedRequest(request database.DispatchedDeploymentRequest) (*pb.DeploymentRequest, error) {
+	pbRequest := &pb.DeploymentRequest{}
+	err := proto.Unmarshal(request.Request, pbRequest)
+	if err != nil {
+		return nil, err
+	}
+	return pbRequest, nil
+}
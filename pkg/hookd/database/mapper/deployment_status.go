@@ -13,6 +13,7 @@ func DeploymentStatus(status *pb.DeploymentStatus) database.DeploymentStatus {
 		Status:       status.GetState().String(),
 		Message:      status.GetMessage(),
 		Created:      status.Timestamp(),
+		Cancelled:    status.GetCancelled(),
 	}
 }
 
@@ -21,9 +22,10 @@ func PbStatus(status database.DeploymentStatus) *pb.DeploymentStatus {
 		Request: &pb.DeploymentRequest{
 			ID: status.DeploymentID,
 		},
-		Time:    pb.TimeAsTimestamp(status.Created),
-		State:   pb.DeploymentState(pb.DeploymentState_value[status.Status]),
-		Message: status.Message,
+		Time:      pb.TimeAsTimestamp(status.Created),
+		State:     pb.DeploymentState(pb.DeploymentState_value[status.Status]),
+		Message:   status.Message,
+		Cancelled: status.Cancelled,
 	}
 }
 
@@ -0,0 +1,62 @@
+package database_mapper
+
+import (
+	"strings"
+	"time"
+
+	"github.com/nais/deploy/pkg/hookd/database"
+	"github.com/nais/deploy/pkg/k8sutils"
+	"github.com/nais/deploy/pkg/pb"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// TeardownRequest returns a request that deletes the given resources of a preview environment.
+// Only the identity of each resource is included, which is all deployd needs to delete it.
+// Resources that a deployment has already deleted are left out, and resources recorded by several deployments
+// are only included once.
+func TeardownRequest(preview database.Preview, resources []database.DeploymentResource) (*pb.DeploymentRequest, error) {
+	identifiers := make([]k8sutils.Identifier, 0, len(resources))
+	seen := make(map[k8sutils.Identifier]bool, len(resources))
+	for _, resource := range resources {
+		if resource.Deleted {
+			continue
+		}
+		identifier := k8sutils.Identifier{
+			GroupVersionKind: schema.GroupVersionKind{
+				Group:   resource.Group,
+				Version: resource.Version,
+				Kind:    resource.Kind,
+			},
+			Namespace: resource.Namespace,
+			Name:      resource.Name,
+		}
+		if seen[identifier] {
+			continue
+		}
+		seen[identifier] = true
+		identifiers = append(identifiers, identifier)
+	}
+
+	kube, err := k8sutils.KubernetesFromIdentifiers(identifiers)
+	if err != nil {
+		return nil, err
+	}
+
+	var repository *pb.GithubRepository
+	if owner, name, found := strings.Cut(preview.Repository, "/"); found {
+		repository = &pb.GithubRepository{
+			Owner: owner,
+			Name:  name,
+		}
+	}
+
+	return &pb.DeploymentRequest{
+		Time:       pb.TimeAsTimestamp(time.Now()),
+		Cluster:    preview.Cluster,
+		Team:       preview.Team,
+		Kubernetes: kube,
+		Repository: repository,
+		Preview:    preview.Name,
+		Delete:     true,
+	}, nil
+}
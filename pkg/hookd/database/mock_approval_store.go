@@ -0,0 +1,147 @@
+// Code generated by mockery v2.33.2. DO NOT EDIT.
+
+package database
+
+import (
+	context "context"
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockApprovalStore is an autogenerated mock type for the ApprovalStore type
+type MockApprovalStore struct {
+	mock.Mock
+}
+
+// DecideDeploymentApproval provides a mock function with given fields: ctx, deploymentID, state, decidedBy, comment, decided
+func (_m *MockApprovalStore) DecideDeploymentApproval(ctx context.Context, deploymentID string, state string, decidedBy string, comment string, decided time.Time) (*DeploymentApproval, error) {
+	ret := _m.Called(ctx, deploymentID, state, decidedBy, comment, decided)
+
+	var r0 *DeploymentApproval
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string, time.Time) (*DeploymentApproval, error)); ok {
+		return rf(ctx, deploymentID, state, decidedBy, comment, decided)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string, time.Time) *DeploymentApproval); ok {
+		r0 = rf(ctx, deploymentID, state, decidedBy, comment, decided)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DeploymentApproval)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, string, time.Time) error); ok {
+		r1 = rf(ctx, deploymentID, state, decidedBy, comment, decided)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeploymentApproval provides a mock function with given fields: ctx, deploymentID
+func (_m *MockApprovalStore) DeploymentApproval(ctx context.Context, deploymentID string) (*DeploymentApproval, error) {
+	ret := _m.Called(ctx, deploymentID)
+
+	var r0 *DeploymentApproval
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*DeploymentApproval, error)); ok {
+		return rf(ctx, deploymentID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *DeploymentApproval); ok {
+		r0 = rf(ctx, deploymentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DeploymentApproval)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, deploymentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExpireDeploymentApprovals provides a mock function with given fields: ctx, before
+func (_m *MockApprovalStore) ExpireDeploymentApprovals(ctx context.Context, before time.Time) ([]*DeploymentApproval, error) {
+	ret := _m.Called(ctx, before)
+
+	var r0 []*DeploymentApproval
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]*DeploymentApproval, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []*DeploymentApproval); ok {
+		r0 = rf(ctx, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*DeploymentApproval)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PendingDeploymentApprovals provides a mock function with given fields: ctx
+func (_m *MockApprovalStore) PendingDeploymentApprovals(ctx context.Context) ([]*DeploymentApproval, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*DeploymentApproval
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*DeploymentApproval, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*DeploymentApproval); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*DeploymentApproval)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WriteDeploymentApproval provides a mock function with given fields: ctx, approval
+func (_m *MockApprovalStore) WriteDeploymentApproval(ctx context.Context, approval DeploymentApproval) error {
+	ret := _m.Called(ctx, approval)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, DeploymentApproval) error); ok {
+		r0 = rf(ctx, approval)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewMockApprovalStore creates a new instance of MockApprovalStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockApprovalStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockApprovalStore {
+	mock := &MockApprovalStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -14,6 +14,164 @@ type MockDeploymentStore struct {
 	mock.Mock
 }
 
+// ClaimDispatchedDeploymentRequest provides a mock function with given fields: ctx, deploymentID, previousInstanceID, instanceID
+func (_m *MockDeploymentStore) ClaimDispatchedDeploymentRequest(ctx context.Context, deploymentID string, previousInstanceID string, instanceID string) error {
+	ret := _m.Called(ctx, deploymentID, previousInstanceID, instanceID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, deploymentID, previousInstanceID, instanceID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteDeploydInstance provides a mock function with given fields: ctx, cluster, instanceID
+func (_m *MockDeploymentStore) DeleteDeploydInstance(ctx context.Context, cluster string, instanceID string) error {
+	ret := _m.Called(ctx, cluster, instanceID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, cluster, instanceID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteDeploymentManifests provides a mock function with given fields: ctx, before
+func (_m *MockDeploymentStore) DeleteDeploymentManifests(ctx context.Context, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, before)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteDispatchedDeploymentRequest provides a mock function with given fields: ctx, deploymentID
+func (_m *MockDeploymentStore) DeleteDispatchedDeploymentRequest(ctx context.Context, deploymentID string) error {
+	ret := _m.Called(ctx, deploymentID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, deploymentID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteExpiredPreviews provides a mock function with given fields: ctx, before
+func (_m *MockDeploymentStore) DeleteExpiredPreviews(ctx context.Context, before time.Time) ([]*Preview, error) {
+	ret := _m.Called(ctx, before)
+
+	var r0 []*Preview
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]*Preview, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []*Preview); ok {
+		r0 = rf(ctx, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*Preview)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeletePreview provides a mock function with given fields: ctx, team, cluster, repository, name
+func (_m *MockDeploymentStore) DeletePreview(ctx context.Context, team string, cluster string, repository string, name string) (*Preview, error) {
+	ret := _m.Called(ctx, team, cluster, repository, name)
+
+	var r0 *Preview
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) (*Preview, error)); ok {
+		return rf(ctx, team, cluster, repository, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) *Preview); ok {
+		r0 = rf(ctx, team, cluster, repository, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Preview)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, string) error); ok {
+		r1 = rf(ctx, team, cluster, repository, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteQueuedDeploymentRequest provides a mock function with given fields: ctx, deploymentID
+func (_m *MockDeploymentStore) DeleteQueuedDeploymentRequest(ctx context.Context, deploymentID string) error {
+	ret := _m.Called(ctx, deploymentID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, deploymentID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeploydInstances provides a mock function with given fields: ctx, cluster, since
+func (_m *MockDeploymentStore) DeploydInstances(ctx context.Context, cluster string, since time.Time) ([]string, error) {
+	ret := _m.Called(ctx, cluster, since)
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) ([]string, error)); ok {
+		return rf(ctx, cluster, since)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) []string); ok {
+		r0 = rf(ctx, cluster, since)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, cluster, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Deployment provides a mock function with given fields: ctx, id
 func (_m *MockDeploymentStore) Deployment(ctx context.Context, id string) (*Deployment, error) {
 	ret := _m.Called(ctx, id)
@@ -40,6 +198,32 @@ func (_m *MockDeploymentStore) Deployment(ctx context.Context, id string) (*Depl
 	return r0, r1
 }
 
+// DeploymentManifest provides a mock function with given fields: ctx, deploymentID
+func (_m *MockDeploymentStore) DeploymentManifest(ctx context.Context, deploymentID string) (*DeploymentManifest, error) {
+	ret := _m.Called(ctx, deploymentID)
+
+	var r0 *DeploymentManifest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*DeploymentManifest, error)); ok {
+		return rf(ctx, deploymentID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *DeploymentManifest); ok {
+		r0 = rf(ctx, deploymentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DeploymentManifest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, deploymentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeploymentResources provides a mock function with given fields: ctx, deploymentID
 func (_m *MockDeploymentStore) DeploymentResources(ctx context.Context, deploymentID string) ([]DeploymentResource, error) {
 	ret := _m.Called(ctx, deploymentID)
@@ -118,6 +302,72 @@ func (_m *MockDeploymentStore) Deployments(ctx context.Context, teams []string,
 	return r0, r1
 }
 
+// DispatchQueuedDeploymentRequest provides a mock function with given fields: ctx, request
+func (_m *MockDeploymentStore) DispatchQueuedDeploymentRequest(ctx context.Context, request DispatchedDeploymentRequest) error {
+	ret := _m.Called(ctx, request)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, DispatchedDeploymentRequest) error); ok {
+		r0 = rf(ctx, request)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DispatchedDeploymentRequests provides a mock function with given fields: ctx, cluster
+func (_m *MockDeploymentStore) DispatchedDeploymentRequests(ctx context.Context, cluster string) ([]DispatchedDeploymentRequest, error) {
+	ret := _m.Called(ctx, cluster)
+
+	var r0 []DispatchedDeploymentRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]DispatchedDeploymentRequest, error)); ok {
+		return rf(ctx, cluster)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []DispatchedDeploymentRequest); ok {
+		r0 = rf(ctx, cluster)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]DispatchedDeploymentRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, cluster)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExpiredQueuedDeploymentRequests provides a mock function with given fields: ctx, before
+func (_m *MockDeploymentStore) ExpiredQueuedDeploymentRequests(ctx context.Context, before time.Time) ([]QueuedDeploymentRequest, error) {
+	ret := _m.Called(ctx, before)
+
+	var r0 []QueuedDeploymentRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]QueuedDeploymentRequest, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []QueuedDeploymentRequest); ok {
+		r0 = rf(ctx, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]QueuedDeploymentRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // HistoricDeployments provides a mock function with given fields: ctx, cluster, timestamp
 func (_m *MockDeploymentStore) HistoricDeployments(ctx context.Context, cluster string, timestamp time.Time) ([]*Deployment, error) {
 	ret := _m.Called(ctx, cluster, timestamp)
@@ -144,6 +394,112 @@ func (_m *MockDeploymentStore) HistoricDeployments(ctx context.Context, cluster
 	return r0, r1
 }
 
+// ListDeployments provides a mock function with given fields: ctx, filter
+func (_m *MockDeploymentStore) ListDeployments(ctx context.Context, filter DeploymentFilter) ([]*Deployment, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*Deployment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, DeploymentFilter) ([]*Deployment, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, DeploymentFilter) []*Deployment); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*Deployment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, DeploymentFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PreviousDeployment provides a mock function with given fields: ctx, deploymentID
+func (_m *MockDeploymentStore) PreviousDeployment(ctx context.Context, deploymentID string) (*Deployment, error) {
+	ret := _m.Called(ctx, deploymentID)
+
+	var r0 *Deployment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*Deployment, error)); ok {
+		return rf(ctx, deploymentID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *Deployment); ok {
+		r0 = rf(ctx, deploymentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Deployment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, deploymentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// QueueDeploymentRequest provides a mock function with given fields: ctx, request
+func (_m *MockDeploymentStore) QueueDeploymentRequest(ctx context.Context, request QueuedDeploymentRequest) error {
+	ret := _m.Called(ctx, request)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, QueuedDeploymentRequest) error); ok {
+		r0 = rf(ctx, request)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// QueuedDeploymentRequests provides a mock function with given fields: ctx, cluster
+func (_m *MockDeploymentStore) QueuedDeploymentRequests(ctx context.Context, cluster string) ([]QueuedDeploymentRequest, error) {
+	ret := _m.Called(ctx, cluster)
+
+	var r0 []QueuedDeploymentRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]QueuedDeploymentRequest, error)); ok {
+		return rf(ctx, cluster)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []QueuedDeploymentRequest); ok {
+		r0 = rf(ctx, cluster)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]QueuedDeploymentRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, cluster)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WriteDeploydInstance provides a mock function with given fields: ctx, cluster, instanceID
+func (_m *MockDeploymentStore) WriteDeploydInstance(ctx context.Context, cluster string, instanceID string) error {
+	ret := _m.Called(ctx, cluster, instanceID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, cluster, instanceID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // WriteDeployment provides a mock function with given fields: ctx, deployment
 func (_m *MockDeploymentStore) WriteDeployment(ctx context.Context, deployment Deployment) error {
 	ret := _m.Called(ctx, deployment)
@@ -158,6 +514,20 @@ func (_m *MockDeploymentStore) WriteDeployment(ctx context.Context, deployment D
 	return r0
 }
 
+// WriteDeploymentManifest provides a mock function with given fields: ctx, manifest
+func (_m *MockDeploymentStore) WriteDeploymentManifest(ctx context.Context, manifest DeploymentManifest) error {
+	ret := _m.Called(ctx, manifest)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, DeploymentManifest) error); ok {
+		r0 = rf(ctx, manifest)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // WriteDeploymentResource provides a mock function with given fields: ctx, resource
 func (_m *MockDeploymentStore) WriteDeploymentResource(ctx context.Context, resource DeploymentResource) error {
 	ret := _m.Called(ctx, resource)
@@ -186,6 +556,34 @@ func (_m *MockDeploymentStore) WriteDeploymentStatus(ctx context.Context, status
 	return r0
 }
 
+// WriteDispatchedDeploymentRequest provides a mock function with given fields: ctx, request
+func (_m *MockDeploymentStore) WriteDispatchedDeploymentRequest(ctx context.Context, request DispatchedDeploymentRequest) error {
+	ret := _m.Called(ctx, request)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, DispatchedDeploymentRequest) error); ok {
+		r0 = rf(ctx, request)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WritePreview provides a mock function with given fields: ctx, preview
+func (_m *MockDeploymentStore) WritePreview(ctx context.Context, preview Preview) error {
+	ret := _m.Called(ctx, preview)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, Preview) error); ok {
+		r0 = rf(ctx, preview)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewMockDeploymentStore creates a new instance of MockDeploymentStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockDeploymentStore(t interface {
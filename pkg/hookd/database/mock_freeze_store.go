@@ -0,0 +1,109 @@
+// Code generated by mockery v2.33.2. DO NOT EDIT.
+
+package database
+
+import (
+	context "context"
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockFreezeStore is an autogenerated mock type for the FreezeStore type
+type MockFreezeStore struct {
+	mock.Mock
+}
+
+// ApplicableFreezes provides a mock function with given fields: ctx, team, cluster, at
+func (_m *MockFreezeStore) ApplicableFreezes(ctx context.Context, team string, cluster string, at time.Time) ([]*Freeze, error) {
+	ret := _m.Called(ctx, team, cluster, at)
+
+	var r0 []*Freeze
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) ([]*Freeze, error)); ok {
+		return rf(ctx, team, cluster, at)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) []*Freeze); ok {
+		r0 = rf(ctx, team, cluster, at)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*Freeze)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Time) error); ok {
+		r1 = rf(ctx, team, cluster, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteFreeze provides a mock function with given fields: ctx, id
+func (_m *MockFreezeStore) DeleteFreeze(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Freezes provides a mock function with given fields: ctx
+func (_m *MockFreezeStore) Freezes(ctx context.Context) ([]*Freeze, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*Freeze
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*Freeze, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*Freeze); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*Freeze)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WriteFreeze provides a mock function with given fields: ctx, freeze
+func (_m *MockFreezeStore) WriteFreeze(ctx context.Context, freeze Freeze) error {
+	ret := _m.Called(ctx, freeze)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, Freeze) error); ok {
+		r0 = rf(ctx, freeze)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewMockFreezeStore creates a new instance of MockFreezeStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockFreezeStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockFreezeStore {
+	mock := &MockFreezeStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,75 @@
+// Code generated by mockery v2.33.2. DO NOT EDIT.
+
+package database
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockNotifier is an autogenerated mock type for the Notifier type
+type MockNotifier struct {
+	mock.Mock
+}
+
+// Listen provides a mock function with given fields: ctx, channels
+func (_m *MockNotifier) Listen(ctx context.Context, channels ...string) (<-chan Notification, error) {
+	_va := make([]interface{}, len(channels))
+	for _i := range channels {
+		_va[_i] = channels[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 <-chan Notification
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, ...string) (<-chan Notification, error)); ok {
+		return rf(ctx, channels...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ...string) <-chan Notification); ok {
+		r0 = rf(ctx, channels...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan Notification)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ...string) error); ok {
+		r1 = rf(ctx, channels...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Notify provides a mock function with given fields: ctx, channel, payload
+func (_m *MockNotifier) Notify(ctx context.Context, channel string, payload string) error {
+	ret := _m.Called(ctx, channel, payload)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, channel, payload)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewMockNotifier creates a new instance of MockNotifier. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockNotifier(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockNotifier {
+	mock := &MockNotifier{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
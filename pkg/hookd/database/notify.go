@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// Notification channels used to coordinate hookd instances sharing the same database.
+const (
+	// Payload is the ID of the publishing hookd instance and a deployment status, separated by a space;
+	// delivered to the status subscribers of the other instances.
+	ChannelDeploymentStatus = "deployment_status"
+	// Payload is a cluster name; the instance connected to that cluster sends its queued deployment requests.
+	ChannelDeploymentQueue = "deployment_queue"
+	// Payload is a cancel request; the instance connected to the request's cluster forwards it to deployd.
+	ChannelDeploymentCancel = "deployment_cancel"
+)
+
+// PostgreSQL rejects notification payloads of this size or larger.
+const MaxNotificationPayload = 8000
+
+// Wait this long before reconnecting after losing the listener connection.
+const listenReconnectInterval = 5 * time.Second
+
+type Notification struct {
+	Channel string
+	Payload string
+}
+
+// Notifier broadcasts messages to every hookd instance, including the sender.
+type Notifier interface {
+	Notify(ctx context.Context, channel, payload string) error
+	// Listen delivers notifications on the given channels until the context is cancelled.
+	Listen(ctx context.Context, channels ...string) (<-chan Notification, error)
+}
+
+var _ Notifier = &Database{}
+
+func (db *Database) Notify(ctx context.Context, channel, payload string) error {
+	_, err := db.conn.Exec(ctx, `SELECT pg_notify($1, $2);`, channel, payload)
+	return err
+}
+
+func (db *Database) Listen(ctx context.Context, channels ...string) (<-chan Notification, error) {
+	conn, err := db.listen(ctx, channels)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications := make(chan Notification, 64)
+
+	go func() {
+		defer close(notifications)
+		for {
+			for {
+				notification, err := conn.WaitForNotification(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						log.Errorf("Lost database notification listener: %s", err)
+					}
+					break
+				}
+				notifications <- Notification{
+					Channel: notification.Channel,
+					Payload: notification.Payload,
+				}
+			}
+
+			_ = conn.Close(context.Background())
+
+			// Notifications sent while reconnecting are lost.
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(listenReconnectInterval):
+				}
+				conn, err = db.listen(ctx, channels)
+				if err == nil {
+					break
+				}
+				log.Errorf("Reconnect database notification listener: %s", err)
+			}
+		}
+	}()
+
+	return notifications, nil
+}
+
+// Open a dedicated connection that listens on all channels. Notifications can only be received
+// on the connection that issued LISTEN, so this connection is taken out of the pool for good.
+func (db *Database) listen(ctx context.Context, channels []string) (*pgx.Conn, error) {
+	pooled, err := db.conn.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn := pooled.Hijack()
+
+	for _, channel := range channels {
+		_, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize())
+		if err != nil {
+			_ = conn.Close(context.Background())
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
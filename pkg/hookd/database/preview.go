@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Preview is a pull request preview environment, along with the deployments made to it.
+type Preview struct {
+	Team    string `json:"team"`
+	Cluster string `json:"cluster"`
+	// Full name of the GitHub repository, in the form owner/name. Empty if the deployment was not made from a repository.
+	Repository   string    `json:"repository"`
+	Name         string    `json:"name"`
+	DeploymentID string    `json:"deploymentID"`
+	Expires      time.Time `json:"expires"`
+	// Every deployment made to the environment, including the latest one.
+	DeploymentIDs []string `json:"deploymentIDs"`
+}
+
+// deletePreviewsQuery stops tracking the previews matching a WHERE clause on the preview table,
+// and returns each of them along with every deployment made to it.
+const deletePreviewsQuery = `
+WITH deleted AS (
+    DELETE FROM preview
+    WHERE %s
+    RETURNING team, cluster, repository, name, deployment_id, expires
+), history AS (
+    DELETE FROM preview_deployment pd
+    USING deleted d
+    WHERE pd.team = d.team AND pd.cluster = d.cluster AND pd.repository = d.repository AND pd.name = d.name
+    RETURNING pd.team, pd.cluster, pd.repository, pd.name, pd.deployment_id
+)
+SELECT d.team, d.cluster, d.repository, d.name, d.deployment_id, d.expires,
+       coalesce(array_agg(h.deployment_id) FILTER (WHERE h.deployment_id IS NOT NULL), '{}')
+FROM deleted d
+LEFT JOIN history h ON h.team = d.team AND h.cluster = d.cluster AND h.repository = d.repository AND h.name = d.name
+GROUP BY d.team, d.cluster, d.repository, d.name, d.deployment_id, d.expires;
+`
+
+// WritePreview records the latest deployment to a preview environment, along with when the environment expires.
+// The deployment is added to the deployments made to the environment, as are any earlier ones listed in the preview.
+func (db *Database) WritePreview(ctx context.Context, preview Preview) error {
+	tx, err := db.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to start transaction: %s", err)
+	}
+
+	query := `
+INSERT INTO preview (team, cluster, repository, name, deployment_id, expires)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (team, cluster, repository, name) DO UPDATE
+SET deployment_id = EXCLUDED.deployment_id, expires = EXCLUDED.expires;
+`
+	_, err = tx.Exec(ctx, query,
+		preview.Team,
+		preview.Cluster,
+		preview.Repository,
+		preview.Name,
+		preview.DeploymentID,
+		preview.Expires,
+	)
+	if err != nil {
+		tx.Rollback(ctx)
+		return err
+	}
+
+	query = `
+INSERT INTO preview_deployment (team, cluster, repository, name, deployment_id)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT DO NOTHING;
+`
+	for _, deploymentID := range append([]string{preview.DeploymentID}, preview.DeploymentIDs...) {
+		_, err = tx.Exec(ctx, query,
+			preview.Team,
+			preview.Cluster,
+			preview.Repository,
+			preview.Name,
+			deploymentID,
+		)
+		if err != nil {
+			tx.Rollback(ctx)
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// DeletePreview stops tracking a preview environment, and returns it so that it can be torn down.
+// Only one caller gets the preview, so that it is not torn down twice.
+func (db *Database) DeletePreview(ctx context.Context, team, cluster, repository, name string) (*Preview, error) {
+	query := fmt.Sprintf(deletePreviewsQuery, "team = $1 AND cluster = $2 AND repository = $3 AND name = $4")
+	rows, err := db.timedQuery(ctx, query, team, cluster, repository, name)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	if rows.Next() {
+		return scanPreview(rows)
+	}
+
+	return nil, ErrNotFound
+}
+
+// DeleteExpiredPreviews stops tracking preview environments that expired before the given time, and returns them so that they can be torn down.
+func (db *Database) DeleteExpiredPreviews(ctx context.Context, before time.Time) ([]*Preview, error) {
+	query := fmt.Sprintf(deletePreviewsQuery, "expires < $1")
+	rows, err := db.timedQuery(ctx, query, before)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	previews := make([]*Preview, 0)
+	for rows.Next() {
+		preview, err := scanPreview(rows)
+		if err != nil {
+			return nil, err
+		}
+		previews = append(previews, preview)
+	}
+
+	return previews, rows.Err()
+}
+
+func scanPreview(rows pgx.Rows) (*Preview, error) {
+	preview := &Preview{}
+
+	err := rows.Scan(
+		&preview.Team,
+		&preview.Cluster,
+		&preview.Repository,
+		&preview.Name,
+		&preview.DeploymentID,
+		&preview.Expires,
+		&preview.DeploymentIDs,
+	)
+
+	return preview, err
+}
@@ -0,0 +1,20 @@
+-- Run the entire migration as an atomic operation.
+START TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;
+
+-- Table deployment_queue holds deployment requests for clusters that are not currently connected.
+-- The request is stored as a serialized protobuf message, and sent to deployd when the cluster comes online.
+CREATE TABLE deployment_queue
+(
+    "deployment_id" varchar primary key references deployment (id) not null,
+    "cluster"       varchar                                        not null,
+    "request"       bytea                                          not null,
+    "deadline"      timestamp with time zone                       not null,
+    "created"       timestamp with time zone                       not null
+);
+
+CREATE INDEX deployment_queue_cluster ON deployment_queue (cluster);
+
+-- Mark this database migration as completed.
+INSERT INTO migrations (version, created)
+VALUES (10, now());
+COMMIT;
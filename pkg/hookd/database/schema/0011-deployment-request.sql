@@ -0,0 +1,20 @@
+-- Run the entire migration as an atomic operation.
+START TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;
+
+-- Table deployment_request holds deployment requests that have been sent to deployd, but have not yet finished.
+-- If deployd restarts in the middle of a deployment, the request is handed back so that the rollout can be resumed.
+CREATE TABLE deployment_request
+(
+    "deployment_id" varchar primary key references deployment (id) not null,
+    "cluster"       varchar                                        not null,
+    "request"       bytea                                          not null,
+    "deadline"      timestamp with time zone                       not null,
+    "created"       timestamp with time zone                       not null
+);
+
+CREATE INDEX deployment_request_cluster ON deployment_request (cluster);
+
+-- Mark this database migration as completed.
+INSERT INTO migrations (version, created)
+VALUES (11, now());
+COMMIT;
@@ -0,0 +1,18 @@
+-- Run the entire migration as an atomic operation.
+START TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;
+
+-- Table deployment_manifest holds the Kubernetes resources of a deployment, with secrets redacted,
+-- as a gzip compressed JSON array. Manifests are removed once they are older than the configured retention.
+CREATE TABLE deployment_manifest
+(
+    "deployment_id" varchar primary key references deployment (id) not null,
+    "data"          bytea                                          not null,
+    "created"       timestamp with time zone                       not null
+);
+
+CREATE INDEX deployment_manifest_created ON deployment_manifest (created);
+
+-- Mark this database migration as completed.
+INSERT INTO migrations (version, created)
+VALUES (12, now());
+COMMIT;
@@ -0,0 +1,11 @@
+-- Run the entire migration as an atomic operation.
+START TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;
+
+-- The original deployment request, including unredacted resources, so that the deployment can be made again.
+-- Encrypted with the database encryption key, as it may contain secrets.
+ALTER TABLE deployment_manifest ADD COLUMN "request" bytea;
+
+-- Mark this database migration as completed.
+INSERT INTO migrations (version, created)
+VALUES (13, now());
+COMMIT;
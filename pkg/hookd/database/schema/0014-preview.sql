@@ -0,0 +1,22 @@
+-- Run the entire migration as an atomic operation.
+START TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;
+
+-- Table preview tracks pull request preview environments, along with the latest deployment made to each of them.
+-- Previews are torn down by deleting the resources of that deployment, either on request or once they have expired.
+CREATE TABLE preview
+(
+    "team"          varchar                                    not null,
+    "cluster"       varchar                                    not null,
+    "repository"    varchar                                    not null default '',
+    "name"          varchar                                    not null,
+    "deployment_id" varchar references deployment (id)         not null,
+    "expires"       timestamp with time zone                   not null,
+    primary key (team, cluster, repository, name)
+);
+
+CREATE INDEX preview_expires ON preview (expires);
+
+-- Mark this database migration as completed.
+INSERT INTO migrations (version, created)
+VALUES (14, now());
+COMMIT;
@@ -0,0 +1,10 @@
+-- Run the entire migration as an atomic operation.
+START TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;
+
+-- Resources deleted by a deployment, such as resources pruned because they were removed from the deploy set.
+ALTER TABLE deployment_resource ADD COLUMN "deleted" boolean not null default false;
+
+-- Mark this database migration as completed.
+INSERT INTO migrations (version, created)
+VALUES (15, now());
+COMMIT;
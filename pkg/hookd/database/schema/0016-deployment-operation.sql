@@ -0,0 +1,10 @@
+-- Run the entire migration as an atomic operation.
+START TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;
+
+-- What a deployment does with its resources; either deploy, or undeploy to delete them.
+ALTER TABLE deployment ADD COLUMN "operation" varchar not null default 'deploy';
+
+-- Mark this database migration as completed.
+INSERT INTO migrations (version, created)
+VALUES (16, now());
+COMMIT;
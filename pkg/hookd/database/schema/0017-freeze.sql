@@ -0,0 +1,25 @@
+-- Run the entire migration as an atomic operation.
+START TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;
+
+-- Table freeze holds deployment freezes, which block deployments to a cluster, by a team, or altogether.
+-- An empty team or cluster matches all teams or clusters. A freeze applies between start and end, where missing
+-- means unbounded. If it has a schedule, it only applies for duration after each time matching the schedule.
+CREATE TABLE freeze
+(
+    "id"       varchar                  primary key,
+    "team"     varchar                  not null default '',
+    "cluster"  varchar                  not null default '',
+    "reason"   varchar                  not null,
+    "start"    timestamp with time zone null,
+    "end"      timestamp with time zone null,
+    "schedule" varchar                  not null default '',
+    "duration" varchar                  not null default '',
+    "timezone" varchar                  not null default 'UTC',
+    "strict"   boolean                  not null default false,
+    "created"  timestamp with time zone not null
+);
+
+-- Mark this database migration as completed.
+INSERT INTO migrations (version, created)
+VALUES (17, now());
+COMMIT;
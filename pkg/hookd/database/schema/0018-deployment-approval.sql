@@ -0,0 +1,28 @@
+-- Run the entire migration as an atomic operation.
+START TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;
+
+-- Table deployment_approval holds deployment requests to clusters where deployments must be approved.
+-- The request is dispatched to deployd once approved. Rejected requests, and requests that are not
+-- approved before their deadline, are never dispatched.
+CREATE TABLE deployment_approval
+(
+    "deployment_id" varchar primary key references deployment (id) not null,
+    "team"          varchar                                        not null,
+    "cluster"       varchar                                        not null,
+    "approvers"     varchar                                        not null,
+    "requested_by"  varchar                                        not null default '',
+    "request"       bytea                                          not null,
+    "deadline"      timestamp with time zone                       not null,
+    "created"       timestamp with time zone                       not null,
+    "state"         varchar                                        not null default 'pending',
+    "decided_by"    varchar                                        not null default '',
+    "decided"       timestamp with time zone                       null,
+    "comment"       varchar                                        not null default ''
+);
+
+CREATE INDEX deployment_approval_state ON deployment_approval (state, deadline);
+
+-- Mark this database migration as completed.
+INSERT INTO migrations (version, created)
+VALUES (18, now());
+COMMIT;
@@ -0,0 +1,12 @@
+-- Run the entire migration as an atomic operation.
+START TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;
+
+-- Distinguish deployments stopped by a cancel request from deployments that were lost,
+-- so that clients reconnecting to a cancelled deployment do not send it again.
+ALTER TABLE deployment_status
+    ADD COLUMN "cancelled" boolean not null default false;
+
+-- Mark this database migration as completed.
+INSERT INTO migrations (version, created)
+VALUES (19, now());
+COMMIT;
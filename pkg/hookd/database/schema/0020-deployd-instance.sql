@@ -0,0 +1,23 @@
+-- Run the entire migration as an atomic operation.
+START TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;
+
+-- Record which deployd instance each unfinished deployment request was sent to,
+-- so that only that instance resumes it after a restart.
+ALTER TABLE deployment_request
+    ADD COLUMN "instance_id" varchar not null default '';
+
+-- Table deployd_instance holds the deployd instances connected to any hookd instance.
+-- The hookd instance holding the connection updates last_seen periodically. Unfinished deployment requests
+-- sent to an instance that has not been seen for a while are taken over by the next instance connecting for the cluster.
+CREATE TABLE deployd_instance
+(
+    "cluster"     varchar                  not null,
+    "instance_id" varchar                  not null,
+    "last_seen"   timestamp with time zone not null,
+    primary key (cluster, instance_id)
+);
+
+-- Mark this database migration as completed.
+INSERT INTO migrations (version, created)
+VALUES (20, now());
+COMMIT;
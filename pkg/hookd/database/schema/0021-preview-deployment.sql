@@ -0,0 +1,23 @@
+-- Run the entire migration as an atomic operation.
+START TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;
+
+-- Table preview_deployment records every deployment made to a preview environment, not just the latest one,
+-- so that teardown also deletes resources that a later deployment to the environment no longer contains.
+CREATE TABLE preview_deployment
+(
+    "team"          varchar                            not null,
+    "cluster"       varchar                            not null,
+    "repository"    varchar                            not null default '',
+    "name"          varchar                            not null,
+    "deployment_id" varchar references deployment (id) not null,
+    primary key (team, cluster, repository, name, deployment_id)
+);
+
+INSERT INTO preview_deployment (team, cluster, repository, name, deployment_id)
+SELECT team, cluster, repository, name, deployment_id
+FROM preview;
+
+-- Mark this database migration as completed.
+INSERT INTO migrations (version, created)
+VALUES (21, now());
+COMMIT;
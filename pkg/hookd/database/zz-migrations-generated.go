@@ -12,4 +12,16 @@ var migrations = []string{
 	"-- Run the entire migration as an atomic operation.\nSTART TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;\n\n-- Add cluster field to deployment table.\nALTER TABLE deployment\nADD COLUMN \"state\" VARCHAR NULL;\n\n-- Enable fast lookups on cluster and state\nCREATE INDEX deployment_state ON deployment (state);\nCREATE INDEX deployment_cluster ON deployment (cluster);\n\n-- Mark this database migration as completed.\nINSERT INTO migrations (version, created)\nVALUES (7, now());\nCOMMIT;\n",
 	"-- Run the entire migration as an atomic operation.\nSTART TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;\n\n-- Enable fast lookups on team\nCREATE INDEX deployment_team ON deployment (team);\n\n-- Mark this database migration as completed.\nINSERT INTO migrations (version, created)\nVALUES (8, now());\nCOMMIT;\n",
 	"-- Run the entire migration as an atomic operation.\nSTART TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;\n\n-- Remove no longer used Azure column / index\nDROP INDEX apikey_team_azure_id_index;\nALTER TABLE apikey DROP COLUMN \"team_azure_id\";\n\n-- Mark this database migration as completed.\nINSERT INTO migrations (version, created)\nVALUES (9, now());\nCOMMIT;\n",
+	"-- Run the entire migration as an atomic operation.\nSTART TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;\n\n-- Table deployment_queue holds deployment requests for clusters that are not currently connected.\n-- The request is stored as a serialized protobuf message, and sent to deployd when the cluster comes online.\nCREATE TABLE deployment_queue\n(\n    \"deployment_id\" varchar primary key references deployment (id) not null,\n    \"cluster\"       varchar                                        not null,\n    \"request\"       bytea                                          not null,\n    \"deadline\"      timestamp with time zone                       not null,\n    \"created\"       timestamp with time zone                       not null\n);\n\nCREATE INDEX deployment_queue_cluster ON deployment_queue (cluster);\n\n-- Mark this database migration as completed.\nINSERT INTO migrations (version, created)\nVALUES (10, now());\nCOMMIT;\n",
+	"-- Run the entire migration as an atomic operation.\nSTART TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;\n\n-- Table deployment_request holds deployment requests that have been sent to deployd, but have not yet finished.\n-- If deployd restarts in the middle of a deployment, the request is handed back so that the rollout can be resumed.\nCREATE TABLE deployment_request\n(\n    \"deployment_id\" varchar primary key references deployment (id) not null,\n    \"cluster\"       varchar                                        not null,\n    \"request\"       bytea                                          not null,\n    \"deadline\"      timestamp with time zone                       not null,\n    \"created\"       timestamp with time zone                       not null\n);\n\nCREATE INDEX deployment_request_cluster ON deployment_request (cluster);\n\n-- Mark this database migration as completed.\nINSERT INTO migrations (version, created)\nVALUES (11, now());\nCOMMIT;\n",
+	"-- Run the entire migration as an atomic operation.\nSTART TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;\n\n-- Table deployment_manifest holds the Kubernetes resources of a deployment, with secrets redacted,\n-- as a gzip compressed JSON array. Manifests are removed once they are older than the configured retention.\nCREATE TABLE deployment_manifest\n(\n    \"deployment_id\" varchar primary key references deployment (id) not null,\n    \"data\"          bytea                                          not null,\n    \"created\"       timestamp with time zone                       not null\n);\n\nCREATE INDEX deployment_manifest_created ON deployment_manifest (created);\n\n-- Mark this database migration as completed.\nINSERT INTO migrations (version, created)\nVALUES (12, now());\nCOMMIT;\n",
+	"-- Run the entire migration as an atomic operation.\nSTART TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;\n\n-- The original deployment request, including unredacted resources, so that the deployment can be made again.\n-- Encrypted with the database encryption key, as it may contain secrets.\nALTER TABLE deployment_manifest ADD COLUMN \"request\" bytea;\n\n-- Mark this database migration as completed.\nINSERT INTO migrations (version, created)\nVALUES (13, now());\nCOMMIT;\n",
+	"-- Run the entire migration as an atomic operation.\nSTART TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;\n\n-- Table preview tracks pull request preview environments, along with the latest deployment made to each of them.\n-- Previews are torn down by deleting the resources of that deployment, either on request or once they have expired.\nCREATE TABLE preview\n(\n    \"team\"          varchar                                    not null,\n    \"cluster\"       varchar                                    not null,\n    \"repository\"    varchar                                    not null default '',\n    \"name\"          varchar                                    not null,\n    \"deployment_id\" varchar references deployment (id)         not null,\n    \"expires\"       timestamp with time zone                   not null,\n    primary key (team, cluster, repository, name)\n);\n\nCREATE INDEX preview_expires ON preview (expires);\n\n-- Mark this database migration as completed.\nINSERT INTO migrations (version, created)\nVALUES (14, now());\nCOMMIT;\n",
+	"-- Run the entire migration as an atomic operation.\nSTART TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;\n\n-- Resources deleted by a deployment, such as resources pruned because they were removed from the deploy set.\nALTER TABLE deployment_resource ADD COLUMN \"deleted\" boolean not null default false;\n\n-- Mark this database migration as completed.\nINSERT INTO migrations (version, created)\nVALUES (15, now());\nCOMMIT;\n",
+	"-- Run the entire migration as an atomic operation.\nSTART TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;\n\n-- What a deployment does with its resources; either deploy, or undeploy to delete them.\nALTER TABLE deployment ADD COLUMN \"operation\" varchar not null default 'deploy';\n\n-- Mark this database migration as completed.\nINSERT INTO migrations (version, created)\nVALUES (16, now());\nCOMMIT;\n",
+	"-- Run the entire migration as an atomic operation.\nSTART TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;\n\n-- Table freeze holds deployment freezes, which block deployments to a cluster, by a team, or altogether.\n-- An empty team or cluster matches all teams or clusters. A freeze applies between start and end, where missing\n-- means unbounded. If it has a schedule, it only applies for duration after each time matching the schedule.\nCREATE TABLE freeze\n(\n    \"id\"       varchar                  primary key,\n    \"team\"     varchar                  not null default '',\n    \"cluster\"  varchar                  not null default '',\n    \"reason\"   varchar                  not null,\n    \"start\"    timestamp with time zone null,\n    \"end\"      timestamp with time zone null,\n    \"schedule\" varchar                  not null default '',\n    \"duration\" varchar                  not null default '',\n    \"timezone\" varchar                  not null default 'UTC',\n    \"strict\"   boolean                  not null default false,\n    \"created\"  timestamp with time zone not null\n);\n\n-- Mark this database migration as completed.\nINSERT INTO migrations (version, created)\nVALUES (17, now());\nCOMMIT;\n",
+	"-- Run the entire migration as an atomic operation.\nSTART TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;\n\n-- Table deployment_approval holds deployment requests to clusters where deployments must be approved.\n-- The request is dispatched to deployd once approved. Rejected requests, and requests that are not\n-- approved before their deadline, are never dispatched.\nCREATE TABLE deployment_approval\n(\n    \"deployment_id\" varchar primary key references deployment (id) not null,\n    \"team\"          varchar                                        not null,\n    \"cluster\"       varchar                                        not null,\n    \"approvers\"     varchar                                        not null,\n    \"requested_by\"  varchar                                        not null default '',\n    \"request\"       bytea                                          not null,\n    \"deadline\"      timestamp with time zone                       not null,\n    \"created\"       timestamp with time zone                       not null,\n    \"state\"         varchar                                        not null default 'pending',\n    \"decided_by\"    varchar                                        not null default '',\n    \"decided\"       timestamp with time zone                       null,\n    \"comment\"       varchar                                        not null default ''\n);\n\nCREATE INDEX deployment_approval_state ON deployment_approval (state, deadline);\n\n-- Mark this database migration as completed.\nINSERT INTO migrations (version, created)\nVALUES (18, now());\nCOMMIT;\n",
+	"-- Run the entire migration as an atomic operation.\nSTART TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;\n\n-- Distinguish deployments stopped by a cancel request from deployments that were lost,\n-- so that clients reconnecting to a cancelled deployment do not send it again.\nALTER TABLE deployment_status\n    ADD COLUMN \"cancelled\" boolean not null default false;\n\n-- Mark this database migration as completed.\nINSERT INTO migrations (version, created)\nVALUES (19, now());\nCOMMIT;\n",
+	"-- Run the entire migration as an atomic operation.\nSTART TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;\n\n-- Record which deployd instance each unfinished deployment request was sent to,\n-- so that only that instance resumes it after a restart.\nALTER TABLE deployment_request\n    ADD COLUMN \"instance_id\" varchar not null default '';\n\n-- Table deployd_instance holds the deployd instances connected to any hookd instance.\n-- The hookd instance holding the connection updates last_seen periodically. Unfinished deployment requests\n-- sent to an instance that has not been seen for a while are taken over by the next instance connecting for the cluster.\nCREATE TABLE deployd_instance\n(\n    \"cluster\"     varchar                  not null,\n    \"instance_id\" varchar                  not null,\n    \"last_seen\"   timestamp with time zone not null,\n    primary key (cluster, instance_id)\n);\n\n-- Mark this database migration as completed.\nINSERT INTO migrations (version, created)\nVALUES (20, now());\nCOMMIT;\n",
+	"-- Run the entire migration as an atomic operation.\nSTART TRANSACTION ISOLATION LEVEL SERIALIZABLE READ WRITE;\n\n-- Table preview_deployment records every deployment made to a preview environment, not just the latest one,\n-- so that teardown also deletes resources that a later deployment to the environment no longer contains.\nCREATE TABLE preview_deployment\n(\n    \"team\"          varchar                            not null,\n    \"cluster\"       varchar                            not null,\n    \"repository\"    varchar                            not null default '',\n    \"name\"          varchar                            not null,\n    \"deployment_id\" varchar references deployment (id) not null,\n    primary key (team, cluster, repository, name, deployment_id)\n);\n\nINSERT INTO preview_deployment (team, cluster, repository, name, deployment_id)\nSELECT team, cluster, repository, name, deployment_id\nFROM preview;\n\n-- Mark this database migration as completed.\nINSERT INTO migrations (version, created)\nVALUES (21, now());\nCOMMIT;\n",
 }
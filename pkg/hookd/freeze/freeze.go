@@ -0,0 +1,160 @@
+package freeze
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	// Time zones of scheduled freezes must be available even if the system has no time zone database.
+	_ "time/tzdata"
+
+	"github.com/nais/deploy/pkg/hookd/database"
+	"github.com/nais/deploy/pkg/pb"
+	log "github.com/sirupsen/logrus"
+)
+
+// MaxScheduledDuration limits how long a scheduled freeze lasts each time it starts.
+// Longer freezes are made with a start and end time.
+const MaxScheduledDuration = 7 * 24 * time.Hour
+
+// Validate checks that a freeze can be evaluated.
+func Validate(freeze database.Freeze) error {
+	if len(strings.TrimSpace(freeze.Reason)) == 0 {
+		return fmt.Errorf("reason is required")
+	}
+
+	if freeze.Start != nil && freeze.End != nil && !freeze.End.After(*freeze.Start) {
+		return fmt.Errorf("end must be after start")
+	}
+
+	if _, err := time.LoadLocation(freeze.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+
+	if len(freeze.Schedule) == 0 {
+		if len(freeze.Duration) > 0 {
+			return fmt.Errorf("duration is only used with a schedule")
+		}
+		return nil
+	}
+
+	_, err := ParseSchedule(freeze.Schedule)
+	if err != nil {
+		return err
+	}
+
+	duration, err := time.ParseDuration(freeze.Duration)
+	if err != nil {
+		return fmt.Errorf("scheduled freezes need a valid duration: %w", err)
+	}
+	if duration < time.Minute || duration > MaxScheduledDuration {
+		return fmt.Errorf("duration must be between 1m and %s", MaxScheduledDuration)
+	}
+
+	return nil
+}
+
+// Active returns true if the freeze applies at the given time, along with when it stops applying.
+// The end time is nil if the freeze lasts until it is removed.
+func Active(freeze database.Freeze, now time.Time) (bool, *time.Time, error) {
+	if freeze.Start != nil && now.Before(*freeze.Start) {
+		return false, nil, nil
+	}
+	if freeze.End != nil && !now.Before(*freeze.End) {
+		return false, nil, nil
+	}
+	if len(freeze.Schedule) == 0 {
+		return true, freeze.End, nil
+	}
+
+	schedule, err := ParseSchedule(freeze.Schedule)
+	if err != nil {
+		return false, nil, err
+	}
+	duration, err := time.ParseDuration(freeze.Duration)
+	if err != nil {
+		return false, nil, err
+	}
+	location, err := time.LoadLocation(freeze.Timezone)
+	if err != nil {
+		return false, nil, err
+	}
+
+	// Look for the most recent time the schedule started the freeze, that is still within its duration.
+	minute := now.In(location).Truncate(time.Minute)
+	for started := minute; now.Before(started.Add(duration)); started = started.Add(-time.Minute) {
+		if !schedule.Matches(started) {
+			continue
+		}
+		until := started.Add(duration)
+		if freeze.End != nil && freeze.End.Before(until) {
+			until = *freeze.End
+		}
+		return true, &until, nil
+	}
+
+	return false, nil, nil
+}
+
+// Describe explains why deployments are blocked by an active freeze.
+func Describe(freeze database.Freeze, until *time.Time) string {
+	text := &strings.Builder{}
+	text.WriteString("deployments")
+	if len(freeze.Team) > 0 {
+		fmt.Fprintf(text, " by team '%s'", freeze.Team)
+	}
+	if len(freeze.Cluster) > 0 {
+		fmt.Fprintf(text, " to cluster '%s'", freeze.Cluster)
+	}
+	text.WriteString(" are frozen")
+	if until != nil {
+		fmt.Fprintf(text, " until %s", until.UTC().Format(time.RFC3339))
+	} else {
+		text.WriteString(" until further notice")
+	}
+	fmt.Fprintf(text, ": %s (freeze %s)", freeze.Reason, freeze.ID)
+	return text.String()
+}
+
+// FrozenError is returned by Check when a freeze stops a deployment.
+type FrozenError struct {
+	message string
+}
+
+func (e *FrozenError) Error() string {
+	return e.message
+}
+
+// Check returns a FrozenError if a freeze applies to the team and cluster of a deployment request at the given time,
+// unless the request overrides it with a reason. Overrides are logged, and are not possible for strict freezes.
+// Other errors come from the store.
+func Check(ctx context.Context, store database.FreezeStore, request *pb.DeploymentRequest, now time.Time, logger *log.Entry) error {
+	freezes, err := store.ApplicableFreezes(ctx, request.GetTeam(), request.GetCluster(), now)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range freezes {
+		active, until, err := Active(*f, now)
+		if err != nil {
+			// Freezes are validated when created, so this should not happen. Don't let a broken freeze block everyone.
+			logger.Errorf("Evaluate deployment freeze %s: %s", f.ID, err)
+			continue
+		}
+		if !active {
+			continue
+		}
+
+		message := Describe(*f, until)
+		if len(request.GetFreezeOverride()) == 0 {
+			return &FrozenError{message: message + "; deploy with a freeze override reason to deploy anyway"}
+		}
+		if f.Strict {
+			return &FrozenError{message: message + "; this freeze can't be overridden"}
+		}
+
+		logger.WithField("freeze_id", f.ID).Warnf("Overriding deployment freeze: %s; reason given: %s", f.Reason, request.GetFreezeOverride())
+	}
+
+	return nil
+}
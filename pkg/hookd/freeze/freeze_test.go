@@ -0,0 +1,103 @@
+package freeze_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nais/deploy/pkg/hookd/database"
+	"github.com/nais/deploy/pkg/hookd/freeze"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActive(t *testing.T) {
+	start := time.Date(2026, time.December, 20, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2027, time.January, 3, 0, 0, 0, 0, time.UTC)
+
+	t.Run("ad-hoc freeze applies between start and end", func(t *testing.T) {
+		f := database.Freeze{Start: &start, End: &end}
+
+		active, _, err := freeze.Active(f, start.Add(-time.Second))
+		assert.NoError(t, err)
+		assert.False(t, active)
+
+		active, until, err := freeze.Active(f, start)
+		assert.NoError(t, err)
+		assert.True(t, active)
+		assert.Equal(t, &end, until)
+
+		active, _, err = freeze.Active(f, end)
+		assert.NoError(t, err)
+		assert.False(t, active)
+	})
+
+	t.Run("freeze without end applies until removed", func(t *testing.T) {
+		active, until, err := freeze.Active(database.Freeze{}, start)
+		assert.NoError(t, err)
+		assert.True(t, active)
+		assert.Nil(t, until)
+	})
+
+	t.Run("scheduled freeze applies for its duration in its time zone", func(t *testing.T) {
+		// Weekends, from Friday 16:00 until Monday 08:00 in Oslo, which is UTC+2 in October.
+		f := database.Freeze{Schedule: "0 16 * * 5", Duration: "64h", Timezone: "Europe/Oslo"}
+
+		for _, testCase := range []struct {
+			now    time.Time
+			active bool
+		}{
+			{time.Date(2026, time.October, 16, 13, 59, 0, 0, time.UTC), false},
+			{time.Date(2026, time.October, 16, 14, 0, 0, 0, time.UTC), true},
+			{time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC), true},
+			{time.Date(2026, time.October, 19, 5, 59, 0, 0, time.UTC), true},
+			{time.Date(2026, time.October, 19, 6, 0, 0, 0, time.UTC), false},
+		} {
+			active, until, err := freeze.Active(f, testCase.now)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.active, active, testCase.now.String())
+			if active {
+				assert.True(t, until.Equal(time.Date(2026, time.October, 19, 6, 0, 0, 0, time.UTC)), until.String())
+			}
+		}
+	})
+
+	t.Run("scheduled freeze is cut short by its end", func(t *testing.T) {
+		f := database.Freeze{Schedule: "0 0 * * *", Duration: "24h", End: &end}
+
+		active, until, err := freeze.Active(f, end.Add(-time.Hour))
+		assert.NoError(t, err)
+		assert.True(t, active)
+		assert.Equal(t, end, *until)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	start := time.Now()
+	before := start.Add(-time.Hour)
+
+	assert.NoError(t, freeze.Validate(database.Freeze{Reason: "incident"}))
+	assert.NoError(t, freeze.Validate(database.Freeze{Reason: "weekend", Schedule: "0 16 * * 5", Duration: "64h", Timezone: "Europe/Oslo"}))
+
+	for _, f := range []database.Freeze{
+		{},
+		{Reason: "incident", Start: &start, End: &before},
+		{Reason: "incident", Timezone: "Mars/Olympus_Mons"},
+		{Reason: "incident", Duration: "1h"},
+		{Reason: "weekend", Schedule: "0 16 * * 5"},
+		{Reason: "weekend", Schedule: "0 16 * * 5", Duration: "30d"},
+		{Reason: "weekend", Schedule: "0 16 * * 5", Duration: "200h"},
+		{Reason: "weekend", Schedule: "0 16 * *", Duration: "1h"},
+	} {
+		assert.Error(t, freeze.Validate(f), "%+v", f)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	until := time.Date(2027, time.January, 3, 0, 0, 0, 0, time.UTC)
+	f := database.Freeze{ID: "1", Cluster: "prod-gcp", Reason: "Christmas"}
+
+	assert.Equal(t, "deployments to cluster 'prod-gcp' are frozen until 2027-01-03T00:00:00Z: Christmas (freeze 1)", freeze.Describe(f, &until))
+
+	f.Team = "aura"
+	f.Cluster = ""
+	assert.Equal(t, "deployments by team 'aura' are frozen until further notice: Christmas (freeze 1)", freeze.Describe(f, nil))
+}
@@ -0,0 +1,137 @@
+package freeze
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a cron expression with five fields: minute, hour, day of month, month and day of week.
+// Each field is *, a number, a range such as 1-5, or a comma separated list of these, optionally followed by a step such as */15.
+// Day of week is 0-7, where both 0 and 7 are Sunday. As with cron, if both day of month and day of week are restricted,
+// a time matches if either of them does.
+type Schedule struct {
+	minute     uint64
+	hour       uint64
+	dayOfMonth uint64
+	month      uint64
+	dayOfWeek  uint64
+	// Set if the field is not *.
+	dayOfMonthRestricted bool
+	dayOfWeekRestricted  bool
+}
+
+type fieldBounds struct {
+	name     string
+	min, max int
+}
+
+var scheduleFields = []fieldBounds{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+func ParseSchedule(expr string) (*Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(scheduleFields) {
+		return nil, fmt.Errorf("schedule %q must have %d fields", expr, len(scheduleFields))
+	}
+
+	bits := make([]uint64, len(fields))
+	for i := range fields {
+		var err error
+		bits[i], err = parseField(fields[i], scheduleFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %s: %w", expr, scheduleFields[i].name, err)
+		}
+	}
+
+	// Sunday is both 0 and 7.
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	return &Schedule{
+		minute:               bits[0],
+		hour:                 bits[1],
+		dayOfMonth:           bits[2],
+		month:                bits[3],
+		dayOfWeek:            bits[4],
+		dayOfMonthRestricted: fields[2] != "*",
+		dayOfWeekRestricted:  fields[4] != "*",
+	}, nil
+}
+
+// Matches returns true if the minute of the given time matches the schedule.
+func (s *Schedule) Matches(t time.Time) bool {
+	if !has(s.minute, t.Minute()) || !has(s.hour, t.Hour()) || !has(s.month, int(t.Month())) {
+		return false
+	}
+
+	dayOfMonth := has(s.dayOfMonth, t.Day())
+	dayOfWeek := has(s.dayOfWeek, int(t.Weekday()))
+	if s.dayOfMonthRestricted && s.dayOfWeekRestricted {
+		return dayOfMonth || dayOfWeek
+	}
+	return dayOfMonth && dayOfWeek
+}
+
+func has(bits uint64, n int) bool {
+	return bits&(1<<uint(n)) != 0
+}
+
+func parseField(field string, bounds fieldBounds) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepExpr)
+			if err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepExpr)
+			}
+		}
+
+		low, high := bounds.min, bounds.max
+		if rangeExpr != "*" {
+			lowExpr, highExpr, isRange := strings.Cut(rangeExpr, "-")
+			var err error
+			low, err = parseNumber(lowExpr, bounds)
+			if err != nil {
+				return 0, err
+			}
+			high = low
+			if isRange {
+				high, err = parseNumber(highExpr, bounds)
+				if err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				high = bounds.max
+			}
+			if high < low {
+				return 0, fmt.Errorf("invalid range %q", rangeExpr)
+			}
+		}
+
+		for n := low; n <= high; n += step {
+			bits |= 1 << uint(n)
+		}
+	}
+
+	return bits, nil
+}
+
+func parseNumber(s string, bounds fieldBounds) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < bounds.min || n > bounds.max {
+		return 0, fmt.Errorf("%q is not a number between %d and %d", s, bounds.min, bounds.max)
+	}
+	return n, nil
+}
@@ -0,0 +1,55 @@
+package freeze_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nais/deploy/pkg/hookd/freeze"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedule(t *testing.T) {
+	// Friday 16:30 UTC.
+	friday := time.Date(2026, time.October, 16, 16, 30, 0, 0, time.UTC)
+
+	for _, testCase := range []struct {
+		schedule string
+		time     time.Time
+		matches  bool
+	}{
+		{"* * * * *", friday, true},
+		{"30 16 * * 5", friday, true},
+		{"30 16 * * 1-4", friday, false},
+		{"*/15 16 * * *", friday, true},
+		{"*/20 16 * * *", friday, false},
+		{"0,30 8-17 * * *", friday, true},
+		{"30 16 16 10 *", friday, true},
+		{"30 16 24-26 12 *", friday, false},
+		// Either day of month or day of week must match when both are restricted.
+		{"30 16 1 * 5", friday, true},
+		// Sunday is both 0 and 7.
+		{"0 12 * * 7", time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC), true},
+	} {
+		schedule, err := freeze.ParseSchedule(testCase.schedule)
+		assert.NoError(t, err, testCase.schedule)
+		assert.Equal(t, testCase.matches, schedule.Matches(testCase.time), testCase.schedule)
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, schedule := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+	} {
+		_, err := freeze.ParseSchedule(schedule)
+		assert.Error(t, err, schedule)
+	}
+}
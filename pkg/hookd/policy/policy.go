@@ -0,0 +1,204 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/nais/deploy/pkg/k8sutils"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Spec is the configuration of a single policy, as read from the policy directory.
+type Spec struct {
+	// Unique name of the policy, shown along with every violation.
+	Name string `json:"name"`
+	// Which rule the policy uses; one of the registered rule types.
+	Type string `json:"type"`
+	// Only check resources of these kinds, given as Kind or group/Kind. All resources are checked if empty.
+	Kinds []string `json:"kinds"`
+	// Teams that are not subject to this policy.
+	ExemptTeams []string `json:"exemptTeams"`
+	// Replaces the messages of the rule, if set.
+	Message string `json:"message"`
+	// Report violations as warnings, without rejecting the request.
+	Warn bool `json:"warn"`
+	// Settings for the rule type.
+	Config json.RawMessage `json:"config"`
+}
+
+// Check returns a message for every way the resource violates a rule, or nothing if it does not.
+type Check func(team string, resource unstructured.Unstructured) []string
+
+// Factory makes a check from the settings of a policy. The settings may be empty.
+type Factory func(config json.RawMessage) (Check, error)
+
+var factories = map[string]Factory{}
+
+// Register makes a rule type available to policies. Built-in rule types are registered by this package.
+func Register(ruleType string, factory Factory) {
+	factories[ruleType] = factory
+}
+
+type Policy struct {
+	Spec
+	check Check
+}
+
+func New(spec Spec) (*Policy, error) {
+	if len(spec.Name) == 0 {
+		return nil, fmt.Errorf("policy name is required")
+	}
+
+	factory, ok := factories[spec.Type]
+	if !ok {
+		return nil, fmt.Errorf("policy %s: unknown type %q", spec.Name, spec.Type)
+	}
+
+	check, err := factory(spec.Config)
+	if err != nil {
+		return nil, fmt.Errorf("policy %s: %w", spec.Name, err)
+	}
+
+	return &Policy{Spec: spec, check: check}, nil
+}
+
+// Load reads every policy in a directory. Each .yaml, .yml or .json file holds a single policy.
+func Load(directory string) ([]*Policy, error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]*Policy, 0, len(entries))
+	names := make(map[string]string)
+	for _, entry := range entries {
+		switch filepath.Ext(entry.Name()) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		if entry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(directory, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		spec := Spec{}
+		err = yaml.Unmarshal(data, &spec)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		policy, err := New(spec)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if other, found := names[policy.Name]; found {
+			return nil, fmt.Errorf("%s: policy %s is already defined in %s", path, policy.Name, other)
+		}
+		names[policy.Name] = path
+
+		policies = append(policies, policy)
+	}
+
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].Name < policies[j].Name
+	})
+
+	return policies, nil
+}
+
+// Applies returns true if the resource deployed by the team is subject to this policy.
+func (p *Policy) Applies(team string, resource unstructured.Unstructured) bool {
+	if slices.Contains(p.ExemptTeams, team) {
+		return false
+	}
+	if len(p.Kinds) == 0 {
+		return true
+	}
+
+	gvk := resource.GroupVersionKind()
+	for _, kind := range p.Kinds {
+		group, name, qualified := strings.Cut(kind, "/")
+		if !qualified {
+			name = group
+		}
+		if name == gvk.Kind && (!qualified || group == gvk.Group) {
+			return true
+		}
+	}
+	return false
+}
+
+// Violation is a resource that breaks a policy.
+type Violation struct {
+	Policy string
+	// Index of the resource in the deployment request.
+	Index    int
+	Resource k8sutils.Identifier
+	Message  string
+	// Warnings don't stop the deployment.
+	Warning bool
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s/%s: %s (policy %s)", v.Resource.Kind, v.Resource.Name, v.Message, v.Policy)
+}
+
+// Engine checks deployment requests against a set of policies.
+// A nil engine has no policies.
+type Engine struct {
+	policies []*Policy
+	warnOnly bool
+}
+
+// NewEngine returns an engine for the given policies. In warn-only mode, every violation is a warning.
+func NewEngine(policies []*Policy, warnOnly bool) *Engine {
+	return &Engine{
+		policies: policies,
+		warnOnly: warnOnly,
+	}
+}
+
+// Evaluate checks every resource deployed by the team against every policy, and returns all violations.
+func (e *Engine) Evaluate(team string, resources []unstructured.Unstructured) []Violation {
+	if e == nil {
+		return nil
+	}
+
+	violations := make([]Violation, 0)
+	for i, resource := range resources {
+		for _, policy := range e.policies {
+			if !policy.Applies(team, resource) {
+				continue
+			}
+
+			messages := policy.check(team, resource)
+			if len(messages) > 0 && len(policy.Message) > 0 {
+				messages = []string{policy.Message}
+			}
+
+			for _, message := range messages {
+				violations = append(violations, Violation{
+					Policy:   policy.Name,
+					Index:    i,
+					Resource: k8sutils.ResourceIdentifier(resource),
+					Message:  message,
+					Warning:  policy.Warn || e.warnOnly,
+				})
+			}
+		}
+	}
+
+	return violations
+}
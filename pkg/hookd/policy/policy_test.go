@@ -0,0 +1,162 @@
+package policy_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nais/deploy/pkg/hookd/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func resource(t *testing.T, data string) unstructured.Unstructured {
+	resource := unstructured.Unstructured{}
+	err := resource.UnmarshalJSON([]byte(data))
+	require.NoError(t, err)
+	return resource
+}
+
+func load(t *testing.T, files map[string]string) ([]*policy.Policy, error) {
+	directory := t.TempDir()
+	for name, data := range files {
+		err := os.WriteFile(filepath.Join(directory, name), []byte(data), 0644)
+		require.NoError(t, err)
+	}
+	return policy.Load(directory)
+}
+
+const application = `{"apiVersion":"nais.io/v1alpha1","kind":"Application","metadata":{"name":"myapp","namespace":"aura"},"spec":{"image":"ghcr.io/navikt/myapp:latest"}}`
+
+const deployment = `{
+  "apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "worker", "namespace": "other"},
+  "spec": {"template": {"spec": {
+    "initContainers": [{"name": "migrate", "image": "ghcr.io/navikt/worker@sha256:abc", "resources": {"limits": {"memory": "128Mi"}}}],
+    "containers": [{"name": "worker", "image": "ghcr.io/navikt/worker"}]
+  }}}
+}`
+
+const clusterRole = `{"apiVersion":"rbac.authorization.k8s.io/v1","kind":"ClusterRole","metadata":{"name":"admin"}}`
+
+func TestEvaluate(t *testing.T) {
+	policies, err := load(t, map[string]string{
+		"kinds.yaml": `
+name: no-cluster-roles
+type: denyKinds
+kinds: [rbac.authorization.k8s.io/ClusterRole, ClusterRoleBinding]
+`,
+		"images.yaml": `
+name: pinned-images
+type: imageTag
+config:
+  disallowed: [latest]
+  requireTag: true
+`,
+		"limits.yaml": `
+name: memory-limits
+type: resourceLimits
+warn: true
+config:
+  require: [memory]
+`,
+		"namespace.yml": `
+name: team-namespace
+type: teamNamespace
+exemptTeams: [nais]
+`,
+		"owner.json": `{"name": "team-label", "type": "field", "kinds": ["Application"], "config": {"path": "metadata.labels.team", "required": true}}`,
+		"README.md":  "not a policy",
+	})
+	require.NoError(t, err)
+	require.Len(t, policies, 5)
+
+	resources := []unstructured.Unstructured{
+		resource(t, application),
+		resource(t, deployment),
+		resource(t, clusterRole),
+	}
+
+	violations := policy.NewEngine(policies, false).Evaluate("aura", resources)
+	messages := make([]string, len(violations))
+	for i, violation := range violations {
+		messages[i] = violation.String()
+	}
+
+	assert.Equal(t, []string{
+		`Application/myapp: Application myapp has no memory limit (policy memory-limits)`,
+		`Application/myapp: image "ghcr.io/navikt/myapp:latest" uses disallowed tag "latest" (policy pinned-images)`,
+		`Application/myapp: metadata.labels.team is required (policy team-label)`,
+		`Deployment/worker: container "worker" has no memory limit (policy memory-limits)`,
+		`Deployment/worker: image "ghcr.io/navikt/worker" has no tag (policy pinned-images)`,
+		`Deployment/worker: namespace "other" does not belong to team "aura" (policy team-namespace)`,
+		`ClusterRole/admin: resources of kind ClusterRole are not allowed (policy no-cluster-roles)`,
+	}, messages)
+
+	assert.Equal(t, 1, violations[3].Index)
+	assert.True(t, violations[3].Warning, "policy in warn mode")
+	assert.False(t, violations[4].Warning)
+
+	t.Run("exempt team", func(t *testing.T) {
+		violations := policy.NewEngine(policies, false).Evaluate("nais", resources[1:2])
+		for _, violation := range violations {
+			assert.NotEqual(t, "team-namespace", violation.Policy)
+		}
+	})
+
+	t.Run("warn-only engine", func(t *testing.T) {
+		for _, violation := range policy.NewEngine(policies, true).Evaluate("aura", resources) {
+			assert.True(t, violation.Warning)
+		}
+	})
+
+	t.Run("nil engine", func(t *testing.T) {
+		var engine *policy.Engine
+		assert.Empty(t, engine.Evaluate("aura", resources))
+	})
+}
+
+func TestUntaggedImageUsesLatest(t *testing.T) {
+	p, err := policy.New(policy.Spec{Name: "no-latest", Type: policy.TypeImageTag, Config: []byte(`{"disallowed":["latest"]}`)})
+	require.NoError(t, err)
+
+	engine := policy.NewEngine([]*policy.Policy{p}, false)
+	violations := engine.Evaluate("aura", []unstructured.Unstructured{
+		resource(t, `{"apiVersion":"nais.io/v1alpha1","kind":"Application","metadata":{"name":"a"},"spec":{"image":"nginx"}}`),
+		resource(t, `{"apiVersion":"nais.io/v1alpha1","kind":"Application","metadata":{"name":"b"},"spec":{"image":"nginx:1.25"}}`),
+		resource(t, `{"apiVersion":"nais.io/v1alpha1","kind":"Application","metadata":{"name":"c"},"spec":{"image":"nginx@sha256:abc"}}`),
+	})
+	require.Len(t, violations, 1)
+	assert.Equal(t, `image "nginx" has no tag, and uses disallowed tag "latest"`, violations[0].Message)
+	assert.Equal(t, "a", violations[0].Resource.Name)
+}
+
+func TestCustomMessage(t *testing.T) {
+	p, err := policy.New(policy.Spec{Name: "replicas", Type: policy.TypeField, Message: "set spec.replicas.min to at least 2", Config: []byte(`{"path":"spec.replicas.min","pattern":"[2-9]|[1-9][0-9]+"}`)})
+	require.NoError(t, err)
+
+	engine := policy.NewEngine([]*policy.Policy{p}, false)
+	violations := engine.Evaluate("aura", []unstructured.Unstructured{
+		resource(t, `{"apiVersion":"nais.io/v1alpha1","kind":"Application","metadata":{"name":"a"},"spec":{"replicas":{"min":1}}}`),
+		resource(t, `{"apiVersion":"nais.io/v1alpha1","kind":"Application","metadata":{"name":"b"},"spec":{"replicas":{"min":2}}}`),
+		resource(t, `{"apiVersion":"nais.io/v1alpha1","kind":"Application","metadata":{"name":"c"}}`),
+	})
+	require.Len(t, violations, 1)
+	assert.Equal(t, "set spec.replicas.min to at least 2", violations[0].Message)
+	assert.Equal(t, "a", violations[0].Resource.Name)
+}
+
+func TestLoadErrors(t *testing.T) {
+	for name, files := range map[string]map[string]string{
+		"unknown type":    {"a.yaml": "name: a\ntype: rego\n"},
+		"missing name":    {"a.yaml": "type: denyKinds\n"},
+		"unknown config":  {"a.yaml": "name: a\ntype: imageTag\nconfig:\n  disallow: [latest]\n"},
+		"missing config":  {"a.yaml": "name: a\ntype: resourceLimits\n"},
+		"invalid pattern": {"a.yaml": "name: a\ntype: field\nconfig:\n  path: spec.image\n  pattern: '('\n"},
+		"duplicate name":  {"a.yaml": "name: a\ntype: denyKinds\n", "b.yaml": "name: a\ntype: teamNamespace\n"},
+		"invalid yaml":    {"a.yaml": "name: [a\n"},
+	} {
+		_, err := load(t, files)
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,246 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Built-in rule types.
+const (
+	// Reject every resource the policy applies to. Used with kinds, to disallow kinds such as ClusterRole.
+	TypeDenyKinds = "denyKinds"
+	// Check the tags of container images.
+	TypeImageTag = "imageTag"
+	// Require resource limits on containers.
+	TypeResourceLimits = "resourceLimits"
+	// Only allow resources in the namespace of the team making the deployment.
+	TypeTeamNamespace = "teamNamespace"
+	// Check a single field.
+	TypeField = "field"
+)
+
+func init() {
+	Register(TypeDenyKinds, denyKinds)
+	Register(TypeImageTag, imageTag)
+	Register(TypeResourceLimits, resourceLimits)
+	Register(TypeTeamNamespace, teamNamespace)
+	Register(TypeField, field)
+}
+
+func decodeConfig(config json.RawMessage, target any) error {
+	if len(config) == 0 {
+		return nil
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(config)))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(target)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	return nil
+}
+
+func denyKinds(config json.RawMessage) (Check, error) {
+	return func(team string, resource unstructured.Unstructured) []string {
+		return []string{fmt.Sprintf("resources of kind %s are not allowed", resource.GetKind())}
+	}, nil
+}
+
+type imageTagConfig struct {
+	// Tags that can't be used, such as latest. Images without a tag or digest use latest.
+	Disallowed []string `json:"disallowed"`
+	// Require every image to have a tag or digest.
+	RequireTag bool `json:"requireTag"`
+}
+
+func imageTag(config json.RawMessage) (Check, error) {
+	cfg := imageTagConfig{}
+	err := decodeConfig(config, &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(team string, resource unstructured.Unstructured) []string {
+		messages := make([]string, 0)
+		found := images(resource.Object)
+		slices.Sort(found)
+		for _, image := range found {
+			tag, digest := parseImage(image)
+			switch {
+			case len(digest) > 0:
+			case len(tag) == 0 && cfg.RequireTag:
+				messages = append(messages, fmt.Sprintf("image %q has no tag", image))
+			case len(tag) == 0 && slices.Contains(cfg.Disallowed, "latest"):
+				messages = append(messages, fmt.Sprintf("image %q has no tag, and uses disallowed tag %q", image, "latest"))
+			case slices.Contains(cfg.Disallowed, tag):
+				messages = append(messages, fmt.Sprintf("image %q uses disallowed tag %q", image, tag))
+			}
+		}
+		return messages
+	}, nil
+}
+
+// Find every image in a resource, i.e. string values of fields named image.
+func images(value any) []string {
+	found := make([]string, 0)
+	switch value := value.(type) {
+	case map[string]any:
+		for key, v := range value {
+			if image, ok := v.(string); ok && key == "image" {
+				found = append(found, image)
+				continue
+			}
+			found = append(found, images(v)...)
+		}
+	case []any:
+		for _, v := range value {
+			found = append(found, images(v)...)
+		}
+	}
+	return found
+}
+
+// Return the tag and digest of an image reference such as registry:5000/team/app:1.0@sha256:abc.
+func parseImage(image string) (tag, digest string) {
+	image, digest, _ = strings.Cut(image, "@")
+	name := image[strings.LastIndex(image, "/")+1:]
+	_, tag, _ = strings.Cut(name, ":")
+	return tag, digest
+}
+
+type resourceLimitsConfig struct {
+	// Resources that must have a limit, such as memory.
+	Require []string `json:"require"`
+}
+
+func resourceLimits(config json.RawMessage) (Check, error) {
+	cfg := resourceLimitsConfig{}
+	err := decodeConfig(config, &cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Require) == 0 {
+		return nil, fmt.Errorf("config.require must list at least one resource")
+	}
+
+	return func(team string, resource unstructured.Unstructured) []string {
+		messages := make([]string, 0)
+		for _, container := range containers(resource) {
+			limits, _, _ := unstructured.NestedMap(container.spec, "resources", "limits")
+			for _, name := range cfg.Require {
+				if _, ok := limits[name]; !ok {
+					messages = append(messages, fmt.Sprintf("%s has no %s limit", container.name, name))
+				}
+			}
+		}
+		return messages
+	}, nil
+}
+
+type container struct {
+	name string
+	spec map[string]any
+}
+
+// Find the containers of a resource. NAIS applications and jobs describe a single container in their spec;
+// other workloads have lists of containers and init containers somewhere in their spec.
+func containers(resource unstructured.Unstructured) []container {
+	if resource.GroupVersionKind().Group == "nais.io" {
+		spec, found, _ := unstructured.NestedMap(resource.Object, "spec")
+		if !found {
+			return nil
+		}
+		return []container{{name: fmt.Sprintf("%s %s", resource.GetKind(), resource.GetName()), spec: spec}}
+	}
+
+	found := findContainers(resource.Object["spec"])
+	slices.SortFunc(found, func(a, b container) int {
+		return strings.Compare(a.name, b.name)
+	})
+	return found
+}
+
+func findContainers(value any) []container {
+	found := make([]container, 0)
+	switch value := value.(type) {
+	case map[string]any:
+		for _, key := range []string{"containers", "initContainers"} {
+			list, _ := value[key].([]any)
+			for _, item := range list {
+				if spec, ok := item.(map[string]any); ok {
+					name, _ := spec["name"].(string)
+					found = append(found, container{name: fmt.Sprintf("container %q", name), spec: spec})
+				}
+			}
+		}
+		for key, v := range value {
+			if key != "containers" && key != "initContainers" {
+				found = append(found, findContainers(v)...)
+			}
+		}
+	case []any:
+		for _, v := range value {
+			found = append(found, findContainers(v)...)
+		}
+	}
+	return found
+}
+
+func teamNamespace(config json.RawMessage) (Check, error) {
+	return func(team string, resource unstructured.Unstructured) []string {
+		namespace := resource.GetNamespace()
+		if len(namespace) == 0 || namespace == team {
+			return nil
+		}
+		return []string{fmt.Sprintf("namespace %q does not belong to team %q", namespace, team)}
+	}, nil
+}
+
+type fieldConfig struct {
+	// Dot separated path to the field, such as spec.replicas.min.
+	Path string `json:"path"`
+	// The field must be set.
+	Required bool `json:"required"`
+	// If the field is set, its value must match this regular expression.
+	Pattern string `json:"pattern"`
+}
+
+func field(config json.RawMessage) (Check, error) {
+	cfg := fieldConfig{}
+	err := decodeConfig(config, &cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Path) == 0 {
+		return nil, fmt.Errorf("config.path is required")
+	}
+
+	var pattern *regexp.Regexp
+	if len(cfg.Pattern) > 0 {
+		pattern, err = regexp.Compile("^(?:" + cfg.Pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("config.pattern: %w", err)
+		}
+	}
+
+	path := strings.Split(cfg.Path, ".")
+
+	return func(team string, resource unstructured.Unstructured) []string {
+		value, found, _ := unstructured.NestedFieldNoCopy(resource.Object, path...)
+		if !found {
+			if cfg.Required {
+				return []string{fmt.Sprintf("%s is required", cfg.Path)}
+			}
+			return nil
+		}
+		if pattern != nil && !pattern.MatchString(fmt.Sprint(value)) {
+			return []string{fmt.Sprintf("%s must match %q", cfg.Path, cfg.Pattern)}
+		}
+		return nil
+	}, nil
+}
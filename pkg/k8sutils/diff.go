@@ -0,0 +1,195 @@
+package k8sutils
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/nais/deploy/pkg/pb"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var plainPathElement = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Resources are matched across versions of their API.
+type resourceKey struct {
+	schema.GroupKind
+	Namespace string
+	Name      string
+}
+
+func keyOf(resource unstructured.Unstructured) resourceKey {
+	return resourceKey{
+		GroupKind: resource.GroupVersionKind().GroupKind(),
+		Namespace: resource.GetNamespace(),
+		Name:      resource.GetName(),
+	}
+}
+
+// KubernetesResource returns the protobuf representation of this identifier.
+func (id Identifier) KubernetesResource() *pb.KubernetesResource {
+	return &pb.KubernetesResource{
+		Group:     id.Group,
+		Version:   id.Version,
+		Kind:      id.Kind,
+		Name:      id.Name,
+		Namespace: id.Namespace,
+	}
+}
+
+// Diff returns the resources that differ between two sets of resources, along with the fields that were changed.
+// Resources are listed in the order they appear in the new set, followed by removed resources.
+func Diff(from, to []unstructured.Unstructured) []*pb.ResourceDiff {
+	previous := make(map[resourceKey]unstructured.Unstructured, len(from))
+	for _, resource := range from {
+		previous[keyOf(resource)] = resource
+	}
+
+	diffs := make([]*pb.ResourceDiff, 0)
+	seen := make(map[resourceKey]bool, len(to))
+
+	for _, resource := range to {
+		key := keyOf(resource)
+		seen[key] = true
+		old, ok := previous[key]
+		if !ok {
+			diffs = append(diffs, &pb.ResourceDiff{
+				Resource: ResourceIdentifier(resource).KubernetesResource(),
+				Change:   pb.ResourceChange_added,
+			})
+			continue
+		}
+		fields := DiffFields(old, resource)
+		if len(fields) > 0 {
+			diffs = append(diffs, &pb.ResourceDiff{
+				Resource: ResourceIdentifier(resource).KubernetesResource(),
+				Change:   pb.ResourceChange_changed,
+				Fields:   fields,
+			})
+		}
+	}
+
+	for _, resource := range from {
+		if !seen[keyOf(resource)] {
+			diffs = append(diffs, &pb.ResourceDiff{
+				Resource: ResourceIdentifier(resource).KubernetesResource(),
+				Change:   pb.ResourceChange_removed,
+			})
+		}
+	}
+
+	return diffs
+}
+
+// DiffFields returns the fields that differ between two versions of a resource, ordered by path.
+func DiffFields(old, new unstructured.Unstructured) []*pb.FieldChange {
+	fields := make([]*pb.FieldChange, 0)
+	diffValues("", old.Object, new.Object, &fields)
+	return fields
+}
+
+// HasCommonResources returns true if any resource is present in both sets.
+func HasCommonResources(a, b []unstructured.Unstructured) bool {
+	keys := make(map[resourceKey]bool, len(a))
+	for _, resource := range a {
+		keys[keyOf(resource)] = true
+	}
+	for _, resource := range b {
+		if keys[keyOf(resource)] {
+			return true
+		}
+	}
+	return false
+}
+
+func diffValues(path string, old, new any, changes *[]*pb.FieldChange) {
+	switch o := old.(type) {
+	case map[string]any:
+		n, ok := new.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(o)+len(n))
+		for key := range o {
+			keys = append(keys, key)
+		}
+		for key := range n {
+			if _, ok := o[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			oldValue, inOld := o[key]
+			newValue, inNew := n[key]
+			childPath := fieldPath(path, key)
+			switch {
+			case !inOld:
+				*changes = append(*changes, fieldChange(childPath, nil, newValue))
+			case !inNew:
+				*changes = append(*changes, fieldChange(childPath, oldValue, nil))
+			default:
+				diffValues(childPath, oldValue, newValue, changes)
+			}
+		}
+		return
+
+	case []any:
+		n, ok := new.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < len(o) || i < len(n); i++ {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(o):
+				*changes = append(*changes, fieldChange(childPath, nil, n[i]))
+			case i >= len(n):
+				*changes = append(*changes, fieldChange(childPath, o[i], nil))
+			default:
+				diffValues(childPath, o[i], n[i], changes)
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(old, new) {
+		*changes = append(*changes, fieldChange(path, old, new))
+	}
+}
+
+func fieldPath(parent, key string) string {
+	if !plainPathElement.MatchString(key) {
+		return fmt.Sprintf("%s[%q]", parent, key)
+	}
+	if len(parent) == 0 {
+		return key
+	}
+	return parent + "." + key
+}
+
+// Nil values are treated as missing fields.
+func fieldChange(path string, old, new any) *pb.FieldChange {
+	return &pb.FieldChange{
+		Path: path,
+		Old:  yamlValue(old),
+		New:  yamlValue(new),
+	}
+}
+
+func yamlValue(value any) *string {
+	if value == nil {
+		return nil
+	}
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		encoded := fmt.Sprintf("%v", value)
+		return &encoded
+	}
+	encoded := strings.TrimSuffix(string(data), "\n")
+	return &encoded
+}
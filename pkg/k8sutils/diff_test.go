@@ -0,0 +1,73 @@
+package k8sutils_test
+
+import (
+	"testing"
+
+	"github.com/nais/deploy/pkg/k8sutils"
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func configMap(name string, data map[string]any) unstructured.Unstructured {
+	return unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]any{
+			"name":        name,
+			"namespace":   "aura",
+			"annotations": map[string]any{"deploy.nais.io/github-sha": "abc"},
+		},
+		"data": data,
+	}}
+}
+
+func TestDiff(t *testing.T) {
+	from := []unstructured.Unstructured{
+		configMap("changed", map[string]any{"a": "1", "b": "2", "list": []any{"x", "y"}}),
+		configMap("unchanged", map[string]any{"a": "1"}),
+		configMap("removed", map[string]any{}),
+	}
+	to := []unstructured.Unstructured{
+		configMap("added", map[string]any{}),
+		configMap("unchanged", map[string]any{"a": "1"}),
+		configMap("changed", map[string]any{"a": "1", "b": "3", "c": "4", "list": []any{"x"}}),
+	}
+	to[2].SetAnnotations(map[string]string{"deploy.nais.io/github-sha": "def"})
+
+	diffs := k8sutils.Diff(from, to)
+	assert.Len(t, diffs, 3)
+
+	assert.Equal(t, "added", diffs[0].GetResource().GetName())
+	assert.Equal(t, pb.ResourceChange_added, diffs[0].GetChange())
+	assert.Empty(t, diffs[0].GetFields())
+
+	assert.Equal(t, "changed", diffs[1].GetResource().GetName())
+	assert.Equal(t, pb.ResourceChange_changed, diffs[1].GetChange())
+	fields := diffs[1].GetFields()
+	assert.Len(t, fields, 4)
+
+	assert.Equal(t, "data.b", fields[0].GetPath())
+	assert.Equal(t, `"2"`, fields[0].GetOld())
+	assert.Equal(t, `"3"`, fields[0].GetNew())
+
+	assert.Equal(t, "data.c", fields[1].GetPath())
+	assert.Nil(t, fields[1].Old)
+	assert.Equal(t, `"4"`, fields[1].GetNew())
+
+	assert.Equal(t, "data.list[1]", fields[2].GetPath())
+	assert.Equal(t, `"y"`, fields[2].GetOld())
+	assert.Nil(t, fields[2].New)
+
+	assert.Equal(t, `metadata.annotations["deploy.nais.io/github-sha"]`, fields[3].GetPath())
+
+	assert.Equal(t, "removed", diffs[2].GetResource().GetName())
+	assert.Equal(t, pb.ResourceChange_removed, diffs[2].GetChange())
+}
+
+func TestHasCommonResources(t *testing.T) {
+	foo := configMap("foo", nil)
+	bar := configMap("bar", nil)
+	assert.True(t, k8sutils.HasCommonResources([]unstructured.Unstructured{foo, bar}, []unstructured.Unstructured{bar}))
+	assert.False(t, k8sutils.HasCommonResources([]unstructured.Unstructured{foo}, []unstructured.Unstructured{bar}))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/nais/deploy/pkg/pb"
+	"google.golang.org/protobuf/types/known/structpb"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
@@ -56,3 +57,29 @@ func Identifiers(resources []unstructured.Unstructured) []Identifier {
 	}
 	return identifiers
 }
+
+// Object returns a resource with nothing but the identity set, which is enough to find it in the cluster, or to delete it.
+func (id Identifier) Object() unstructured.Unstructured {
+	resource := unstructured.Unstructured{}
+	resource.SetGroupVersionKind(id.GroupVersionKind)
+	resource.SetName(id.Name)
+	if len(id.Namespace) > 0 {
+		resource.SetNamespace(id.Namespace)
+	}
+	return resource
+}
+
+// KubernetesFromIdentifiers returns a Kubernetes payload with a minimal object for each identifier.
+func KubernetesFromIdentifiers(identifiers []Identifier) (*pb.Kubernetes, error) {
+	kube := &pb.Kubernetes{
+		Resources: make([]*structpb.Struct, 0, len(identifiers)),
+	}
+	for _, id := range identifiers {
+		object, err := structpb.NewStruct(id.Object().Object)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		kube.Resources = append(kube.Resources, object)
+	}
+	return kube, nil
+}
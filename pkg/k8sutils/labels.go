@@ -0,0 +1,11 @@
+package k8sutils
+
+const (
+	// PreviewLabel marks a resource as part of a pull request preview environment. The value is the name of the environment.
+	PreviewLabel = "deploy.nais.io/preview"
+	// PreviewExpiresAnnotation tells when a preview environment will be torn down, in RFC 3339 format.
+	PreviewExpiresAnnotation = "deploy.nais.io/preview-expires"
+	// DeploySetLabel marks a resource as part of a deploy set, i.e. resources that are deployed together.
+	// Resources in a deploy set that are no longer deployed can be pruned.
+	DeploySetLabel = "deploy.nais.io/deploy-set"
+)
@@ -0,0 +1,95 @@
+package k8sutils
+
+import (
+	"strings"
+
+	"github.com/nais/deploy/pkg/pb"
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Annotations injected by the deploy client describe the pipeline run, and change every time.
+const injectedAnnotationPrefix = "deploy.nais.io/"
+
+// Fields maintained by the Kubernetes API server or by deployd, which change with every deployment.
+var serverManagedFields = [][]string{
+	{"status"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "generation"},
+	{"metadata", "managedFields"},
+	{"metadata", "resourceVersion"},
+	{"metadata", "selfLink"},
+	{"metadata", "uid"},
+	{"metadata", "annotations", "kubectl.kubernetes.io/last-applied-configuration"},
+	{"metadata", "annotations", nais_io_v1.DeploymentCorrelationIDAnnotation},
+}
+
+// WithoutServerManagedFields returns a copy of the resource without fields that are not part of what is deployed,
+// such as status, resource version and annotations describing the pipeline run.
+func WithoutServerManagedFields(resource unstructured.Unstructured) unstructured.Unstructured {
+	resource = *resource.DeepCopy()
+	for _, field := range serverManagedFields {
+		unstructured.RemoveNestedField(resource.Object, field...)
+	}
+
+	annotations := resource.GetAnnotations()
+	for key := range annotations {
+		if strings.HasPrefix(key, injectedAnnotationPrefix) {
+			delete(annotations, key)
+		}
+	}
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(resource.Object, "metadata", "annotations")
+	} else {
+		resource.SetAnnotations(annotations)
+	}
+
+	return resource
+}
+
+// DiffLive compares a resource with the object currently in the cluster, which is nil if it does not exist yet.
+// Server-managed fields are ignored, and secret values are redacted; changed secrets are shown as <redacted>.
+func DiffLive(live *unstructured.Unstructured, desired unstructured.Unstructured) *pb.ResourceDiff {
+	diff := &pb.ResourceDiff{
+		Resource: ResourceIdentifier(desired).KubernetesResource(),
+		Change:   pb.ResourceChange_unchanged,
+	}
+
+	if live == nil {
+		diff.Change = pb.ResourceChange_added
+		return diff
+	}
+
+	current := WithoutServerManagedFields(*live)
+	desired = WithoutServerManagedFields(desired)
+
+	fields := DiffFields(current, desired)
+	if len(fields) == 0 {
+		return diff
+	}
+
+	redacted := make(map[string]*pb.FieldChange)
+	for _, field := range DiffFields(Redact(current), Redact(desired)) {
+		redacted[field.GetPath()] = field
+	}
+
+	redactedValue := Redacted
+	for i, field := range fields {
+		if field, ok := redacted[field.GetPath()]; ok {
+			fields[i] = field
+			continue
+		}
+		// The values only differ in what was redacted.
+		if field.Old != nil {
+			field.Old = &redactedValue
+		}
+		if field.New != nil {
+			field.New = &redactedValue
+		}
+	}
+
+	diff.Change = pb.ResourceChange_changed
+	diff.Fields = fields
+
+	return diff
+}
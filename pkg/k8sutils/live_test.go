@@ -0,0 +1,65 @@
+package k8sutils_test
+
+import (
+	"testing"
+
+	"github.com/nais/deploy/pkg/k8sutils"
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDiffLive(t *testing.T) {
+	secret := func(password string, metadata map[string]any) unstructured.Unstructured {
+		metadata["name"] = "credentials"
+		metadata["namespace"] = "aura"
+		return unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   metadata,
+			"stringData": map[string]any{"password": password, "username": "aura"},
+		}}
+	}
+
+	t.Run("missing resources are added", func(t *testing.T) {
+		diff := k8sutils.DiffLive(nil, secret("hunter2", map[string]any{}))
+		assert.Equal(t, pb.ResourceChange_added, diff.GetChange())
+		assert.Equal(t, "credentials", diff.GetResource().GetName())
+		assert.Empty(t, diff.GetFields())
+	})
+
+	t.Run("server-managed fields are ignored", func(t *testing.T) {
+		live := secret("hunter2", map[string]any{
+			"uid":             "1234",
+			"resourceVersion": "42",
+			"managedFields":   []any{map[string]any{"manager": "nais-deploy"}},
+			"annotations": map[string]any{
+				"deploy.nais.io/github-sha":       "abc",
+				"nais.io/deploymentCorrelationID": "1",
+			},
+		})
+		live.Object["status"] = map[string]any{"phase": "Active"}
+		desired := secret("hunter2", map[string]any{
+			"annotations": map[string]any{"deploy.nais.io/github-sha": "def"},
+		})
+
+		diff := k8sutils.DiffLive(&live, desired)
+		assert.Equal(t, pb.ResourceChange_unchanged, diff.GetChange())
+		assert.Empty(t, diff.GetFields())
+	})
+
+	t.Run("changed secret values are redacted", func(t *testing.T) {
+		live := secret("hunter2", map[string]any{"labels": map[string]any{"team": "aura"}})
+		desired := secret("hunter3", map[string]any{"labels": map[string]any{"team": "nais"}})
+
+		diff := k8sutils.DiffLive(&live, desired)
+		assert.Equal(t, pb.ResourceChange_changed, diff.GetChange())
+		assert.Len(t, diff.GetFields(), 2)
+		assert.Equal(t, "metadata.labels.team", diff.GetFields()[0].GetPath())
+		assert.Equal(t, "aura", diff.GetFields()[0].GetOld())
+		assert.Equal(t, "nais", diff.GetFields()[0].GetNew())
+		assert.Equal(t, "stringData.password", diff.GetFields()[1].GetPath())
+		assert.Equal(t, k8sutils.Redacted, diff.GetFields()[1].GetOld())
+		assert.Equal(t, k8sutils.Redacted, diff.GetFields()[1].GetNew())
+	})
+}
@@ -0,0 +1,67 @@
+package k8sutils
+
+import (
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Redacted replaces values that may contain secrets.
+const Redacted = "<redacted>"
+
+var (
+	secretFieldPattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|api[-_]?key|credential|private[-_]?key)`)
+	// Fields that refer to secrets, rather than containing them, such as secretName or tokenPath.
+	secretReferencePattern = regexp.MustCompile(`(?i)(name|ref|path|file)$`)
+)
+
+// Redact returns a copy of the resource where secret-looking values are replaced.
+// This covers the data of Secret resources, fields named like secrets, and name/value pairs
+// such as environment variables where the name looks like a secret.
+func Redact(resource unstructured.Unstructured) unstructured.Unstructured {
+	redacted := resource.DeepCopy()
+
+	gvk := redacted.GroupVersionKind()
+	if gvk.Group == "" && gvk.Kind == "Secret" {
+		for _, field := range []string{"data", "stringData"} {
+			values, found, _ := unstructured.NestedMap(redacted.Object, field)
+			if !found {
+				continue
+			}
+			for key := range values {
+				values[key] = Redacted
+			}
+			_ = unstructured.SetNestedMap(redacted.Object, values, field)
+		}
+	}
+
+	redactValue(redacted.Object)
+
+	return *redacted
+}
+
+func secretField(key string) bool {
+	return secretFieldPattern.MatchString(key) && !secretReferencePattern.MatchString(key)
+}
+
+func redactValue(value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		if name, ok := v["name"].(string); ok && secretField(name) {
+			if _, ok := v["value"].(string); ok {
+				v["value"] = Redacted
+			}
+		}
+		for key, child := range v {
+			if s, ok := child.(string); ok && len(s) > 0 && secretField(key) {
+				v[key] = Redacted
+				continue
+			}
+			redactValue(child)
+		}
+	case []any:
+		for _, child := range v {
+			redactValue(child)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package k8sutils_test
+
+import (
+	"testing"
+
+	"github.com/nais/deploy/pkg/k8sutils"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRedact(t *testing.T) {
+	t.Run("secret data is redacted", func(t *testing.T) {
+		secret := unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]any{"name": "foo"},
+			"data":       map[string]any{"username": "Zm9v"},
+			"stringData": map[string]any{"host": "localhost"},
+		}}
+
+		redacted := k8sutils.Redact(secret)
+		assert.Equal(t, k8sutils.Redacted, redacted.Object["data"].(map[string]any)["username"])
+		assert.Equal(t, k8sutils.Redacted, redacted.Object["stringData"].(map[string]any)["host"])
+		assert.Equal(t, "Zm9v", secret.Object["data"].(map[string]any)["username"], "original is not modified")
+	})
+
+	t.Run("secret-looking fields and environment variables are redacted", func(t *testing.T) {
+		app := unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "nais.io/v1alpha1",
+			"kind":       "Application",
+			"metadata":   map[string]any{"name": "foo"},
+			"spec": map[string]any{
+				"image":    "ghcr.io/nais/foo:1",
+				"apiKey":   "hunter2",
+				"password": "",
+				"env": []any{
+					map[string]any{"name": "DB_PASSWORD", "value": "hunter2"},
+					map[string]any{"name": "LOG_LEVEL", "value": "debug"},
+				},
+				"volumes": []any{
+					map[string]any{"secretName": "foo-secret"},
+				},
+			},
+		}}
+
+		spec := k8sutils.Redact(app).Object["spec"].(map[string]any)
+		assert.Equal(t, "ghcr.io/nais/foo:1", spec["image"])
+		assert.Equal(t, k8sutils.Redacted, spec["apiKey"])
+		assert.Equal(t, "", spec["password"], "empty values reveal nothing")
+		assert.Equal(t, k8sutils.Redacted, spec["env"].([]any)[0].(map[string]any)["value"])
+		assert.Equal(t, "debug", spec["env"].([]any)[1].(map[string]any)["value"])
+		assert.Equal(t, "foo-secret", spec["volumes"].([]any)[0].(map[string]any)["secretName"], "references to secrets are kept")
+	})
+}
@@ -21,6 +21,7 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	Dispatch_Deployments_FullMethodName  = "/pb.Dispatch/Deployments"
 	Dispatch_ReportStatus_FullMethodName = "/pb.Dispatch/ReportStatus"
+	Dispatch_ReportPlan_FullMethodName   = "/pb.Dispatch/ReportPlan"
 )
 
 // DispatchClient is the client API for Dispatch service.
@@ -33,6 +34,8 @@ type DispatchClient interface {
 	Deployments(ctx context.Context, in *GetDeploymentOpts, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DeploymentRequest], error)
 	// Deployd returns back statuses for deploys using this API.
 	ReportStatus(ctx context.Context, in *DeploymentStatus, opts ...grpc.CallOption) (*ReportStatusOpts, error)
+	// Deployd returns the result of plan requests using this API.
+	ReportPlan(ctx context.Context, in *DeploymentPlan, opts ...grpc.CallOption) (*ReportStatusOpts, error)
 }
 
 type dispatchClient struct {
@@ -72,6 +75,16 @@ func (c *dispatchClient) ReportStatus(ctx context.Context, in *DeploymentStatus,
 	return out, nil
 }
 
+func (c *dispatchClient) ReportPlan(ctx context.Context, in *DeploymentPlan, opts ...grpc.CallOption) (*ReportStatusOpts, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReportStatusOpts)
+	err := c.cc.Invoke(ctx, Dispatch_ReportPlan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DispatchServer is the server API for Dispatch service.
 // All implementations must embed UnimplementedDispatchServer
 // for forward compatibility.
@@ -82,6 +95,8 @@ type DispatchServer interface {
 	Deployments(*GetDeploymentOpts, grpc.ServerStreamingServer[DeploymentRequest]) error
 	// Deployd returns back statuses for deploys using this API.
 	ReportStatus(context.Context, *DeploymentStatus) (*ReportStatusOpts, error)
+	// Deployd returns the result of plan requests using this API.
+	ReportPlan(context.Context, *DeploymentPlan) (*ReportStatusOpts, error)
 	mustEmbedUnimplementedDispatchServer()
 }
 
@@ -98,6 +113,9 @@ func (UnimplementedDispatchServer) Deployments(*GetDeploymentOpts, grpc.ServerSt
 func (UnimplementedDispatchServer) ReportStatus(context.Context, *DeploymentStatus) (*ReportStatusOpts, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ReportStatus not implemented")
 }
+func (UnimplementedDispatchServer) ReportPlan(context.Context, *DeploymentPlan) (*ReportStatusOpts, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportPlan not implemented")
+}
 func (UnimplementedDispatchServer) mustEmbedUnimplementedDispatchServer() {}
 func (UnimplementedDispatchServer) testEmbeddedByValue()                  {}
 
@@ -148,6 +166,24 @@ func _Dispatch_ReportStatus_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Dispatch_ReportPlan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeploymentPlan)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DispatchServer).ReportPlan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Dispatch_ReportPlan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DispatchServer).ReportPlan(ctx, req.(*DeploymentPlan))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Dispatch_ServiceDesc is the grpc.ServiceDesc for Dispatch service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -159,6 +195,10 @@ var Dispatch_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ReportStatus",
 			Handler:    _Dispatch_ReportStatus_Handler,
 		},
+		{
+			MethodName: "ReportPlan",
+			Handler:    _Dispatch_ReportPlan_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -171,8 +211,18 @@ var Dispatch_ServiceDesc = grpc.ServiceDesc{
 }
 
 const (
-	Deploy_Deploy_FullMethodName = "/pb.Deploy/Deploy"
-	Deploy_Status_FullMethodName = "/pb.Deploy/Status"
+	Deploy_Deploy_FullMethodName          = "/pb.Deploy/Deploy"
+	Deploy_Status_FullMethodName          = "/pb.Deploy/Status"
+	Deploy_Cancel_FullMethodName          = "/pb.Deploy/Cancel"
+	Deploy_ListDeployments_FullMethodName = "/pb.Deploy/ListDeployments"
+	Deploy_GetDeployment_FullMethodName   = "/pb.Deploy/GetDeployment"
+	Deploy_DiffDeployments_FullMethodName = "/pb.Deploy/DiffDeployments"
+	Deploy_Redeploy_FullMethodName        = "/pb.Deploy/Redeploy"
+	Deploy_Plan_FullMethodName            = "/pb.Deploy/Plan"
+	Deploy_Undeploy_FullMethodName        = "/pb.Deploy/Undeploy"
+	Deploy_TeardownPreview_FullMethodName = "/pb.Deploy/TeardownPreview"
+	Deploy_Approve_FullMethodName         = "/pb.Deploy/Approve"
+	Deploy_Reject_FullMethodName          = "/pb.Deploy/Reject"
 )
 
 // DeployClient is the client API for Deploy service.
@@ -183,6 +233,21 @@ const (
 type DeployClient interface {
 	Deploy(ctx context.Context, in *DeploymentRequest, opts ...grpc.CallOption) (*DeploymentStatus, error)
 	Status(ctx context.Context, in *DeploymentRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DeploymentStatus], error)
+	Cancel(ctx context.Context, in *DeploymentRequest, opts ...grpc.CallOption) (*DeploymentStatus, error)
+	ListDeployments(ctx context.Context, in *ListDeploymentsRequest, opts ...grpc.CallOption) (*ListDeploymentsResponse, error)
+	GetDeployment(ctx context.Context, in *GetDeploymentRequest, opts ...grpc.CallOption) (*Deployment, error)
+	DiffDeployments(ctx context.Context, in *DiffDeploymentsRequest, opts ...grpc.CallOption) (*DeploymentDiff, error)
+	Redeploy(ctx context.Context, in *RedeployRequest, opts ...grpc.CallOption) (*DeploymentStatus, error)
+	// Show what a deployment request would change in the cluster, without deploying it.
+	Plan(ctx context.Context, in *DeploymentRequest, opts ...grpc.CallOption) (*DeploymentPlan, error)
+	// Delete the resources in a deployment request, and wait until they are gone.
+	Undeploy(ctx context.Context, in *DeploymentRequest, opts ...grpc.CallOption) (*DeploymentStatus, error)
+	// Delete all resources of a pull request preview environment, before it expires.
+	TeardownPreview(ctx context.Context, in *TeardownPreviewRequest, opts ...grpc.CallOption) (*DeploymentStatus, error)
+	// Dispatch a deployment that is waiting for approval.
+	Approve(ctx context.Context, in *ApprovalRequest, opts ...grpc.CallOption) (*DeploymentStatus, error)
+	// Stop a deployment that is waiting for approval from being dispatched.
+	Reject(ctx context.Context, in *ApprovalRequest, opts ...grpc.CallOption) (*DeploymentStatus, error)
 }
 
 type deployClient struct {
@@ -222,6 +287,106 @@ func (c *deployClient) Status(ctx context.Context, in *DeploymentRequest, opts .
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type Deploy_StatusClient = grpc.ServerStreamingClient[DeploymentStatus]
 
+func (c *deployClient) Cancel(ctx context.Context, in *DeploymentRequest, opts ...grpc.CallOption) (*DeploymentStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeploymentStatus)
+	err := c.cc.Invoke(ctx, Deploy_Cancel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deployClient) ListDeployments(ctx context.Context, in *ListDeploymentsRequest, opts ...grpc.CallOption) (*ListDeploymentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDeploymentsResponse)
+	err := c.cc.Invoke(ctx, Deploy_ListDeployments_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deployClient) GetDeployment(ctx context.Context, in *GetDeploymentRequest, opts ...grpc.CallOption) (*Deployment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Deployment)
+	err := c.cc.Invoke(ctx, Deploy_GetDeployment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deployClient) DiffDeployments(ctx context.Context, in *DiffDeploymentsRequest, opts ...grpc.CallOption) (*DeploymentDiff, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeploymentDiff)
+	err := c.cc.Invoke(ctx, Deploy_DiffDeployments_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deployClient) Redeploy(ctx context.Context, in *RedeployRequest, opts ...grpc.CallOption) (*DeploymentStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeploymentStatus)
+	err := c.cc.Invoke(ctx, Deploy_Redeploy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deployClient) Plan(ctx context.Context, in *DeploymentRequest, opts ...grpc.CallOption) (*DeploymentPlan, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeploymentPlan)
+	err := c.cc.Invoke(ctx, Deploy_Plan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deployClient) Undeploy(ctx context.Context, in *DeploymentRequest, opts ...grpc.CallOption) (*DeploymentStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeploymentStatus)
+	err := c.cc.Invoke(ctx, Deploy_Undeploy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deployClient) TeardownPreview(ctx context.Context, in *TeardownPreviewRequest, opts ...grpc.CallOption) (*DeploymentStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeploymentStatus)
+	err := c.cc.Invoke(ctx, Deploy_TeardownPreview_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deployClient) Approve(ctx context.Context, in *ApprovalRequest, opts ...grpc.CallOption) (*DeploymentStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeploymentStatus)
+	err := c.cc.Invoke(ctx, Deploy_Approve_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deployClient) Reject(ctx context.Context, in *ApprovalRequest, opts ...grpc.CallOption) (*DeploymentStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeploymentStatus)
+	err := c.cc.Invoke(ctx, Deploy_Reject_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DeployServer is the server API for Deploy service.
 // All implementations must embed UnimplementedDeployServer
 // for forward compatibility.
@@ -230,6 +395,21 @@ type Deploy_StatusClient = grpc.ServerStreamingClient[DeploymentStatus]
 type DeployServer interface {
 	Deploy(context.Context, *DeploymentRequest) (*DeploymentStatus, error)
 	Status(*DeploymentRequest, grpc.ServerStreamingServer[DeploymentStatus]) error
+	Cancel(context.Context, *DeploymentRequest) (*DeploymentStatus, error)
+	ListDeployments(context.Context, *ListDeploymentsRequest) (*ListDeploymentsResponse, error)
+	GetDeployment(context.Context, *GetDeploymentRequest) (*Deployment, error)
+	DiffDeployments(context.Context, *DiffDeploymentsRequest) (*DeploymentDiff, error)
+	Redeploy(context.Context, *RedeployRequest) (*DeploymentStatus, error)
+	// Show what a deployment request would change in the cluster, without deploying it.
+	Plan(context.Context, *DeploymentRequest) (*DeploymentPlan, error)
+	// Delete the resources in a deployment request, and wait until they are gone.
+	Undeploy(context.Context, *DeploymentRequest) (*DeploymentStatus, error)
+	// Delete all resources of a pull request preview environment, before it expires.
+	TeardownPreview(context.Context, *TeardownPreviewRequest) (*DeploymentStatus, error)
+	// Dispatch a deployment that is waiting for approval.
+	Approve(context.Context, *ApprovalRequest) (*DeploymentStatus, error)
+	// Stop a deployment that is waiting for approval from being dispatched.
+	Reject(context.Context, *ApprovalRequest) (*DeploymentStatus, error)
 	mustEmbedUnimplementedDeployServer()
 }
 
@@ -246,6 +426,36 @@ func (UnimplementedDeployServer) Deploy(context.Context, *DeploymentRequest) (*D
 func (UnimplementedDeployServer) Status(*DeploymentRequest, grpc.ServerStreamingServer[DeploymentStatus]) error {
 	return status.Errorf(codes.Unimplemented, "method Status not implemented")
 }
+func (UnimplementedDeployServer) Cancel(context.Context, *DeploymentRequest) (*DeploymentStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Cancel not implemented")
+}
+func (UnimplementedDeployServer) ListDeployments(context.Context, *ListDeploymentsRequest) (*ListDeploymentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeployments not implemented")
+}
+func (UnimplementedDeployServer) GetDeployment(context.Context, *GetDeploymentRequest) (*Deployment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeployment not implemented")
+}
+func (UnimplementedDeployServer) DiffDeployments(context.Context, *DiffDeploymentsRequest) (*DeploymentDiff, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DiffDeployments not implemented")
+}
+func (UnimplementedDeployServer) Redeploy(context.Context, *RedeployRequest) (*DeploymentStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Redeploy not implemented")
+}
+func (UnimplementedDeployServer) Plan(context.Context, *DeploymentRequest) (*DeploymentPlan, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Plan not implemented")
+}
+func (UnimplementedDeployServer) Undeploy(context.Context, *DeploymentRequest) (*DeploymentStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Undeploy not implemented")
+}
+func (UnimplementedDeployServer) TeardownPreview(context.Context, *TeardownPreviewRequest) (*DeploymentStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TeardownPreview not implemented")
+}
+func (UnimplementedDeployServer) Approve(context.Context, *ApprovalRequest) (*DeploymentStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Approve not implemented")
+}
+func (UnimplementedDeployServer) Reject(context.Context, *ApprovalRequest) (*DeploymentStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reject not implemented")
+}
 func (UnimplementedDeployServer) mustEmbedUnimplementedDeployServer() {}
 func (UnimplementedDeployServer) testEmbeddedByValue()                {}
 
@@ -296,6 +506,186 @@ func _Deploy_Status_Handler(srv interface{}, stream grpc.ServerStream) error {
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type Deploy_StatusServer = grpc.ServerStreamingServer[DeploymentStatus]
 
+func _Deploy_Cancel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeploymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeployServer).Cancel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Deploy_Cancel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeployServer).Cancel(ctx, req.(*DeploymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Deploy_ListDeployments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeploymentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeployServer).ListDeployments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Deploy_ListDeployments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeployServer).ListDeployments(ctx, req.(*ListDeploymentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Deploy_GetDeployment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeploymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeployServer).GetDeployment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Deploy_GetDeployment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeployServer).GetDeployment(ctx, req.(*GetDeploymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Deploy_DiffDeployments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffDeploymentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeployServer).DiffDeployments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Deploy_DiffDeployments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeployServer).DiffDeployments(ctx, req.(*DiffDeploymentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Deploy_Redeploy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RedeployRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeployServer).Redeploy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Deploy_Redeploy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeployServer).Redeploy(ctx, req.(*RedeployRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Deploy_Plan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeploymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeployServer).Plan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Deploy_Plan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeployServer).Plan(ctx, req.(*DeploymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Deploy_Undeploy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeploymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeployServer).Undeploy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Deploy_Undeploy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeployServer).Undeploy(ctx, req.(*DeploymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Deploy_TeardownPreview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TeardownPreviewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeployServer).TeardownPreview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Deploy_TeardownPreview_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeployServer).TeardownPreview(ctx, req.(*TeardownPreviewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Deploy_Approve_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApprovalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeployServer).Approve(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Deploy_Approve_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeployServer).Approve(ctx, req.(*ApprovalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Deploy_Reject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApprovalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeployServer).Reject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Deploy_Reject_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeployServer).Reject(ctx, req.(*ApprovalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Deploy_ServiceDesc is the grpc.ServiceDesc for Deploy service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -307,6 +697,46 @@ var Deploy_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Deploy",
 			Handler:    _Deploy_Deploy_Handler,
 		},
+		{
+			MethodName: "Cancel",
+			Handler:    _Deploy_Cancel_Handler,
+		},
+		{
+			MethodName: "ListDeployments",
+			Handler:    _Deploy_ListDeployments_Handler,
+		},
+		{
+			MethodName: "GetDeployment",
+			Handler:    _Deploy_GetDeployment_Handler,
+		},
+		{
+			MethodName: "DiffDeployments",
+			Handler:    _Deploy_DiffDeployments_Handler,
+		},
+		{
+			MethodName: "Redeploy",
+			Handler:    _Deploy_Redeploy_Handler,
+		},
+		{
+			MethodName: "Plan",
+			Handler:    _Deploy_Plan_Handler,
+		},
+		{
+			MethodName: "Undeploy",
+			Handler:    _Deploy_Undeploy_Handler,
+		},
+		{
+			MethodName: "TeardownPreview",
+			Handler:    _Deploy_TeardownPreview_Handler,
+		},
+		{
+			MethodName: "Approve",
+			Handler:    _Deploy_Approve_Handler,
+		},
+		{
+			MethodName: "Reject",
+			Handler:    _Deploy_Reject_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
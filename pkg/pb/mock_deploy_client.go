@@ -15,6 +15,72 @@ type MockDeployClient struct {
 	mock.Mock
 }
 
+// Approve provides a mock function with given fields: ctx, in, opts
+func (_m *MockDeployClient) Approve(ctx context.Context, in *ApprovalRequest, opts ...grpc.CallOption) (*DeploymentStatus, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, in)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *DeploymentStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *ApprovalRequest, ...grpc.CallOption) (*DeploymentStatus, error)); ok {
+		return rf(ctx, in, opts...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *ApprovalRequest, ...grpc.CallOption) *DeploymentStatus); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DeploymentStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *ApprovalRequest, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Cancel provides a mock function with given fields: ctx, in, opts
+func (_m *MockDeployClient) Cancel(ctx context.Context, in *DeploymentRequest, opts ...grpc.CallOption) (*DeploymentStatus, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, in)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *DeploymentStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *DeploymentRequest, ...grpc.CallOption) (*DeploymentStatus, error)); ok {
+		return rf(ctx, in, opts...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *DeploymentRequest, ...grpc.CallOption) *DeploymentStatus); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DeploymentStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *DeploymentRequest, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Deploy provides a mock function with given fields: ctx, in, opts
 func (_m *MockDeployClient) Deploy(ctx context.Context, in *DeploymentRequest, opts ...grpc.CallOption) (*DeploymentStatus, error) {
 	_va := make([]interface{}, len(opts))
@@ -48,6 +114,204 @@ func (_m *MockDeployClient) Deploy(ctx context.Context, in *DeploymentRequest, o
 	return r0, r1
 }
 
+// DiffDeployments provides a mock function with given fields: ctx, in, opts
+func (_m *MockDeployClient) DiffDeployments(ctx context.Context, in *DiffDeploymentsRequest, opts ...grpc.CallOption) (*DeploymentDiff, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, in)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *DeploymentDiff
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *DiffDeploymentsRequest, ...grpc.CallOption) (*DeploymentDiff, error)); ok {
+		return rf(ctx, in, opts...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *DiffDeploymentsRequest, ...grpc.CallOption) *DeploymentDiff); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DeploymentDiff)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *DiffDeploymentsRequest, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeployment provides a mock function with given fields: ctx, in, opts
+func (_m *MockDeployClient) GetDeployment(ctx context.Context, in *GetDeploymentRequest, opts ...grpc.CallOption) (*Deployment, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, in)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *Deployment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *GetDeploymentRequest, ...grpc.CallOption) (*Deployment, error)); ok {
+		return rf(ctx, in, opts...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *GetDeploymentRequest, ...grpc.CallOption) *Deployment); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Deployment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *GetDeploymentRequest, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListDeployments provides a mock function with given fields: ctx, in, opts
+func (_m *MockDeployClient) ListDeployments(ctx context.Context, in *ListDeploymentsRequest, opts ...grpc.CallOption) (*ListDeploymentsResponse, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, in)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ListDeploymentsResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *ListDeploymentsRequest, ...grpc.CallOption) (*ListDeploymentsResponse, error)); ok {
+		return rf(ctx, in, opts...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *ListDeploymentsRequest, ...grpc.CallOption) *ListDeploymentsResponse); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ListDeploymentsResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *ListDeploymentsRequest, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Plan provides a mock function with given fields: ctx, in, opts
+func (_m *MockDeployClient) Plan(ctx context.Context, in *DeploymentRequest, opts ...grpc.CallOption) (*DeploymentPlan, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, in)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *DeploymentPlan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *DeploymentRequest, ...grpc.CallOption) (*DeploymentPlan, error)); ok {
+		return rf(ctx, in, opts...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *DeploymentRequest, ...grpc.CallOption) *DeploymentPlan); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DeploymentPlan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *DeploymentRequest, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Redeploy provides a mock function with given fields: ctx, in, opts
+func (_m *MockDeployClient) Redeploy(ctx context.Context, in *RedeployRequest, opts ...grpc.CallOption) (*DeploymentStatus, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, in)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *DeploymentStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *RedeployRequest, ...grpc.CallOption) (*DeploymentStatus, error)); ok {
+		return rf(ctx, in, opts...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *RedeployRequest, ...grpc.CallOption) *DeploymentStatus); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DeploymentStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *RedeployRequest, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reject provides a mock function with given fields: ctx, in, opts
+func (_m *MockDeployClient) Reject(ctx context.Context, in *ApprovalRequest, opts ...grpc.CallOption) (*DeploymentStatus, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, in)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *DeploymentStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *ApprovalRequest, ...grpc.CallOption) (*DeploymentStatus, error)); ok {
+		return rf(ctx, in, opts...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *ApprovalRequest, ...grpc.CallOption) *DeploymentStatus); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DeploymentStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *ApprovalRequest, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Status provides a mock function with given fields: ctx, in, opts
 func (_m *MockDeployClient) Status(ctx context.Context, in *DeploymentRequest, opts ...grpc.CallOption) (Deploy_StatusClient, error) {
 	_va := make([]interface{}, len(opts))
@@ -82,6 +346,72 @@ func (_m *MockDeployClient) Status(ctx context.Context, in *DeploymentRequest, o
 }
 
 // NewMockDeployClient creates a new instance of MockDeployClient. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// TeardownPreview provides a mock function with given fields: ctx, in, opts
+func (_m *MockDeployClient) TeardownPreview(ctx context.Context, in *TeardownPreviewRequest, opts ...grpc.CallOption) (*DeploymentStatus, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, in)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *DeploymentStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *TeardownPreviewRequest, ...grpc.CallOption) (*DeploymentStatus, error)); ok {
+		return rf(ctx, in, opts...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *TeardownPreviewRequest, ...grpc.CallOption) *DeploymentStatus); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DeploymentStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *TeardownPreviewRequest, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Undeploy provides a mock function with given fields: ctx, in, opts
+func (_m *MockDeployClient) Undeploy(ctx context.Context, in *DeploymentRequest, opts ...grpc.CallOption) (*DeploymentStatus, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, in)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *DeploymentStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *DeploymentRequest, ...grpc.CallOption) (*DeploymentStatus, error)); ok {
+		return rf(ctx, in, opts...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *DeploymentRequest, ...grpc.CallOption) *DeploymentStatus); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DeploymentStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *DeploymentRequest, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // The first argument is typically a *testing.T value.
 func NewMockDeployClient(t interface {
 	mock.TestingT
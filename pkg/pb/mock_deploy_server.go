@@ -13,6 +13,58 @@ type MockDeployServer struct {
 	mock.Mock
 }
 
+// Approve provides a mock function with given fields: _a0, _a1
+func (_m *MockDeployServer) Approve(_a0 context.Context, _a1 *ApprovalRequest) (*DeploymentStatus, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *DeploymentStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *ApprovalRequest) (*DeploymentStatus, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *ApprovalRequest) *DeploymentStatus); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DeploymentStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *ApprovalRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Cancel provides a mock function with given fields: _a0, _a1
+func (_m *MockDeployServer) Cancel(_a0 context.Context, _a1 *DeploymentRequest) (*DeploymentStatus, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *DeploymentStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *DeploymentRequest) (*DeploymentStatus, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *DeploymentRequest) *DeploymentStatus); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DeploymentStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *DeploymentRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Deploy provides a mock function with given fields: _a0, _a1
 func (_m *MockDeployServer) Deploy(_a0 context.Context, _a1 *DeploymentRequest) (*DeploymentStatus, error) {
 	ret := _m.Called(_a0, _a1)
@@ -39,6 +91,162 @@ func (_m *MockDeployServer) Deploy(_a0 context.Context, _a1 *DeploymentRequest)
 	return r0, r1
 }
 
+// DiffDeployments provides a mock function with given fields: _a0, _a1
+func (_m *MockDeployServer) DiffDeployments(_a0 context.Context, _a1 *DiffDeploymentsRequest) (*DeploymentDiff, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *DeploymentDiff
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *DiffDeploymentsRequest) (*DeploymentDiff, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *DiffDeploymentsRequest) *DeploymentDiff); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DeploymentDiff)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *DiffDeploymentsRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeployment provides a mock function with given fields: _a0, _a1
+func (_m *MockDeployServer) GetDeployment(_a0 context.Context, _a1 *GetDeploymentRequest) (*Deployment, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *Deployment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *GetDeploymentRequest) (*Deployment, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *GetDeploymentRequest) *Deployment); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Deployment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *GetDeploymentRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListDeployments provides a mock function with given fields: _a0, _a1
+func (_m *MockDeployServer) ListDeployments(_a0 context.Context, _a1 *ListDeploymentsRequest) (*ListDeploymentsResponse, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *ListDeploymentsResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *ListDeploymentsRequest) (*ListDeploymentsResponse, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *ListDeploymentsRequest) *ListDeploymentsResponse); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ListDeploymentsResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *ListDeploymentsRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Plan provides a mock function with given fields: _a0, _a1
+func (_m *MockDeployServer) Plan(_a0 context.Context, _a1 *DeploymentRequest) (*DeploymentPlan, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *DeploymentPlan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *DeploymentRequest) (*DeploymentPlan, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *DeploymentRequest) *DeploymentPlan); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DeploymentPlan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *DeploymentRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Redeploy provides a mock function with given fields: _a0, _a1
+func (_m *MockDeployServer) Redeploy(_a0 context.Context, _a1 *RedeployRequest) (*DeploymentStatus, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *DeploymentStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *RedeployRequest) (*DeploymentStatus, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *RedeployRequest) *DeploymentStatus); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DeploymentStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *RedeployRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reject provides a mock function with given fields: _a0, _a1
+func (_m *MockDeployServer) Reject(_a0 context.Context, _a1 *ApprovalRequest) (*DeploymentStatus, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *DeploymentStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *ApprovalRequest) (*DeploymentStatus, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *ApprovalRequest) *DeploymentStatus); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DeploymentStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *ApprovalRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Status provides a mock function with given fields: _a0, _a1
 func (_m *MockDeployServer) Status(_a0 *DeploymentRequest, _a1 Deploy_StatusServer) error {
 	ret := _m.Called(_a0, _a1)
@@ -53,6 +261,58 @@ func (_m *MockDeployServer) Status(_a0 *DeploymentRequest, _a1 Deploy_StatusServ
 	return r0
 }
 
+// TeardownPreview provides a mock function with given fields: _a0, _a1
+func (_m *MockDeployServer) TeardownPreview(_a0 context.Context, _a1 *TeardownPreviewRequest) (*DeploymentStatus, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *DeploymentStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *TeardownPreviewRequest) (*DeploymentStatus, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *TeardownPreviewRequest) *DeploymentStatus); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DeploymentStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *TeardownPreviewRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Undeploy provides a mock function with given fields: _a0, _a1
+func (_m *MockDeployServer) Undeploy(_a0 context.Context, _a1 *DeploymentRequest) (*DeploymentStatus, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *DeploymentStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *DeploymentRequest) (*DeploymentStatus, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *DeploymentRequest) *DeploymentStatus); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DeploymentStatus)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *DeploymentRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // mustEmbedUnimplementedDeployServer provides a mock function with given fields:
 func (_m *MockDeployServer) mustEmbedUnimplementedDeployServer() {
 	_m.Called()
@@ -48,6 +48,39 @@ func (_m *MockDispatchClient) Deployments(ctx context.Context, in *GetDeployment
 	return r0, r1
 }
 
+// ReportPlan provides a mock function with given fields: ctx, in, opts
+func (_m *MockDispatchClient) ReportPlan(ctx context.Context, in *DeploymentPlan, opts ...grpc.CallOption) (*ReportStatusOpts, error) {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, in)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *ReportStatusOpts
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *DeploymentPlan, ...grpc.CallOption) (*ReportStatusOpts, error)); ok {
+		return rf(ctx, in, opts...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *DeploymentPlan, ...grpc.CallOption) *ReportStatusOpts); ok {
+		r0 = rf(ctx, in, opts...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ReportStatusOpts)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *DeploymentPlan, ...grpc.CallOption) error); ok {
+		r1 = rf(ctx, in, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ReportStatus provides a mock function with given fields: ctx, in, opts
 func (_m *MockDispatchClient) ReportStatus(ctx context.Context, in *DeploymentStatus, opts ...grpc.CallOption) (*ReportStatusOpts, error) {
 	_va := make([]interface{}, len(opts))
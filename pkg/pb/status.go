@@ -74,6 +74,16 @@ func NewInactiveStatus(req *DeploymentRequest) *DeploymentStatus {
 	}
 }
 
+func NewCancelledStatus(req *DeploymentRequest) *DeploymentStatus {
+	return &DeploymentStatus{
+		Request:   req,
+		Message:   "Deployment was cancelled.",
+		State:     DeploymentState_inactive,
+		Time:      TimeAsTimestamp(time.Now()),
+		Cancelled: true,
+	}
+}
+
 func NewQueuedStatus(req *DeploymentRequest) *DeploymentStatus {
 	return &DeploymentStatus{
 		Request: req,
@@ -83,6 +93,24 @@ func NewQueuedStatus(req *DeploymentRequest) *DeploymentStatus {
 	}
 }
 
+func NewPendingStatus(req *DeploymentRequest, format string, args ...interface{}) *DeploymentStatus {
+	return &DeploymentStatus{
+		Request: req,
+		Message: fmt.Sprintf(format, args...),
+		State:   DeploymentState_pending,
+		Time:    TimeAsTimestamp(time.Now()),
+	}
+}
+
+func NewQueuePositionStatus(req *DeploymentRequest, position int) *DeploymentStatus {
+	return &DeploymentStatus{
+		Request: req,
+		Message: fmt.Sprintf("Deployment is waiting for other deployments of the same resources to finish (position %d in queue).", position),
+		State:   DeploymentState_queued,
+		Time:    TimeAsTimestamp(time.Now()),
+	}
+}
+
 func NewSuccessStatus(req *DeploymentRequest) *DeploymentStatus {
 	return &DeploymentStatus{
 		Request: req,
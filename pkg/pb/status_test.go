@@ -0,0 +1,29 @@
+package pb_test
+
+import (
+	"testing"
+
+	"github.com/nais/deploy/pkg/pb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestCancelledStatus(t *testing.T) {
+	request := &pb.DeploymentRequest{ID: "1"}
+
+	cancelled := pb.NewCancelledStatus(request)
+	assert.Equal(t, pb.DeploymentState_inactive, cancelled.GetState())
+	assert.True(t, cancelled.GetCancelled())
+
+	// A lost deployment must not be mistaken for a cancelled one, even if the message is the same.
+	lost := pb.NewInactiveStatus(request)
+	lost.Message = cancelled.GetMessage()
+	assert.False(t, lost.GetCancelled())
+
+	// The flag survives the round trip through other hookd instances.
+	payload, err := protojson.Marshal(cancelled)
+	assert.NoError(t, err)
+	decoded := &pb.DeploymentStatus{}
+	assert.NoError(t, protojson.Unmarshal(payload, decoded))
+	assert.True(t, decoded.GetCancelled())
+}